@@ -0,0 +1,51 @@
+package mysqldump
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// WithBufferSize 输出缓冲区的大小, 默认 4096 字节, 行较大时调大可以减少写入次数
+func WithBufferSize(size int) DumpOption {
+	return func(option *dumpOption) {
+		option.bufferSize = size
+	}
+}
+
+// WithFlushInterval 缓冲的内容最多保留 d 后写到 writer, 读取方不必等缓冲区写满或导出结束才收到数据
+// 在写出每行和每个表之后检查, 一条查询返回第一行之前不会写出
+func WithFlushInterval(d time.Duration) DumpOption {
+	return func(option *dumpOption) {
+		option.flushInterval = d
+	}
+}
+
+// newBuffer 返回 WithBufferSize 大小的缓冲
+func (o *dumpOption) newBuffer(w io.Writer) *bufio.Writer {
+	if o.bufferSize > 0 {
+		return bufio.NewWriterSize(w, o.bufferSize)
+	}
+	return bufio.NewWriter(w)
+}
+
+// flushTimer 距离上次写出超过 interval 时写出缓冲, interval 为 0 时不检查
+type flushTimer struct {
+	interval time.Duration
+	last     time.Time
+}
+
+func (t *flushTimer) check(buf *bufio.Writer) error {
+	if t.interval <= 0 || buf.Buffered() == 0 {
+		return nil
+	}
+	now := time.Now()
+	if t.last.IsZero() {
+		t.last = now
+	}
+	if now.Sub(t.last) < t.interval {
+		return nil
+	}
+	t.last = now
+	return buf.Flush()
+}
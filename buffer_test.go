@@ -0,0 +1,57 @@
+package mysqldump
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_newBuffer(t *testing.T) {
+	tests := []struct {
+		size int
+		want int
+	}{
+		{size: 0, want: 4096},
+		{size: 1 << 20, want: 1 << 20},
+	}
+	for _, tt := range tests {
+		o := newDumpOption([]DumpOption{WithBufferSize(tt.size)})
+		if got := o.newBuffer(&bytes.Buffer{}).Size(); got != tt.want {
+			t.Errorf("WithBufferSize(%d) size = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func Test_flushTimer(t *testing.T) {
+	var out bytes.Buffer
+	o := newDumpOption([]DumpOption{WithFlushInterval(10 * time.Millisecond)})
+	buf := o.newBuffer(&out)
+	timer := flushTimer{interval: o.flushInterval}
+
+	buf.WriteString("a")
+	if err := timer.check(buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("flushed before interval: %q", out.String())
+	}
+	time.Sleep(20 * time.Millisecond)
+	buf.WriteString("b")
+	if err := timer.check(buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "ab" {
+		t.Errorf("got %q after interval, want %q", out.String(), "ab")
+	}
+
+	// interval 为 0 时不写出
+	out.Reset()
+	buf.WriteString("c")
+	var none flushTimer
+	if err := none.check(buf); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("flushed without interval: %q", out.String())
+	}
+}
@@ -0,0 +1,229 @@
+package mysqldump
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CatalogEntry 一次导出的记录
+type CatalogEntry struct {
+	// 导出文件位置, 如本地路径或对象存储的 key
+	Location string        `json:"location"`
+	Database string        `json:"database"`
+	Size     int64         `json:"size"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	// 导出内容的 sha256, 断点续传的导出为空
+	Checksum       string `json:"checksum,omitempty"`
+	BinlogFile     string `json:"binlog_file,omitempty"`
+	BinlogPosition uint64 `json:"binlog_position,omitempty"`
+}
+
+// Catalog 导出记录目录, 以 JSON 文件保存
+type Catalog struct {
+	path    string
+	mu      sync.Mutex
+	entries []CatalogEntry
+}
+
+// RetentionPolicy 保留策略, 满足任意一条规则的导出都会保留
+type RetentionPolicy struct {
+	// 保留最近 N 个
+	KeepLast int
+	// 保留最近 N 天每天最新的一个
+	KeepDaily int
+	// 保留最近 N 周每周最新的一个
+	KeepWeekly int
+	// 保留最近 N 月每月最新的一个
+	KeepMonthly int
+}
+
+// OpenCatalog 打开目录文件, 文件不存在时创建空目录
+func OpenCatalog(path string) (*Catalog, error) {
+	c := &Catalog{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(data, &c.entries)
+	if err != nil {
+		return nil, fmt.Errorf("catalog %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Add 添加记录并保存
+func (c *Catalog) Add(entry CatalogEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, entry)
+	return c.save()
+}
+
+// List 按开始时间从新到旧返回所有记录
+func (c *Catalog) List() []CatalogEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := append([]CatalogEntry(nil), c.entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.After(entries[j].Start)
+	})
+	return entries
+}
+
+// Prune 按保留策略删除旧的导出, 每个数据库分别应用策略, remove 删除导出文件, 为 nil 时使用 os.Remove
+// 返回被删除的记录; 执行期间持有锁, 同时 Add 的记录不会丢失
+func (c *Catalog) Prune(policy RetentionPolicy, remove func(CatalogEntry) error) ([]CatalogEntry, error) {
+	if remove == nil {
+		remove = func(entry CatalogEntry) error {
+			err := os.Remove(entry.Location)
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	byDatabase := make(map[string][]int)
+	for i, entry := range c.entries {
+		byDatabase[entry.Database] = append(byDatabase[entry.Database], i)
+	}
+	keep := make([]bool, len(c.entries))
+	for _, indexes := range byDatabase {
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return c.entries[indexes[a]].Start.After(c.entries[indexes[b]].Start)
+		})
+		group := make([]CatalogEntry, len(indexes))
+		for j, i := range indexes {
+			group[j] = c.entries[i]
+		}
+		for j, k := range policy.keep(group) {
+			keep[indexes[j]] = k
+		}
+	}
+
+	var kept, pruned []CatalogEntry
+	var err error
+	for i, entry := range c.entries {
+		// 删除失败的记录和之后的记录保留, 下次再删除
+		if keep[i] || err != nil {
+			kept = append(kept, entry)
+			continue
+		}
+		err = remove(entry)
+		if err != nil {
+			kept = append(kept, entry)
+			continue
+		}
+		pruned = append(pruned, entry)
+	}
+	c.entries = kept
+	if serr := c.save(); err == nil {
+		err = serr
+	}
+	return pruned, err
+}
+
+// keep 返回每条记录是否保留, entries 按从新到旧排序
+func (p RetentionPolicy) keep(entries []CatalogEntry) []bool {
+	keep := make([]bool, len(entries))
+	for i := range entries {
+		if i < p.KeepLast {
+			keep[i] = true
+		}
+	}
+	buckets := []struct {
+		n   int
+		key func(t time.Time) string
+	}{
+		{p.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{p.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+		{p.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	for _, bucket := range buckets {
+		seen := make(map[string]bool)
+		for i, entry := range entries {
+			if len(seen) >= bucket.n {
+				break
+			}
+			key := bucket.key(entry.Start)
+			if !seen[key] {
+				seen[key] = true
+				keep[i] = true
+			}
+		}
+	}
+	return keep
+}
+
+func (c *Catalog) save() error {
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// WithCatalog 导出成功后将记录添加到 catalog, location 为导出文件的位置
+func WithCatalog(catalog *Catalog, location string) DumpOption {
+	return func(option *dumpOption) {
+		option.catalog = catalog
+		option.catalogLocation = location
+	}
+}
+
+// getBinlogPosition 获取当前 binlog 位置, 未开启 binlog 或没有权限时返回空
+// 先使用版本对应的语句, 版本未知时再尝试另一个
+func getBinlogPosition(db queryer, v ServerVersion) (string, uint64) {
+	queries := []string{"SHOW MASTER STATUS", "SHOW BINARY LOG STATUS"}
+	if v.binlogStatusQuery() != queries[0] {
+		queries[0], queries[1] = queries[1], queries[0]
+	}
+	for _, query := range queries {
+		rows, err := db.Query(query)
+		if err != nil {
+			continue
+		}
+		file, pos := scanBinlogPosition(rows)
+		rows.Close()
+		return file, pos
+	}
+	return "", 0
+}
+
+func scanBinlogPosition(rows *sql.Rows) (string, uint64) {
+	columns, err := rows.Columns()
+	if err != nil || len(columns) < 2 || !rows.Next() {
+		return "", 0
+	}
+	values := make([]interface{}, len(columns))
+	var file string
+	var pos uint64
+	values[0], values[1] = &file, &pos
+	for i := 2; i < len(columns); i++ {
+		values[i] = new(sql.RawBytes)
+	}
+	if rows.Scan(values...) != nil {
+		return "", 0
+	}
+	return file, pos
+}
@@ -0,0 +1,108 @@
+package mysqldump
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCatalogPrune(t *testing.T) {
+	c, err := OpenCatalog(filepath.Join(t.TempDir(), "catalog.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 3, 17, 2, 0, 0, 0, time.UTC)
+	// 最近 21 天每天两次导出
+	for i := 0; i < 21; i++ {
+		for _, h := range []int{0, 12} {
+			start := base.AddDate(0, 0, -i).Add(time.Duration(h) * time.Hour)
+			err = c.Add(CatalogEntry{Location: start.Format(time.RFC3339), Start: start})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var removed int
+	pruned, err := c.Prune(RetentionPolicy{KeepLast: 1, KeepDaily: 3, KeepWeekly: 2}, func(CatalogEntry) error {
+		removed++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+
+	kept := c.List()
+	// 3 个每日 (包含最近一个), 第 2 周最新的一个
+	if len(kept) != 4 {
+		for _, e := range kept {
+			t.Logf("kept %s", e.Location)
+		}
+		t.Fatalf("Prune() kept %d, want 4", len(kept))
+	}
+	if len(pruned) != 42-4 || removed != len(pruned) {
+		t.Errorf("Prune() pruned %d, removed %d", len(pruned), removed)
+	}
+
+	reopened, err := OpenCatalog(c.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reopened.List()) != 4 {
+		t.Errorf("OpenCatalog() entries = %d, want 4", len(reopened.List()))
+	}
+}
+
+func TestCatalogPrune_perDatabase(t *testing.T) {
+	c, err := OpenCatalog(filepath.Join(t.TempDir(), "catalog.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 3, 17, 2, 0, 0, 0, time.UTC)
+	// a 每小时导出, b 只有更早的一次导出
+	for i := 0; i < 3; i++ {
+		if err = c.Add(CatalogEntry{Location: fmt.Sprintf("a%d", i), Database: "a", Start: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = c.Add(CatalogEntry{Location: "b0", Database: "b", Start: base.Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 删除时同时添加的记录不丢失
+	var wg sync.WaitGroup
+	pruned, err := c.Prune(RetentionPolicy{KeepLast: 1}, func(entry CatalogEntry) error {
+		if entry.Location == "a1" {
+			return errors.New("remove failed")
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = c.Add(CatalogEntry{Location: "added-" + entry.Location, Database: "c", Start: base})
+		}()
+		return nil
+	})
+	wg.Wait()
+	if err == nil {
+		t.Error("Prune() want remove error")
+	}
+
+	var locations []string
+	for _, entry := range c.List() {
+		locations = append(locations, entry.Location)
+	}
+	sort.Strings(locations)
+	// 每个数据库保留最近一个, 删除失败的 a1 保留
+	want := []string{"a1", "a2", "added-a0", "b0"}
+	if !reflect.DeepEqual(locations, want) {
+		t.Errorf("kept = %v, want %v", locations, want)
+	}
+	if len(pruned) != 1 || pruned[0].Location != "a0" {
+		t.Errorf("pruned = %v, want a0", pruned)
+	}
+}
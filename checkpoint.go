@@ -0,0 +1,152 @@
+package mysqldump
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+)
+
+// checkpointState 断点文件内容
+type checkpointState struct {
+	// 已写出的字节数
+	Offset int64 `json:"offset"`
+	// 已完成的表
+	Done map[string]bool `json:"done"`
+	// 未完成的表已导出的最后一个主键值, 为 SQL 字面量, 整数不加引号, 如 9007199254740993 或 '2024-01-02 03:04:05.000001'
+	Chunks map[string]string `json:"chunks"`
+	// WithSchemaFirst 时所有表结构已输出
+	Schema bool `json:"schema,omitempty"`
+}
+
+// checkpoint 断点续传, nil 时所有方法为空操作
+type checkpoint struct {
+	path    string
+	state   checkpointState
+	counter *countWriter
+}
+
+// WithCheckpoint 断点续传, 记录已完成的表和大表的分块位置到 path
+// 进程中断后使用相同的选项重新执行, 会从上次的位置继续导出, 导出完成后删除 path
+// writer 为 *os.File 时会截断到上次记录的位置, 否则需要调用方以追加方式打开输出
+// 配合 WithChunkSize 可以记录大表的分块位置
+func WithCheckpoint(path string) DumpOption {
+	return func(option *dumpOption) {
+		option.checkpoint = &checkpoint{path: path}
+	}
+}
+
+// load 读取断点文件, 并将输出文件截断到上次记录的位置
+func (c *checkpoint) load(writer io.Writer) error {
+	c.state = checkpointState{Done: make(map[string]bool), Chunks: make(map[string]string)}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	err = json.Unmarshal(data, &c.state)
+	if err != nil {
+		return err
+	}
+	if c.state.Done == nil {
+		c.state.Done = make(map[string]bool)
+	}
+	if c.state.Chunks == nil {
+		c.state.Chunks = make(map[string]string)
+	}
+
+	if f, ok := writer.(*os.File); ok && c.state.Offset > 0 {
+		err = f.Truncate(c.state.Offset)
+		if err != nil {
+			return err
+		}
+		_, err = f.Seek(c.state.Offset, io.SeekStart)
+		if err != nil {
+			return err
+		}
+	}
+	log.Printf("[info] [dump] resume from checkpoint %s, offset %d\n", c.path, c.state.Offset)
+	return nil
+}
+
+func (c *checkpoint) resumed() bool {
+	return c != nil && c.state.Offset > 0
+}
+
+func (c *checkpoint) tableDone(table string) bool {
+	return c != nil && c.state.Done[table]
+}
+
+// resumeFrom 返回未完成的表上次导出的最后一个主键值的 SQL 字面量
+func (c *checkpoint) resumeFrom(table string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	last, ok := c.state.Chunks[table]
+	return last, ok
+}
+
+// chunkDone 写出一个分块后记录位置
+func (c *checkpoint) chunkDone(table, last string, buf *bufio.Writer) error {
+	if c == nil {
+		return nil
+	}
+	c.state.Chunks[table] = last
+	return c.save(buf)
+}
+
+func (c *checkpoint) schemaDone() bool {
+	return c != nil && c.state.Schema
+}
+
+// schemaFinished WithSchemaFirst 的表结构输出完成后记录
+func (c *checkpoint) schemaFinished(buf *bufio.Writer) error {
+	if c == nil {
+		return nil
+	}
+	c.state.Schema = true
+	return c.save(buf)
+}
+
+// tableFinished 表导出完成后记录
+func (c *checkpoint) tableFinished(table string, buf *bufio.Writer) error {
+	if c == nil {
+		return nil
+	}
+	c.state.Done[table] = true
+	delete(c.state.Chunks, table)
+	return c.save(buf)
+}
+
+// save 先将缓冲写出, 保证断点文件记录的位置之前的内容都已写入
+func (c *checkpoint) save(buf *bufio.Writer) error {
+	err := buf.Flush()
+	if err != nil {
+		return err
+	}
+	c.state.Offset = c.counter.n
+	data, err := json.Marshal(c.state)
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+func (c *checkpoint) remove() error {
+	if c == nil {
+		return nil
+	}
+	err := os.Remove(c.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package mysqldump
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	out, err := os.Create(filepath.Join(dir, "dump.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	c := &checkpoint{path: filepath.Join(dir, "dump.ckpt")}
+	if err = c.load(out); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	c.counter = &countWriter{w: out}
+	buf := bufio.NewWriter(c.counter)
+	_, _ = buf.WriteString("table a;\n")
+	if err = c.tableFinished("a", buf); err != nil {
+		t.Fatalf("tableFinished() error = %v", err)
+	}
+	_, _ = buf.WriteString("table b chunk 1;\n")
+	if err = c.chunkDone("b", "100", buf); err != nil {
+		t.Fatalf("chunkDone() error = %v", err)
+	}
+	// 中断前写出的不完整内容
+	_, _ = buf.WriteString("partial")
+	_ = buf.Flush()
+
+	resumed := &checkpoint{path: c.path}
+	if err = resumed.load(out); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if !resumed.resumed() || !resumed.tableDone("a") || resumed.tableDone("b") {
+		t.Errorf("load() state = %+v", resumed.state)
+	}
+	if last, ok := resumed.resumeFrom("b"); !ok || last != "100" {
+		t.Errorf("resumeFrom(b) = %s, %v", last, ok)
+	}
+	data, _ := os.ReadFile(out.Name())
+	if string(data) != "table a;\ntable b chunk 1;\n" {
+		t.Errorf("output not truncated: %q", data)
+	}
+
+	if err = resumed.remove(); err != nil {
+		t.Fatalf("remove() error = %v", err)
+	}
+	if _, err = os.Stat(c.path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint file not removed")
+	}
+}
@@ -0,0 +1,236 @@
+package mysqldump
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// TableChecksum 一个表的数据校验和
+type TableChecksum struct {
+	Name string
+	Rows int64
+	// 所有行哈希的异或, 16 位十六进制, 与分块大小和行的顺序无关
+	Checksum string
+	// 按主键顺序的分块, 没有主键的表只有一块
+	Chunks []ChunkChecksum
+}
+
+// ChunkChecksum 一个分块的校验和, Lower 和 Upper 为主键范围 (Lower, Upper] 的 SQL 行构造器, 如 (1,'a'), 为空表示不限制
+type ChunkChecksum struct {
+	Lower    string
+	Upper    string
+	Rows     int64
+	Checksum string
+}
+
+// ChecksumTables 按主键顺序分块计算表的数据校验和, 与 pt-table-checksum 的算法类似, 但不通过复制在从库上计算
+// tables 为空时计算所有表, 视图和序列不计算, 块的大小使用 WithChunkSize, 默认 1000 行
+// 比较两个数据库的 Checksum 可以发现数据不一致, 再比较 Chunks 可以缩小范围
+func ChecksumTables(dsn string, tables []string, opts ...DumpOption) ([]TableChecksum, error) {
+	if len(tables) > 0 {
+		opts = append(opts, WithTables(tables...))
+	}
+	o := newDumpOption(opts)
+	o.result = &DumpResult{}
+	if o.chunkSize <= 0 {
+		o.chunkSize = 1000
+	}
+
+	db, err := openDB(dsn, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tables, _, err = selectTables(db, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	var checksums []TableChecksum
+	for _, table := range tables {
+		if o.views[table] || o.sequences[table] {
+			continue
+		}
+		checksum, err := checksumTable(db, table, o)
+		if err != nil {
+			log.Printf("[error] [checksum] %s: %v \n", table, err)
+			return nil, err
+		}
+		checksums = append(checksums, checksum)
+	}
+	return checksums, nil
+}
+
+// checksumTable 计算一个表的校验和
+func checksumTable(db queryer, table string, o *dumpOption) (TableChecksum, error) {
+	result := TableChecksum{Name: table}
+	plan, err := newChecksumPlan(db, table, o)
+	if err != nil {
+		return result, err
+	}
+	var sum uint64
+	err = plan.walk(db, func(chunk ChunkChecksum, _ string) error {
+		result.Rows += chunk.Rows
+		value, err := strconv.ParseUint(chunk.Checksum, 16, 64)
+		if err != nil {
+			return err
+		}
+		sum ^= value
+		result.Chunks = append(result.Chunks, chunk)
+		return nil
+	})
+	result.Checksum = fmt.Sprintf("%016x", sum)
+	return result, err
+}
+
+// checksumPlan 计算一个表的分块校验和需要的查询
+type checksumPlan struct {
+	from string
+	// 主键列, 为空时不分块
+	pkList    string
+	hash      string
+	chunkSize int
+}
+
+// newChecksumPlan 查询表的主键和列, 支持 WithOmitColumns
+func newChecksumPlan(db queryer, table string, o *dumpOption) (*checksumPlan, error) {
+	pk, err := getPrimaryKey(db, table)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := getColumns(db, table)
+	if err != nil {
+		return nil, err
+	}
+	columns = excludeColumns(columns, o.omitColumns[table])
+	plan := &checksumPlan{
+		from:      QuoteIdentifier(table),
+		hash:      rowHashExpr(columns),
+		chunkSize: o.chunkSize,
+	}
+	if len(pk) > 0 {
+		plan.pkList = quoteColumns(pk)
+	}
+	return plan, nil
+}
+
+// walk 按主键顺序计算每块的校验和, fn 的第二个参数为块的 WHERE 条件
+func (p *checksumPlan) walk(db queryer, fn func(chunk ChunkChecksum, where string) error) error {
+	var lower string
+	for {
+		var upper string
+		if p.pkList != "" {
+			// 本块的最后一个主键, 没有时为最后一块
+			query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT 1 OFFSET %d", p.pkList, p.from, chunkWhere(p.pkList, lower, ""), p.pkList, p.chunkSize-1)
+			boundary, dataTypes, err := queryValues(db, query)
+			if err != nil {
+				return err
+			}
+			if len(boundary) > 0 {
+				upper, err = tupleLiteral(boundary[0], dataTypes)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		where := chunkWhere(p.pkList, lower, upper)
+		chunk := ChunkChecksum{Lower: lower, Upper: upper}
+		var err error
+		chunk.Rows, chunk.Checksum, err = p.checksum(db, where)
+		if err != nil {
+			return err
+		}
+		err = fn(chunk, where)
+		if err != nil || upper == "" {
+			return err
+		}
+		lower = upper
+	}
+}
+
+// checksum 计算 where 范围内的行数和行哈希的异或
+func (p *checksumPlan) checksum(db queryer, where string) (int64, string, error) {
+	var rows int64
+	var sum uint64
+	query := fmt.Sprintf("SELECT COUNT(*), COALESCE(BIT_XOR(CAST(CONV(LEFT(%s, 16), 16, 10) AS UNSIGNED)), 0) FROM %s%s", p.hash, p.from, where)
+	err := db.QueryRow(query).Scan(&rows, &sum)
+	if err != nil {
+		return 0, "", err
+	}
+	return rows, fmt.Sprintf("%016x", sum), nil
+}
+
+// rowHashExpr 计算一行的 MD5, NULL 和空字符串通过 ISNULL 区分
+func rowHashExpr(columns []string) string {
+	quoted := make([]string, len(columns))
+	nulls := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = QuoteIdentifier(column)
+		nulls[i] = "ISNULL(" + quoted[i] + ")"
+	}
+	return fmt.Sprintf("MD5(CONCAT_WS('#', %s, CONCAT(%s)))", strings.Join(quoted, ", "), strings.Join(nulls, ", "))
+}
+
+// chunkWhere 返回主键在 (lower, upper] 范围内的条件, 为空表示不限制
+func chunkWhere(pkList, lower, upper string) string {
+	var conds []string
+	if lower != "" {
+		conds = append(conds, fmt.Sprintf("(%s) > %s", pkList, lower))
+	}
+	if upper != "" {
+		conds = append(conds, fmt.Sprintf("(%s) <= %s", pkList, upper))
+	}
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// tupleLiteral 将一组值格式化为 SQL 的行构造器, 如 (1,'a')
+func tupleLiteral(values []interface{}, dataTypes []string) (string, error) {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literal, err := FormatValue(value, dataTypes[i])
+		if err != nil {
+			return "", err
+		}
+		literals[i] = literal
+	}
+	return "(" + strings.Join(literals, ",") + ")", nil
+}
+
+// queryValues 执行查询并返回所有行和列的类型
+func queryValues(db queryer, query string) ([][]interface{}, []string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	dataTypes := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		dataTypes[i] = columnType.DatabaseTypeName()
+	}
+
+	var result [][]interface{}
+	for rows.Next() {
+		row := make([]interface{}, len(columnTypes))
+		pointers := make([]interface{}, len(row))
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, row)
+	}
+	return result, dataTypes, rows.Err()
+}
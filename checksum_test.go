@@ -0,0 +1,27 @@
+package mysqldump
+
+import "testing"
+
+func Test_chunkWhere(t *testing.T) {
+	tests := []struct {
+		lower, upper string
+		want         string
+	}{
+		{"", "", ""},
+		{"", "(10)", " WHERE (`id`) <= (10)"},
+		{"(10)", "(20)", " WHERE (`id`) > (10) AND (`id`) <= (20)"},
+		{"(20)", "", " WHERE (`id`) > (20)"},
+	}
+	for _, tt := range tests {
+		if got := chunkWhere("`id`", tt.lower, tt.upper); got != tt.want {
+			t.Errorf("chunkWhere(%q, %q) = %q, want %q", tt.lower, tt.upper, got, tt.want)
+		}
+	}
+}
+
+func Test_rowHashExpr(t *testing.T) {
+	want := "MD5(CONCAT_WS('#', `id`, `na``me`, CONCAT(ISNULL(`id`), ISNULL(`na``me`))))"
+	if got := rowHashExpr([]string{"id", "na`me"}); got != want {
+		t.Errorf("rowHashExpr() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,339 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCloneBatchSize Clone 时每条 INSERT 插入的行数, 默认 1000
+func WithCloneBatchSize(rows int) DumpOption {
+	return func(option *dumpOption) {
+		option.cloneBatchSize = rows
+	}
+}
+
+// Clone 不生成 SQL 文件, 直接将 srcDSN 的表结构和数据复制到 dstDSN
+// 支持导出的表选择, WithDropTable, WithData, WithConcurrency, WithOmitColumns, 脱敏和抽样等选项
+// 两个 DSN 与 Dump 一样补上默认参数, WithSingleTransaction 时所有表从源库同一时间点的快照读取
+func Clone(srcDSN, dstDSN string, opts ...DumpOption) error {
+	start := time.Now()
+	log.Printf("[info] [clone] start at %s\n", start.Format("2006-01-02 15:04:05"))
+	defer func() {
+		end := time.Now()
+		log.Printf("[info] [clone] end at %s, cost %s\n", end.Format("2006-01-02 15:04:05"), end.Sub(start))
+	}()
+
+	o := newDumpOption(opts)
+	if o.result != nil {
+		*o.result = DumpResult{}
+	} else {
+		o.result = &DumpResult{}
+	}
+	o.result.StartTime = start
+	defer func() {
+		o.result.EndTime = time.Now()
+	}()
+
+	srcDSN = o.prepareDSN(srcDSN)
+	cfg, err := checkDSN(srcDSN, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	dstDSN = o.prepareDSN(dstDSN)
+	if _, err = GetDBNameFromDSN(dstDSN); err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+
+	// 先打开目标库, openReadOnlyDB 设置的只读会话只用于源库
+	dst, err := openDB(dstDSN, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	defer dst.Close()
+	src, err := openReadOnlyDB(srcDSN, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	defer src.Close()
+	err = clone(context.Background(), src, dst, cfg.DBName, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+	}
+	return err
+}
+
+// clone 将 src 的 dbName 库复制到 dst
+// nolint: gocyclo
+func clone(ctx context.Context, src *sql.DB, dst *sql.DB, dbName string, o *dumpOption) error {
+	concurrency := o.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// 写入 TiDB 时使用 TiDB 模式的批量大小, 并允许写入 AUTO_RANDOM 列
+	dstVersion := getServerVersion(dst)
+	dstTiDB := dstVersion.Flavor == FlavorTiDB
+	o.applyTiDB(dstVersion)
+	if o.cloneBatchSize <= 0 {
+		o.cloneBatchSize = 1000
+	}
+
+	o.result.Database = dbName
+	o.database = dbName
+	o.serverVersion = getServerVersion(src)
+	o.result.ServerVersion = o.serverVersion
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 一致性快照, 元数据在快照连接上查询, 并发时每个 worker 使用同一时间点的快照连接
+	var q queryer = src
+	var snap *dumpConn
+	if o.singleTransaction {
+		var err error
+		snap, err = openSnapshot(ctx, src, o)
+		if err != nil {
+			return err
+		}
+		defer snap.Close()
+		defer o.snapshotConns.close()
+		q = snap
+	}
+
+	tables, noDataMap, err := selectTables(q, o)
+	if err != nil {
+		return err
+	}
+	// 视图在所有表复制完成后创建
+	var views []string
+	for len(tables) > 0 && o.views[tables[len(tables)-1]] {
+		views = append([]string{tables[len(tables)-1]}, views...)
+		tables = tables[:len(tables)-1]
+	}
+
+	results := make([]TableResult, len(tables))
+	errs := make([]error, len(tables))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 源连接: 只有一个 worker 时直接使用快照连接, 否则取一个已开启快照或重新设置会话的连接
+			var srcConn queryer = snap
+			var err error
+			if snap == nil || o.snapshotConns != nil {
+				var c *dumpConn
+				c, err = openWorkerConn(ctx, src, o)
+				if err == nil {
+					defer c.Close()
+					srcConn = c
+				}
+			}
+			// 每个 worker 使用独立的目标连接, 会话设置不会互相影响
+			var conn *sql.Conn
+			if err == nil {
+				conn, err = dst.Conn(ctx)
+			}
+			if err == nil {
+				defer conn.Close()
+				_, err = conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS=0, UNIQUE_CHECKS=0")
+			}
+			if err == nil && dstTiDB {
+				_, err = conn.ExecContext(ctx, "SET @@SESSION.allow_auto_random_explicit_insert = 1")
+			}
+			for idx := range queue {
+				if err != nil {
+					errs[idx] = err
+					cancel()
+					continue
+				}
+				results[idx], errs[idx] = cloneTable(ctx, srcConn, conn, tables[idx], noDataMap[tables[idx]], o)
+				if errs[idx] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+	for i := range tables {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("[error] [clone] %s: %v \n", tables[i], err)
+			return err
+		}
+	}
+	o.result.Tables = results
+
+	for _, view := range views {
+		err = cloneView(ctx, q, dst, view, o)
+		if err != nil {
+			log.Printf("[error] [clone] %s: %v \n", view, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneView 在目标库创建视图, 无效的视图根据选项跳过
+func cloneView(ctx context.Context, src queryer, dst *sql.DB, view string, o *dumpOption) error {
+	createViewSQL, err := getCreateViewSQL(src, view, o)
+	if err != nil {
+		if o.commentBrokenViews {
+			o.warnBrokenView(view, err)
+			return nil
+		}
+		return err
+	}
+	if o.isDropTable {
+		_, err = dst.ExecContext(ctx, "DROP VIEW IF EXISTS "+QuoteIdentifier(view))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dst.ExecContext(ctx, createViewSQL)
+	return err
+}
+
+// cloneTable 复制一个表的结构和数据
+func cloneTable(ctx context.Context, src queryer, dst *sql.Conn, table string, noData bool, o *dumpOption) (TableResult, error) {
+	result := TableResult{Name: table}
+	tableStart := time.Now()
+	if o.sequences[table] {
+		return result, cloneSequence(ctx, src, dst, table, o)
+	}
+
+	if o.isDropTable {
+		_, err := dst.ExecContext(ctx, "DROP TABLE IF EXISTS "+QuoteIdentifier(table))
+		if err != nil {
+			return result, err
+		}
+	}
+	createTableSQL, err := getCreateTableSQL(src, table, o)
+	if err != nil {
+		return result, err
+	}
+	_, err = dst.ExecContext(ctx, createTableSQL)
+	if err != nil {
+		return result, err
+	}
+
+	if o.isData && !noData {
+		if o.isTruncateTable {
+			_, err = dst.ExecContext(ctx, "TRUNCATE TABLE "+QuoteIdentifier(table))
+			if err != nil {
+				return result, err
+			}
+		}
+		result.Rows, err = cloneTableData(ctx, src, dst, table, o)
+		if err != nil {
+			return result, err
+		}
+	}
+	result.Duration = time.Since(tableStart)
+	log.Printf("[info] [clone] table %s, %d rows, cost %s\n", table, result.Rows, result.Duration)
+	return result, nil
+}
+
+// cloneTableData 按批读取源表数据并插入目标表
+func cloneTableData(ctx context.Context, src queryer, dst *sql.Conn, table string, o *dumpOption) (int64, error) {
+	selectList, _, conds, err := buildTableSelect(src, table, o)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf("%s %s FROM %s", o.selectKeyword(), selectList, QuoteIdentifier(table))
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := src.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	dataTypes := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		dataTypes[i] = columnType.DatabaseTypeName()
+	}
+	transforms := getColumnTransforms(o, table, columns, dataTypes)
+
+	// 占位符不能超过 65535 个
+	batchSize := o.cloneBatchSize
+	if max := 65535 / len(columns); batchSize > max {
+		batchSize = max
+	}
+	insert := "INSERT INTO " + QuoteIdentifier(table) + " (" + quoteColumns(columns) + ") VALUES "
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ")"
+
+	var count, scanned int64
+	args := make([]interface{}, 0, batchSize*len(columns))
+	flush := func(n int) error {
+		if n == 0 {
+			return nil
+		}
+		_, err := dst.ExecContext(ctx, insert+strings.TrimSuffix(strings.Repeat(placeholder+",", n), ","), args...)
+		args = args[:0]
+		return err
+	}
+
+	pending := 0
+	for rows.Next() {
+		row := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return count, err
+		}
+		scanned++
+		if o.sampleEvery > 1 && (scanned-1)%int64(o.sampleEvery) != 0 {
+			continue
+		}
+		for i, transform := range transforms {
+			if transform != nil {
+				row[i] = transform(row[i])
+			}
+		}
+		args = append(args, row...)
+		pending++
+		count++
+		if pending == batchSize {
+			err = flush(pending)
+			if err != nil {
+				return count, err
+			}
+			pending = 0
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return count, err
+	}
+	return count, flush(pending)
+}
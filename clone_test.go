@@ -0,0 +1,63 @@
+package mysqldump
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeClone 将 newFakeDumpDB 的库复制到空的 fakeDB, 返回目标库执行的语句
+func fakeClone(t *testing.T, opts ...DumpOption) (*fakeDB, []string) {
+	src, fsrc := newFakeDumpDB(t)
+	dst, fdst := newFakeDB(t)
+	o := newDumpOption(opts)
+	o.result = &DumpResult{}
+	if err := clone(context.Background(), src, dst, "test", o); err != nil {
+		t.Fatalf("clone() error = %v", err)
+	}
+	if len(o.result.Tables) != 2 {
+		t.Errorf("result tables = %v, want a and b", o.result.Tables)
+	}
+	return fsrc, fdst.executed()
+}
+
+func TestClone(t *testing.T) {
+	_, stmts := fakeClone(t, WithData())
+	joined := strings.Join(stmts, "\n")
+	for _, want := range []string{"CREATE TABLE IF NOT EXISTS `a` (`id` int)", "INSERT INTO `a` (`id`) VALUES (?),(?)", "CREATE TABLE IF NOT EXISTS `b` (`id` int)", "INSERT INTO `b` (`id`) VALUES (?),(?)"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("target statements = %q, want %q", stmts, want)
+		}
+	}
+}
+
+func TestClone_singleTransaction(t *testing.T) {
+	// 并发时快照连接和每个 worker 连接都开启快照
+	for concurrency, want := range map[int]int{1: 1, 2: 3} {
+		fsrc, _ := fakeClone(t, WithData(), WithSingleTransaction(), WithConcurrency(concurrency))
+		// 读取数据的连接都已开启快照
+		snapshots := make(map[int]bool)
+		queries, conns := fsrc.executed(), fsrc.executedConns()
+		for i, query := range queries {
+			if strings.HasPrefix(query, "START TRANSACTION") {
+				snapshots[conns[i]] = true
+			}
+			if strings.HasPrefix(query, "SELECT `id` FROM") && !snapshots[conns[i]] {
+				t.Errorf("concurrency %d: %s on connection %d without a snapshot", concurrency, query, conns[i])
+			}
+		}
+		if len(snapshots) != want {
+			t.Errorf("concurrency %d: snapshots on %d connections, want %d", concurrency, len(snapshots), want)
+		}
+	}
+}
+
+func TestClone_invalidDSN(t *testing.T) {
+	// 连接前检查 DSN
+	for _, dsns := range [][2]string{{"root@tcp(127.0.0.1:1)/", "root@tcp(127.0.0.1:1)/dst"}, {"root@tcp(127.0.0.1:1)/src", "root@tcp(127.0.0.1:1)/"}} {
+		if err := Clone(dsns[0], dsns[1]); !errors.Is(err, ErrInvalidDSN) {
+			t.Errorf("Clone(%s, %s) error = %v, want ErrInvalidDSN", dsns[0], dsns[1], err)
+		}
+	}
+}
@@ -0,0 +1,728 @@
+// mysqldump 命令行工具
+//
+// 导出:
+//
+//	mysqldump -dsn 'root:pass@tcp(localhost:3306)/db?charset=utf8mb4&parseTime=true' -data -output dump.sql.gz -gzip
+//
+// 恢复:
+//
+//	mysqldump source -dsn 'root:pass@tcp(localhost:3306)/db?charset=utf8mb4' -merge-insert 1000 dump.sql
+//
+// 按表拆分:
+//
+//	mysqldump split -output dir dump.sql.gz
+//
+// 检查导出文件是否损坏:
+//
+//	mysqldump validate dump.sql.gz
+//
+// 比较导出文件与数据库的表结构:
+//
+//	mysqldump diff -dsn 'root:pass@tcp(localhost:3306)/db' dump.sql
+//
+// 比较数据, 导出文件与数据库或两个数据库:
+//
+//	mysqldump diff -data -dsn 'root:pass@tcp(localhost:3306)/db' dump.sql
+//	mysqldump diff -data -src-dsn 'root:pass@tcp(primary:3306)/db' -dsn 'root:pass@tcp(replica:3306)/db'
+//
+// 计算表的数据校验和:
+//
+//	mysqldump checksum -dsn 'root:pass@tcp(localhost:3306)/db' -tables users,orders
+//
+// 生成两个导出文件之间的表结构迁移语句:
+//
+//	mysqldump migrate old.sql new.sql
+//
+// 所有参数都可以通过环境变量设置, 如 -ignore-tables 对应 MYSQLDUMP_IGNORE_TABLES, 命令行参数优先
+package main
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ai-mmo/mysqldump"
+)
+
+func main() {
+	args := os.Args[1:]
+	cmd := "dump"
+	if len(args) > 0 && (args[0] == "dump" || args[0] == "source" || args[0] == "split" || args[0] == "validate" || args[0] == "diff" || args[0] == "migrate" || args[0] == "checksum") {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "source":
+		err = runSource(args)
+	case "split":
+		err = runSplit(args)
+	case "validate":
+		err = runValidate(args)
+	case "diff":
+		err = runDiff(args)
+	case "migrate":
+		err = runMigrate(args)
+	case "checksum":
+		err = runChecksum(args)
+	default:
+		err = runDump(args)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// splitList 解析逗号分隔的列表
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseFlags 先从环境变量设置参数, 再解析命令行参数
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		name := "MYSQLDUMP_" + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if v, ok := os.LookupEnv(name); ok && err == nil {
+			if serr := f.Value.Set(v); serr != nil {
+				err = fmt.Errorf("%s: %v", name, serr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return fs.Parse(args)
+}
+
+func runDump(args []string) error {
+	c, err := parseDumpArgs(args)
+	if err != nil {
+		return err
+	}
+	if c.config != "" {
+		return mysqldump.DumpFromConfig(c.config)
+	}
+	return c.run()
+}
+
+// dumpCommand 解析后的导出参数
+type dumpCommand struct {
+	config string
+	dsn    string
+	// sql 格式的输出文件, 为空时输出到标准输出; 其他格式输出到目录, 选项中已设置
+	output     string
+	directory  bool
+	gzip       bool
+	tee        string
+	checkpoint string
+	opts       []mysqldump.DumpOption
+	// 设置了选项的参数名, 与 opts 对应
+	flags []string
+}
+
+// add 添加参数 name 对应的选项
+func (c *dumpCommand) add(name string, opt mysqldump.DumpOption) {
+	c.flags = append(c.flags, name)
+	c.opts = append(c.opts, opt)
+}
+
+// parseDumpArgs 解析导出参数并转换为选项, 不打开输出文件
+func parseDumpArgs(args []string) (*dumpCommand, error) {
+	fs := flag.NewFlagSet("mysqldump", flag.ExitOnError)
+	config := fs.String("config", "", "JSON 配置文件, 指定后忽略其他参数")
+	dsn := fs.String("dsn", "", "MySQL DSN, 如 user:pass@tcp(host:3306)/db, 没有设置时自动加上 parseTime, charset 和 maxAllowedPacket")
+	noDSNDefaults := fs.Bool("no-dsn-defaults", false, "按原样使用 DSN, 不自动加参数")
+	compress := fs.Bool("compress", false, "使用 MySQL 压缩协议, 通过广域网导出时减少网络流量")
+	readOnlyGuard := fs.Bool("read-only-guard", false, "连接设置为只读会话, DSN 有 multiStatements=true 时拒绝导出")
+	data := fs.Bool("data", false, "导出表数据")
+	schemaFirst := fs.Bool("schema-first", false, "先输出所有表结构, 再输出所有表数据")
+	tables := fs.String("tables", "", "导出指定表, 逗号分隔, 默认全部表")
+	ignoreTables := fs.String("ignore-tables", "", "排除指定表, 逗号分隔")
+	noDataTables := fs.String("no-data-tables", "", "只导出表结构的表, 逗号分隔")
+	ignoreEngines := fs.String("ignore-engines", "", "排除指定存储引擎的表, 逗号分隔")
+	maxTableSize := fs.Int64("max-table-size", 0, "排除超过指定字节数的表")
+	compat := fs.Bool("mysqldump-compat", false, "输出与官方 mysqldump 相同的格式")
+	compact := fs.Bool("compact", false, "不输出注释和空行")
+	hexBlob := fs.Bool("hex-blob", true, "二进制列输出为十六进制, false 时输出为 _binary '...'")
+	jsonCast := fs.Bool("json-cast", false, "JSON 列输出为 CAST('...' AS JSON)")
+	compactJSON := fs.Bool("compact-json", false, "去掉 JSON 列中的空白")
+	timeZone := fs.String("time-zone", "", "会话时区, TIMESTAMP 按此时区输出, 如 UTC, Asia/Shanghai")
+	dropDatabase := fs.Bool("drop-database", false, "删除并重新创建数据库")
+	dropTable := fs.Bool("drop-table", false, "创建表前删除表")
+	truncateTable := fs.Bool("truncate-table", false, "导出数据前清空表")
+	disableKeys := fs.Bool("disable-keys", false, "表数据前后加上 DISABLE KEYS 和 ENABLE KEYS")
+	addLocks := fs.Bool("add-locks", false, "表数据前后加上 LOCK TABLES 和 UNLOCK TABLES")
+	insertIgnore := fs.Bool("insert-ignore", false, "使用 INSERT IGNORE")
+	format := fs.String("format", "sql", "输出格式: sql, csv, tsv, tab, jsonl")
+	dialect := fs.String("dialect", "mysql", "sql 格式的方言: mysql, postgres, sqlite")
+	output := fs.String("output", "", "输出文件, 默认标准输出; csv, tsv, tab 和 jsonl 格式为输出目录")
+	gz := fs.Bool("gzip", false, "gzip 压缩输出")
+	tee := fs.String("tee", "", "同时写到的文件, 不压缩, 不能与 -checkpoint 同时使用")
+	bufferSize := fs.Int("buffer-size", 0, "输出缓冲区的字节数, 默认 4096")
+	flushInterval := fs.Duration("flush-interval", 0, "缓冲的内容最多保留的时间, 如 1s, 默认缓冲区写满时才写出")
+	concurrency := fs.Int("concurrency", 1, "同时导出的表数")
+	chunkSize := fs.Int("chunk-size", 0, "按主键分块导出的行数")
+	partitionConcurrency := fs.Int("partition-concurrency", 0, "分区表同时导出的分区数")
+	maxOpenConns := fs.Int("max-open-conns", 0, "最大连接数, 默认按并发数计算, 应大于并发数")
+	connMaxLifetime := fs.Duration("conn-max-lifetime", 0, "连接的最长使用时间, 如 10m")
+	selectHints := fs.String("select-hints", "", "逗号分隔的 SELECT 提示, 如 SQL_NO_CACHE")
+	sessionVars := fs.String("session-vars", "", "逗号分隔的会话变量, 如 net_read_timeout=3600,wait_timeout=28800")
+	skipPartition := fs.Bool("skip-partition", false, "去掉 CREATE TABLE 中的分区定义")
+	singleTransaction := fs.Bool("single-transaction", false, "在一致性快照事务中导出")
+	lockAllTables := fs.Bool("lock-all-tables", false, "开启快照事务前短暂加全局读锁, 记录一致的 binlog 位置")
+	lockNonTransactional := fs.Bool("lock-non-transactional", false, "导出 MyISAM 等非事务引擎表时加 LOCK TABLES READ LOCAL")
+	checkpoint := fs.String("checkpoint", "", "断点文件, 中断后重新执行从断点继续")
+	sampleEvery := fs.Int("sample-every", 0, "每 n 行导出一行")
+	maskRules := fs.String("mask-rules", "", "JSON 脱敏规则文件")
+	skipFailed := fs.Bool("skip-failed-tables", false, "表导出失败时跳过并继续")
+	serverVariables := fs.Bool("server-variables", false, "在文件头记录源库的常用全局变量")
+	manifest := fs.String("manifest", "", "导出成功后写出 JSON 清单的路径")
+	noProvenance := fs.Bool("no-provenance", false, "文件头不输出来源库, 工具版本, 选项和执行用户")
+	summaryFooter := fs.Bool("summary-footer", false, "文件尾输出 JSON 格式的摘要, 包含每个表的行数和 sha256")
+	grants := fs.Bool("grants", false, "导出对数据库有权限的账号和权限")
+	skipDefiner := fs.Bool("skip-definer", false, "去掉视图等对象的 DEFINER")
+	skipAutoIncrement := fs.Bool("skip-auto-increment", false, "去掉 CREATE TABLE 中的 AUTO_INCREMENT=N")
+	noIfNotExists := fs.Bool("no-if-not-exists", false, "CREATE TABLE 不加 IF NOT EXISTS")
+	qualifiedNames := fs.Bool("qualified-names", false, "表名加上数据库名, 如 db.table, 不输出 USE")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if *config != "" {
+		return &dumpCommand{config: *config}, nil
+	}
+	if *dsn == "" {
+		return nil, fmt.Errorf("-dsn or MYSQLDUMP_DSN is required")
+	}
+
+	c := &dumpCommand{dsn: *dsn, output: *output, gzip: *gz, tee: *tee, checkpoint: *checkpoint}
+	c.add("concurrency", mysqldump.WithConcurrency(*concurrency))
+	c.add("chunk-size", mysqldump.WithChunkSize(*chunkSize))
+	c.add("partition-concurrency", mysqldump.WithPartitionConcurrency(*partitionConcurrency))
+	c.add("max-open-conns", mysqldump.WithMaxOpenConns(*maxOpenConns))
+	c.add("conn-max-lifetime", mysqldump.WithConnMaxLifetime(*connMaxLifetime))
+	c.add("sample-every", mysqldump.WithSampleEvery(*sampleEvery))
+	c.add("max-table-size", mysqldump.WithMaxTableSize(*maxTableSize))
+	if *data {
+		c.add("data", mysqldump.WithData())
+	}
+	if *schemaFirst {
+		c.add("schema-first", mysqldump.WithSchemaFirst())
+	}
+	if list := splitList(*tables); len(list) > 0 {
+		c.add("tables", mysqldump.WithTables(list...))
+	}
+	if list := splitList(*ignoreTables); len(list) > 0 {
+		c.add("ignore-tables", mysqldump.WithIgnoreTables(list...))
+	}
+	if list := splitList(*noDataTables); len(list) > 0 {
+		c.add("no-data-tables", mysqldump.WithNoDataTables(list...))
+	}
+	if list := splitList(*ignoreEngines); len(list) > 0 {
+		c.add("ignore-engines", mysqldump.WithIgnoreEngines(list...))
+	}
+	if list := splitList(*sessionVars); len(list) > 0 {
+		vars := make(map[string]string, len(list))
+		for _, item := range list {
+			name, value, ok := strings.Cut(item, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid -session-vars item %q", item)
+			}
+			vars[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		c.add("session-vars", mysqldump.WithSessionVars(vars))
+	}
+	if list := splitList(*selectHints); len(list) > 0 {
+		c.add("select-hints", mysqldump.WithSelectHints(list...))
+	}
+	if *compat {
+		c.add("mysqldump-compat", mysqldump.WithMySQLDumpCompat())
+	}
+	if *compact {
+		c.add("compact", mysqldump.WithCompact())
+	}
+	if !*hexBlob {
+		c.add("hex-blob", mysqldump.WithHexBlob(false))
+	}
+	if *jsonCast {
+		c.add("json-cast", mysqldump.WithJSONCast())
+	}
+	if *compactJSON {
+		c.add("compact-json", mysqldump.WithCompactJSON())
+	}
+	if *timeZone != "" {
+		loc, err := time.LoadLocation(*timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -time-zone: %v", err)
+		}
+		c.add("time-zone", mysqldump.WithTimeZone(loc))
+	}
+	if *skipPartition {
+		c.add("skip-partition", mysqldump.WithoutPartitions())
+	}
+	if *noDSNDefaults {
+		c.add("no-dsn-defaults", mysqldump.WithoutDSNDefaults())
+	}
+	if *compress {
+		c.add("compress", mysqldump.WithCompressProtocol())
+	}
+	if *readOnlyGuard {
+		c.add("read-only-guard", mysqldump.WithReadOnlyGuard())
+	}
+	if *bufferSize > 0 {
+		c.add("buffer-size", mysqldump.WithBufferSize(*bufferSize))
+	}
+	if *flushInterval > 0 {
+		c.add("flush-interval", mysqldump.WithFlushInterval(*flushInterval))
+	}
+	if *dropDatabase {
+		c.add("drop-database", mysqldump.WithAddDropDatabase())
+	}
+	if *dropTable {
+		c.add("drop-table", mysqldump.WithDropTable())
+	}
+	if *truncateTable {
+		c.add("truncate-table", mysqldump.WithTruncateTable())
+	}
+	if *disableKeys {
+		c.add("disable-keys", mysqldump.WithDisableKeys())
+	}
+	if *addLocks {
+		c.add("add-locks", mysqldump.WithAddLocks())
+	}
+	if *insertIgnore {
+		c.add("insert-ignore", mysqldump.WithIgnoreInsertTable())
+	}
+	if *singleTransaction {
+		c.add("single-transaction", mysqldump.WithSingleTransaction())
+	}
+	if *lockAllTables {
+		c.add("lock-all-tables", mysqldump.WithLockAllTables())
+	}
+	if *lockNonTransactional {
+		c.add("lock-non-transactional", mysqldump.WithLockNonTransactionalTables())
+	}
+	if *checkpoint != "" {
+		c.add("checkpoint", mysqldump.WithCheckpoint(*checkpoint))
+	}
+	if *skipFailed {
+		c.add("skip-failed-tables", mysqldump.WithSkipFailedTables())
+	}
+	if *serverVariables {
+		c.add("server-variables", mysqldump.WithServerVariables())
+	}
+	if *manifest != "" {
+		c.add("manifest", mysqldump.WithManifest(*manifest))
+	}
+	if *noProvenance {
+		c.add("no-provenance", mysqldump.WithoutProvenance())
+	}
+	if *summaryFooter {
+		c.add("summary-footer", mysqldump.WithSummaryFooter())
+	}
+	if *grants {
+		c.add("grants", mysqldump.WithGrants())
+	}
+	if *skipDefiner {
+		c.add("skip-definer", mysqldump.WithSkipDefiner())
+	}
+	if *skipAutoIncrement {
+		c.add("skip-auto-increment", mysqldump.WithoutAutoIncrementValue())
+	}
+	if *noIfNotExists {
+		c.add("no-if-not-exists", mysqldump.WithoutIfNotExists())
+	}
+	if *qualifiedNames {
+		c.add("qualified-names", mysqldump.WithQualifiedNames())
+	}
+	if *maskRules != "" {
+		rules, err := mysqldump.LoadMaskRulesFile(*maskRules)
+		if err != nil {
+			return nil, err
+		}
+		c.add("mask-rules", mysqldump.WithMaskRules(rules...))
+	}
+
+	switch *format {
+	case "sql":
+		switch d := mysqldump.Dialect(*dialect); d {
+		case mysqldump.DialectMySQL, mysqldump.DialectPostgres, mysqldump.DialectSQLite:
+			c.add("dialect", mysqldump.WithDialect(d))
+		default:
+			return nil, fmt.Errorf("unknown -dialect %q", *dialect)
+		}
+	case "csv", "tsv":
+		if *output == "" {
+			return nil, fmt.Errorf("-output directory is required for -format %s", *format)
+		}
+		f := mysqldump.NewCSVFormatter()
+		if *format == "tsv" {
+			f = mysqldump.NewTSVFormatter()
+		}
+		c.add("format", mysqldump.WithCSV(*output, f))
+		c.directory = true
+	case "tab", "jsonl":
+		if *output == "" {
+			return nil, fmt.Errorf("-output directory is required for -format %s", *format)
+		}
+		if *format == "tab" {
+			c.add("format", mysqldump.WithTab(*output))
+		} else {
+			c.add("format", mysqldump.WithJSONL(*output))
+		}
+		c.directory = true
+	default:
+		return nil, fmt.Errorf("unknown -format %q", *format)
+	}
+	if *gz && *checkpoint != "" {
+		return nil, fmt.Errorf("-gzip can not be used with -checkpoint")
+	}
+	if *tee != "" && *checkpoint != "" {
+		return nil, fmt.Errorf("-tee can not be used with -checkpoint")
+	}
+	return c, nil
+}
+
+// run 打开输出并导出, 导出后依次关闭 gzip, 输出文件和 tee 文件, 返回第一个错误
+func (c *dumpCommand) run() error {
+	if c.directory {
+		return mysqldump.Dump(c.dsn, append(c.opts, mysqldump.WithWriter(io.Discard))...)
+	}
+
+	var closers []io.Closer
+	var w io.Writer = os.Stdout
+	if c.output != "" {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if c.checkpoint != "" {
+			// 断点续传时由 Dump 截断到断点位置
+			flags = os.O_CREATE | os.O_WRONLY
+		}
+		f, err := os.OpenFile(c.output, flags, 0644)
+		if err != nil {
+			return err
+		}
+		closers = append(closers, f)
+		w = f
+	}
+	if c.gzip {
+		zw := gzip.NewWriter(w)
+		// 先于输出文件关闭, 写出 gzip 的结尾
+		closers = append([]io.Closer{zw}, closers...)
+		w = zw
+	}
+	opts := append(c.opts, mysqldump.WithWriter(w))
+	if c.tee != "" {
+		f, err := os.Create(c.tee)
+		if err != nil {
+			return closeAll(closers, err)
+		}
+		closers = append(closers, f)
+		opts = append(opts, mysqldump.WithTee(f))
+	}
+
+	return closeAll(closers, mysqldump.Dump(c.dsn, opts...))
+}
+
+// closeAll 依次关闭, err 为 nil 时返回第一个关闭错误
+func closeAll(closers []io.Closer, err error) error {
+	for _, closer := range closers {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func runSource(args []string) error {
+	fs := flag.NewFlagSet("mysqldump source", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "MySQL DSN")
+	mergeInsert := fs.Int("merge-insert", 0, "合并 n 条 INSERT 为一条执行")
+	dryRun := fs.Bool("dry-run", false, "只打印不执行")
+	debug := fs.Bool("debug", false, "打印执行的 SQL")
+	loadData := fs.Bool("load-data", false, "使用 LOAD DATA LOCAL INFILE 导入 INSERT 的数据, 需要服务端开启 local_infile")
+	tab := fs.Bool("tab", false, "参数为 -format tab 导出的目录, 使用 LOAD DATA LOCAL INFILE 导入")
+	tables := fs.String("tables", "", "逗号分隔的表名, 只恢复这些表")
+	ignoreTables := fs.String("ignore-tables", "", "逗号分隔的表名, 恢复时跳过")
+	batchSize := fs.Int("batch-size", 0, "每执行 n 条语句提交一次, 默认结束时提交")
+	rateLimit := fs.Int("rate-limit", 0, "每秒最多执行的语句数")
+	requireFooter := fs.Bool("require-footer", false, "没有 -- Dump completed 文件尾时报错并且不提交")
+	schemaOnly := fs.Bool("schema-only", false, "只恢复表结构")
+	dataOnly := fs.Bool("data-only", false, "只恢复数据")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *dsn == "" {
+		return fmt.Errorf("-dsn or MYSQLDUMP_DSN is required")
+	}
+
+	var opts []mysqldump.SourceOption
+	if list := splitList(*tables); len(list) > 0 {
+		opts = append(opts, mysqldump.WithRestoreTables(list...))
+	}
+	if list := splitList(*ignoreTables); len(list) > 0 {
+		opts = append(opts, mysqldump.WithRestoreIgnoreTables(list...))
+	}
+	if *schemaOnly {
+		opts = append(opts, mysqldump.WithSchemaOnly())
+	}
+	if *dataOnly {
+		opts = append(opts, mysqldump.WithDataOnly())
+	}
+	if *tab {
+		if *dryRun {
+			opts = append(opts, mysqldump.WithDryRun())
+		}
+		if *debug {
+			opts = append(opts, mysqldump.WithDebug())
+		}
+		return mysqldump.SourceTab(*dsn, fs.Arg(0), opts...)
+	}
+
+	r, closeInput, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	if *batchSize > 0 {
+		opts = append(opts, mysqldump.WithRestoreBatchSize(*batchSize))
+	}
+	if *rateLimit > 0 {
+		opts = append(opts, mysqldump.WithRestoreRateLimit(*rateLimit))
+	}
+	if *requireFooter {
+		opts = append(opts, mysqldump.WithRequireFooter())
+	}
+	if *mergeInsert > 1 {
+		opts = append(opts, mysqldump.WithMergeInsert(*mergeInsert))
+	}
+	if *dryRun {
+		opts = append(opts, mysqldump.WithDryRun())
+	}
+	if *debug {
+		opts = append(opts, mysqldump.WithDebug())
+	}
+	if *loadData {
+		opts = append(opts, mysqldump.WithLoadData())
+	}
+	return mysqldump.Source(*dsn, r, opts...)
+}
+
+// openInput 打开导出文件, 为空或 - 时读取标准输入, .gz 结尾时解压
+func openInput(name string) (io.Reader, func(), error) {
+	if name == "" || name == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasSuffix(name, ".gz") {
+		return f, func() { f.Close() }, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return zr, func() {
+		zr.Close()
+		f.Close()
+	}, nil
+}
+
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("mysqldump split", flag.ExitOnError)
+	output := fs.String("output", "", "输出目录")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return fmt.Errorf("-output is required")
+	}
+	r, closeInput, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+	return mysqldump.SplitDump(r, *output)
+}
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("mysqldump validate", flag.ExitOnError)
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	r, closeInput, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+	return mysqldump.Validate(r)
+}
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("mysqldump diff", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "MySQL DSN")
+	srcDSN := fs.String("src-dsn", "", "与 -dsn 比较的源数据库, 为空时与导出文件比较")
+	data := fs.Bool("data", false, "比较数据而不是表结构")
+	chunkSize := fs.Int("chunk-size", 0, "比较两个数据库的数据时每块的行数")
+	tables := fs.String("tables", "", "逗号分隔的表名, 只比较这些表")
+	ignoreTables := fs.String("ignore-tables", "", "逗号分隔的表名, 不比较")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *dsn == "" {
+		return fmt.Errorf("-dsn or MYSQLDUMP_DSN is required")
+	}
+	var opts []mysqldump.DumpOption
+	if list := splitList(*tables); len(list) > 0 {
+		opts = append(opts, mysqldump.WithTables(list...))
+	}
+	if list := splitList(*ignoreTables); len(list) > 0 {
+		opts = append(opts, mysqldump.WithIgnoreTables(list...))
+	}
+	if *chunkSize > 0 {
+		opts = append(opts, mysqldump.WithChunkSize(*chunkSize))
+	}
+
+	if *data {
+		var diff *mysqldump.DataDiff
+		if *srcDSN != "" {
+			diff, err = mysqldump.DiffData(*srcDSN, *dsn, opts...)
+		} else {
+			r, closeInput, openErr := openInput(fs.Arg(0))
+			if openErr != nil {
+				return openErr
+			}
+			defer closeInput()
+			diff, err = mysqldump.DiffDumpData(r, *dsn, opts...)
+		}
+		if err != nil {
+			return err
+		}
+		for _, table := range diff.Tables {
+			fmt.Printf("~ table %s\n", table.Name)
+			for _, key := range table.MissingRows {
+				fmt.Printf("  - row %s\n", key)
+			}
+			for _, key := range table.ExtraRows {
+				fmt.Printf("  + row %s\n", key)
+			}
+			for _, key := range table.ChangedRows {
+				fmt.Printf("  ~ row %s\n", key)
+			}
+		}
+		if !diff.Empty() {
+			return fmt.Errorf("data differs")
+		}
+		return nil
+	}
+
+	r, closeInput, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+	diff, err := mysqldump.DiffSchema(r, *dsn, opts...)
+	if err != nil {
+		return err
+	}
+	for _, table := range diff.AddedTables {
+		fmt.Printf("+ table %s\n", table)
+	}
+	for _, table := range diff.RemovedTables {
+		fmt.Printf("- table %s\n", table)
+	}
+	for _, table := range diff.ChangedTables {
+		fmt.Printf("~ table %s\n", table.Name)
+		for _, column := range table.AddedColumns {
+			fmt.Printf("  + column %s\n", column)
+		}
+		for _, column := range table.RemovedColumns {
+			fmt.Printf("  - column %s\n", column)
+		}
+		for _, column := range table.ChangedColumns {
+			fmt.Printf("  ~ column %s: %s -> %s\n", column.Name, column.From, column.To)
+		}
+	}
+	if !diff.Empty() {
+		return fmt.Errorf("schema differs")
+	}
+	return nil
+}
+
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("mysqldump migrate", flag.ExitOnError)
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: mysqldump migrate old.sql new.sql")
+	}
+	from, closeFrom, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer closeFrom()
+	to, closeTo, err := openInput(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer closeTo()
+	statements, err := mysqldump.GenerateMigration(from, to)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		fmt.Println(stmt + ";")
+	}
+	return nil
+}
+
+func runChecksum(args []string) error {
+	fs := flag.NewFlagSet("mysqldump checksum", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "MySQL DSN")
+	tables := fs.String("tables", "", "逗号分隔的表名, 为空时计算所有表")
+	ignoreTables := fs.String("ignore-tables", "", "逗号分隔的表名, 不计算")
+	chunkSize := fs.Int("chunk-size", 0, "每块的行数")
+	err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if *dsn == "" {
+		return fmt.Errorf("-dsn or MYSQLDUMP_DSN is required")
+	}
+	var opts []mysqldump.DumpOption
+	if list := splitList(*ignoreTables); len(list) > 0 {
+		opts = append(opts, mysqldump.WithIgnoreTables(list...))
+	}
+	if *chunkSize > 0 {
+		opts = append(opts, mysqldump.WithChunkSize(*chunkSize))
+	}
+	checksums, err := mysqldump.ChecksumTables(*dsn, splitList(*tables), opts...)
+	if err != nil {
+		return err
+	}
+	for _, checksum := range checksums {
+		fmt.Printf("%s\t%d\t%s\n", checksum.Name, checksum.Rows, checksum.Checksum)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_parseDumpArgs(t *testing.T) {
+	dsn := "root@tcp(localhost:3306)/db"
+	base := []string{"concurrency", "chunk-size", "partition-concurrency", "max-open-conns", "conn-max-lifetime", "sample-every", "max-table-size"}
+	tests := []struct {
+		name      string
+		args      []string
+		flags     []string
+		directory bool
+		wantErr   bool
+	}{
+		{name: "defaults", args: []string{"-dsn", dsn}, flags: []string{"dialect"}},
+		{
+			name:  "options",
+			args:  []string{"-dsn", dsn, "-data", "-tables", "a, b", "-hex-blob=false", "-session-vars", "wait_timeout=60", "-time-zone", "UTC", "-skip-failed-tables"},
+			flags: []string{"data", "tables", "session-vars", "hex-blob", "time-zone", "skip-failed-tables", "dialect"},
+		},
+		{name: "empty list", args: []string{"-dsn", dsn, "-tables", " , "}, flags: []string{"dialect"}},
+		{name: "csv", args: []string{"-dsn", dsn, "-format", "csv", "-output", "dir"}, flags: []string{"format"}, directory: true},
+		{name: "no dsn", args: []string{"-data"}, wantErr: true},
+		{name: "unknown format", args: []string{"-dsn", dsn, "-format", "xml"}, wantErr: true},
+		{name: "unknown dialect", args: []string{"-dsn", dsn, "-dialect", "oracle"}, wantErr: true},
+		{name: "csv without output", args: []string{"-dsn", dsn, "-format", "csv"}, wantErr: true},
+		{name: "bad session vars", args: []string{"-dsn", dsn, "-session-vars", "wait_timeout"}, wantErr: true},
+		{name: "bad time zone", args: []string{"-dsn", dsn, "-time-zone", "Nowhere/City"}, wantErr: true},
+		{name: "gzip checkpoint", args: []string{"-dsn", dsn, "-output", "db.sql", "-gzip", "-checkpoint", "c.json"}, wantErr: true},
+		{name: "tee checkpoint", args: []string{"-dsn", dsn, "-output", "db.sql", "-tee", "t.sql", "-checkpoint", "c.json"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseDumpArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDumpArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := append(base[:len(base):len(base)], tt.flags...); !reflect.DeepEqual(c.flags, want) {
+				t.Errorf("flags = %v, want %v", c.flags, want)
+			}
+			if len(c.opts) != len(c.flags) {
+				t.Errorf("len(opts) = %d, want %d", len(c.opts), len(c.flags))
+			}
+			if c.dsn != dsn || c.directory != tt.directory {
+				t.Errorf("dsn = %s, directory = %v", c.dsn, c.directory)
+			}
+		})
+	}
+}
+
+func Test_parseDumpArgs_env(t *testing.T) {
+	t.Setenv("MYSQLDUMP_DSN", "root@tcp(env:3306)/db")
+	t.Setenv("MYSQLDUMP_IGNORE_TABLES", "logs")
+	t.Setenv("MYSQLDUMP_GZIP", "true")
+
+	// 命令行参数优先
+	c, err := parseDumpArgs([]string{"-dsn", "root@tcp(flag:3306)/db", "-output", "db.sql.gz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.dsn != "root@tcp(flag:3306)/db" || !c.gzip || c.output != "db.sql.gz" {
+		t.Errorf("dsn = %s, gzip = %v, output = %s", c.dsn, c.gzip, c.output)
+	}
+	if !contains(c.flags, "ignore-tables") {
+		t.Errorf("flags = %v, want ignore-tables from environment", c.flags)
+	}
+
+	t.Setenv("MYSQLDUMP_GZIP", "maybe")
+	if _, err := parseDumpArgs(nil); err == nil {
+		t.Error("parseDumpArgs() want error for invalid MYSQLDUMP_GZIP")
+	}
+}
+
+func Test_parseDumpArgs_config(t *testing.T) {
+	c, err := parseDumpArgs([]string{"-config", "prod.json"})
+	if err != nil || c.config != "prod.json" {
+		t.Errorf("parseDumpArgs() = %+v, %v", c, err)
+	}
+}
+
+func Test_closeAll(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := os.Create(filepath.Join(dir, "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = g.Close()
+
+	// g 已关闭, 返回关闭错误, f 仍然关闭
+	if err := closeAll([]io.Closer{g, f}, nil); err == nil {
+		t.Error("closeAll() want close error")
+	}
+	if err := f.Close(); err == nil {
+		t.Error("closeAll() did not close every closer")
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+// mysqldumpd 导出 HTTP 服务
+//
+//	mysqldumpd -dsn 'root:pass@tcp(localhost:3306)/db?charset=utf8mb4&parseTime=true' -addr :8080 -dir /backup
+//
+// 参数也可以通过环境变量 MYSQLDUMP_DSN, MYSQLDUMPD_ADDR, MYSQLDUMPD_DIR, MYSQLDUMPD_TOKEN 设置
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/ai-mmo/mysqldump"
+	"github.com/ai-mmo/mysqldump/server"
+)
+
+func env(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
+func main() {
+	dsn := flag.String("dsn", env("MYSQLDUMP_DSN", ""), "MySQL DSN")
+	addr := flag.String("addr", env("MYSQLDUMPD_ADDR", ":8080"), "监听地址")
+	dir := flag.String("dir", env("MYSQLDUMPD_DIR", "."), "导出文件目录")
+	token := flag.String("token", env("MYSQLDUMPD_TOKEN", ""), "访问令牌, 为空时不认证")
+	flag.Parse()
+
+	if *dsn == "" {
+		log.Fatal("-dsn or MYSQLDUMP_DSN is required")
+	}
+	err := os.MkdirAll(*dir, 0755)
+	if err != nil {
+		log.Fatal(err)
+	}
+	catalog, err := mysqldump.OpenCatalog(filepath.Join(*dir, "catalog.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := server.New(*dsn, *dir, catalog)
+	s.Token = *token
+	log.Printf("[info] [mysqldumpd] listen on %s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
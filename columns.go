@@ -0,0 +1,67 @@
+package mysqldump
+
+import (
+	"strings"
+)
+
+// tableColumn information_schema.COLUMNS 中导出数据需要的列信息
+type tableColumn struct {
+	name string
+	// 虚拟列或存储的生成列, 值由表达式计算, 不能写入
+	generated bool
+	// MySQL 8.0.23 的不可见列, SELECT * 不返回
+	invisible bool
+}
+
+// getTableColumns 按定义顺序获取表的列
+func getTableColumns(db queryer, table string) ([]tableColumn, error) {
+	rows, err := db.Query("SELECT COLUMN_NAME, IFNULL(EXTRA, '') FROM information_schema.COLUMNS "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []tableColumn
+	for rows.Next() {
+		var name, extra string
+		err = rows.Scan(&name, &extra)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, parseColumnExtra(name, extra))
+	}
+	return columns, rows.Err()
+}
+
+// parseColumnExtra 根据 EXTRA 判断列的类型
+// 生成列为 VIRTUAL GENERATED, STORED GENERATED 或 MariaDB 的 PERSISTENT GENERATED,
+// 表达式默认值的 DEFAULT_GENERATED 不是生成列
+func parseColumnExtra(name, extra string) tableColumn {
+	column := tableColumn{name: name}
+	for _, word := range strings.Fields(strings.ToUpper(extra)) {
+		switch word {
+		case "GENERATED":
+			column.generated = true
+		case "INVISIBLE":
+			column.invisible = true
+		}
+	}
+	return column
+}
+
+// dataColumns 返回导出数据的列名, 排除生成列和 omit 中的列, 以及 INSERT 是否需要指定列名
+// 不可见列需要在查询中指定, INSERT 不指定列名时值的个数与可见列不同, 也需要指定列名
+func dataColumns(columns []tableColumn, omit []string) ([]string, bool) {
+	names := make([]string, 0, len(columns))
+	invisible := false
+	for _, column := range columns {
+		if column.generated {
+			continue
+		}
+		invisible = invisible || column.invisible
+		names = append(names, column.name)
+	}
+	names = excludeColumns(names, omit)
+	return names, invisible || len(names) < len(columns)
+}
@@ -0,0 +1,59 @@
+package mysqldump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseColumnExtra(t *testing.T) {
+	tests := []struct {
+		extra string
+		want  tableColumn
+	}{
+		{extra: "", want: tableColumn{name: "c"}},
+		{extra: "auto_increment", want: tableColumn{name: "c"}},
+		{extra: "DEFAULT_GENERATED on update CURRENT_TIMESTAMP", want: tableColumn{name: "c"}},
+		{extra: "VIRTUAL GENERATED", want: tableColumn{name: "c", generated: true}},
+		{extra: "STORED GENERATED", want: tableColumn{name: "c", generated: true}},
+		{extra: "PERSISTENT GENERATED", want: tableColumn{name: "c", generated: true}},
+		{extra: "INVISIBLE", want: tableColumn{name: "c", invisible: true}},
+		{extra: "VIRTUAL GENERATED INVISIBLE", want: tableColumn{name: "c", generated: true, invisible: true}},
+	}
+	for _, tt := range tests {
+		if got := parseColumnExtra("c", tt.extra); got != tt.want {
+			t.Errorf("parseColumnExtra(%q) = %+v, want %+v", tt.extra, got, tt.want)
+		}
+	}
+}
+
+func Test_dataColumns(t *testing.T) {
+	columns := []tableColumn{{name: "id"}, {name: "name"}, {name: "total", generated: true}, {name: "secret"}}
+	invisible := []tableColumn{{name: "id"}, {name: "created", invisible: true}}
+	tests := []struct {
+		name        string
+		columns     []tableColumn
+		omit        []string
+		want        []string
+		wantPartial bool
+	}{
+		{name: "all", columns: columns[:2], want: []string{"id", "name"}},
+		{name: "generated", columns: columns, want: []string{"id", "name", "secret"}, wantPartial: true},
+		{name: "omit", columns: columns, omit: []string{"SECRET"}, want: []string{"id", "name"}, wantPartial: true},
+		{name: "invisible", columns: invisible, want: []string{"id", "created"}, wantPartial: true},
+		{name: "omit invisible", columns: invisible, omit: []string{"created"}, want: []string{"id"}, wantPartial: true},
+		{name: "empty", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, partial := dataColumns(tt.columns, tt.omit)
+			if len(got) != 0 || len(tt.want) != 0 {
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("dataColumns() = %v, want %v", got, tt.want)
+				}
+			}
+			if partial != tt.wantPartial {
+				t.Errorf("partial = %v, want %v", partial, tt.wantPartial)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package mysqldump
+
+// WithCompact 不输出注释, 每个表的说明注释和空行, 生成更小的文件, 便于程序处理
+// SET 等语句仍会输出, WithHeaderTemplate 和 WithFooterTemplate 指定的注释也会输出
+// 文件尾只有 "-- Dump completed on" 一行, 用于检查导出是否完整
+func WithCompact() DumpOption {
+	return func(option *dumpOption) {
+		option.compact = true
+	}
+}
+
+// banner 返回表等对象前的注释块, WithCompact 时返回空
+func (o *dumpOption) banner(title string) string {
+	if o.compact {
+		return ""
+	}
+	return "-- ----------------------------\n" +
+		"-- " + title + "\n" +
+		"-- ----------------------------\n"
+}
+
+// spacing 返回分隔语句的空行, WithCompact 时返回空
+func (o *dumpOption) spacing(s string) string {
+	if o.compact {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithCompact(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	meta := &DumpMeta{Database: "shop", ServerVersion: "8.0.36", StartTime: start, EndTime: start}
+	table := &TableMeta{Name: "t", CreateSQL: "CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB", Columns: []string{"id"}, DataTypes: []string{"INT"}}
+
+	tests := []struct {
+		name string
+		opts []DumpOption
+		want string
+	}{
+		{
+			name: "sql",
+			opts: []DumpOption{WithCompact(), WithDropTable()},
+			want: "DROP TABLE IF EXISTS `t`;\n" +
+				"CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB;\n" +
+				"INSERT INTO `t` VALUES (1);\n" +
+				"-- Dump completed on 2024-01-02 03:04:05\n",
+		},
+		{
+			name: "compat",
+			opts: []DumpOption{WithCompact(), WithMySQLDumpCompat()},
+			want: "/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+				"/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;\n" +
+				"/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;\n" +
+				"/*!50503 SET NAMES utf8mb4 */;\n" +
+				"/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;\n" +
+				"/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n" +
+				"/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;\n" +
+				"/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;\n" +
+				"DROP TABLE IF EXISTS `t`;\n" +
+				"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+				"/*!50503 SET character_set_client = utf8mb4 */;\n" +
+				"CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB;\n" +
+				"/*!40101 SET character_set_client = @saved_cs_client */;\n" +
+				"LOCK TABLES `t` WRITE;\n" +
+				"/*!40000 ALTER TABLE `t` DISABLE KEYS */;\n" +
+				"INSERT INTO `t` VALUES (1);\n" +
+				"/*!40000 ALTER TABLE `t` ENABLE KEYS */;\n" +
+				"UNLOCK TABLES;\n" +
+				"/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;\n" +
+				"/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;\n" +
+				"/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;\n" +
+				"/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;\n" +
+				"/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;\n" +
+				"/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;\n" +
+				"/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;\n" +
+				"-- Dump completed on 2024-01-02 03:04:05\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			var sb strings.Builder
+			steps := []func() error{
+				func() error { return o.formatter.Header(&sb, meta) },
+				func() error { return o.formatter.TableSchema(&sb, table) },
+				func() error { return o.formatter.TableDataBegin(&sb, table) },
+				func() error { return o.formatter.Row(&sb, table, []interface{}{int64(1)}) },
+				func() error { return o.formatter.TableDataEnd(&sb, table) },
+				func() error { return o.formatter.Footer(&sb, meta) },
+			}
+			for _, step := range steps {
+				if err := step(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if sb.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", sb.String(), tt.want)
+			}
+		})
+	}
+}
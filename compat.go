@@ -0,0 +1,157 @@
+package mysqldump
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// WithMySQLDumpCompat 输出格式与官方 mysqldump 相同, 已有的解析和对比工具可以继续使用
+// 相当于 mysqldump --add-drop-table --add-locks --disable-keys --skip-extended-insert --skip-tz-utc,
+// 包括文件头, /*!NNNNN */ 条件注释, SET 语句块和 "-- Dump completed on" 文件尾
+func WithMySQLDumpCompat() DumpOption {
+	return func(option *dumpOption) {
+		option.mysqldumpCompat = true
+		option.isDropTable = true
+		option.isAddLocks = true
+		option.isDisableKeys = true
+		option.noIfNotExists = true
+	}
+}
+
+// compatFormatter 官方 mysqldump 格式, INSERT 与默认格式相同
+type compatFormatter struct {
+	sqlFormatter
+}
+
+func (f *compatFormatter) Header(w io.Writer, meta *DumpMeta) error {
+	var sb strings.Builder
+	if f.o.customHeader {
+		err := f.o.writeHeaderComment(&sb, meta, "")
+		if err != nil {
+			return err
+		}
+	} else if !f.o.compact {
+		sb.WriteString(fmt.Sprintf("-- MySQL dump 10.13  Distrib %s, for %s (%s)\n", meta.ServerVersion, runtime.GOOS, runtime.GOARCH))
+		sb.WriteString("--\n")
+		sb.WriteString(fmt.Sprintf("-- Host: %s    Database: %s\n", meta.Host, meta.Database))
+		sb.WriteString("-- ------------------------------------------------------\n")
+		sb.WriteString(fmt.Sprintf("-- Server version\t%s\n", meta.ServerVersion))
+		if len(meta.ServerVariables) > 0 {
+			sb.WriteString("--\n" + serverVariablesComment(meta.ServerVariables))
+		}
+	}
+	charset := ParseServerVersion(meta.ServerVersion).charset()
+	setNames := "/*!50503 SET NAMES utf8mb4 */;\n"
+	if charset != "utf8mb4" {
+		setNames = "/*!40101 SET NAMES " + charset + " */;\n"
+	}
+	sb.WriteString(f.o.spacing("\n") +
+		"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+		"/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;\n" +
+		"/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;\n" +
+		setNames +
+		"/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;\n" +
+		"/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n" +
+		"/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;\n" +
+		"/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;\n")
+	if f.o.timeZone != nil {
+		sb.WriteString("/*!40103 SET @OLD_TIME_ZONE=@@TIME_ZONE */;\n" +
+			"/*!40103 SET TIME_ZONE='" + timeZoneName(f.o.timeZone) + "' */;\n")
+	}
+	if f.o.isDropDatabase {
+		database := QuoteIdentifier(meta.Database)
+		sb.WriteString(f.banner("Current Database: " + commentName(database)))
+		sb.WriteString("/*!40000 DROP DATABASE IF EXISTS " + database + "*/;\n" + f.o.spacing("\n"))
+		sb.WriteString(f.o.createDatabaseSQL + ";\n" + f.o.spacing("\n"))
+		if !f.o.qualifiedNames {
+			sb.WriteString("USE " + database + ";\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// banner 官方 mysqldump 格式的注释块, WithCompact 时返回空
+func (f *compatFormatter) banner(title string) string {
+	if f.o.compact {
+		return ""
+	}
+	return "\n--\n-- " + title + "\n--\n\n"
+}
+
+func (f *compatFormatter) TableSchema(w io.Writer, table *TableMeta) error {
+	title, name := commentName(QuoteIdentifier(table.Name)), f.o.objectName(table.Name)
+	createSQL := f.o.qualifyCreateSQL(table.CreateSQL)
+	var sb strings.Builder
+	if table.Sequence {
+		// 与 mariadb-dump 相同
+		sb.WriteString(f.banner("Sequence structure for " + title))
+		sb.WriteString("DROP SEQUENCE IF EXISTS " + name + ";\n")
+		sb.WriteString(createSQL + ";\n")
+		if table.SequenceValue != "" {
+			sb.WriteString(sequenceSetval(name, table.SequenceValue))
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+	if table.View {
+		sb.WriteString(f.banner("Final view structure for view " + title))
+		sb.WriteString("/*!50001 DROP VIEW IF EXISTS " + name + "*/;\n")
+		sb.WriteString(createSQL + ";\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+	sb.WriteString(f.banner("Table structure for table " + title))
+	sb.WriteString("DROP TABLE IF EXISTS " + name + ";\n")
+	sb.WriteString("/*!40101 SET @saved_cs_client     = @@character_set_client */;\n")
+	sb.WriteString("/*!50503 SET character_set_client = utf8mb4 */;\n")
+	sb.WriteString(createSQL + ";\n")
+	sb.WriteString("/*!40101 SET character_set_client = @saved_cs_client */;\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (f *compatFormatter) TableDataBegin(w io.Writer, table *TableMeta) error {
+	name := f.o.objectName(table.Name)
+	var sb strings.Builder
+	sb.WriteString(f.banner("Dumping data for table " + commentName(QuoteIdentifier(table.Name))))
+	sb.WriteString("LOCK TABLES " + name + " WRITE;\n")
+	if f.o.isTruncateTable {
+		sb.WriteString("TRUNCATE TABLE " + name + ";\n")
+	}
+	sb.WriteString("/*!40000 ALTER TABLE " + name + " DISABLE KEYS */;\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (f *compatFormatter) TableDataEnd(w io.Writer, table *TableMeta) error {
+	name := f.o.objectName(table.Name)
+	_, err := io.WriteString(w, "/*!40000 ALTER TABLE "+name+" ENABLE KEYS */;\n"+
+		"UNLOCK TABLES;\n")
+	return err
+}
+
+func (f *compatFormatter) Footer(w io.Writer, meta *DumpMeta) error {
+	var restoreTimeZone string
+	if f.o.timeZone != nil {
+		restoreTimeZone = "/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n"
+	}
+	_, err := io.WriteString(w, f.o.spacing("\n")+restoreTimeZone+
+		"/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;\n"+
+		"/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;\n"+
+		"/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;\n"+
+		"/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;\n"+
+		"/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;\n"+
+		"/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;\n"+
+		"/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;\n"+
+		f.o.spacing("\n"))
+	if err != nil {
+		return err
+	}
+	if f.o.customFooter || f.o.compact {
+		return f.o.writeFooterComment(w, meta)
+	}
+	return f.o.writeDumpCompleted(w, meta)
+}
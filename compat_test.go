@@ -0,0 +1,106 @@
+package mysqldump
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_compatFormatter(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithMySQLDumpCompat()})
+	end := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	meta := &DumpMeta{Database: "shop", Host: "db1", ServerVersion: "8.0.36", EndTime: end}
+	table := &TableMeta{Name: "t", CreateSQL: "CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB", Columns: []string{"id"}, DataTypes: []string{"INT"}}
+	view := &TableMeta{Name: "v", CreateSQL: "CREATE OR REPLACE VIEW `v` AS select 1", View: true}
+
+	var sb strings.Builder
+	steps := []func() error{
+		func() error { return o.formatter.Header(&sb, meta) },
+		func() error { return o.formatter.TableSchema(&sb, table) },
+		func() error { return o.formatter.TableDataBegin(&sb, table) },
+		func() error { return o.formatter.Row(&sb, table, []interface{}{int64(1)}) },
+		func() error { return o.formatter.TableDataEnd(&sb, table) },
+		func() error { return o.formatter.TableSchema(&sb, view) },
+		func() error { return o.formatter.Footer(&sb, meta) },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "-- MySQL dump 10.13  Distrib 8.0.36, for " + runtime.GOOS + " (" + runtime.GOARCH + ")\n" +
+		"--\n" +
+		"-- Host: db1    Database: shop\n" +
+		"-- ------------------------------------------------------\n" +
+		"-- Server version\t8.0.36\n" +
+		"\n" +
+		"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+		"/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;\n" +
+		"/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;\n" +
+		"/*!50503 SET NAMES utf8mb4 */;\n" +
+		"/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;\n" +
+		"/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n" +
+		"/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;\n" +
+		"/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;\n" +
+		"\n" +
+		"--\n" +
+		"-- Table structure for table `t`\n" +
+		"--\n" +
+		"\n" +
+		"DROP TABLE IF EXISTS `t`;\n" +
+		"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+		"/*!50503 SET character_set_client = utf8mb4 */;\n" +
+		"CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB;\n" +
+		"/*!40101 SET character_set_client = @saved_cs_client */;\n" +
+		"\n" +
+		"--\n" +
+		"-- Dumping data for table `t`\n" +
+		"--\n" +
+		"\n" +
+		"LOCK TABLES `t` WRITE;\n" +
+		"/*!40000 ALTER TABLE `t` DISABLE KEYS */;\n" +
+		"INSERT INTO `t` VALUES (1);\n" +
+		"/*!40000 ALTER TABLE `t` ENABLE KEYS */;\n" +
+		"UNLOCK TABLES;\n" +
+		"\n" +
+		"--\n" +
+		"-- Final view structure for view `v`\n" +
+		"--\n" +
+		"\n" +
+		"/*!50001 DROP VIEW IF EXISTS `v`*/;\n" +
+		"CREATE OR REPLACE VIEW `v` AS select 1;\n" +
+		"\n" +
+		"/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;\n" +
+		"/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;\n" +
+		"/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;\n" +
+		"/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;\n" +
+		"/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;\n" +
+		"/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;\n" +
+		"/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;\n" +
+		"\n" +
+		"-- Dump completed on 2024-01-02 03:04:05\n"
+	if sb.String() != want {
+		t.Errorf("output = %q, want %q", sb.String(), want)
+	}
+	if !o.noIfNotExists {
+		t.Error("compat mode should not add IF NOT EXISTS")
+	}
+}
+
+func Test_getHostFromDSN(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"root:pass@tcp(10.0.0.1:3306)/db?charset=utf8mb4", "10.0.0.1"},
+		{"root@unix(/tmp/mysql.sock)/db", "localhost"},
+		{"bad dsn", ""},
+	}
+	for _, tt := range tests {
+		if got := getHostFromDSN(tt.dsn); got != tt.want {
+			t.Errorf("getHostFromDSN(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
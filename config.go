@@ -0,0 +1,209 @@
+package mysqldump
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DumpConfig 导出配置, 以 JSON 文件保存, 字符串中的 ${ENV} 会替换为环境变量
+// 只支持 JSON, 不引入第三方依赖, YAML 或 TOML 的配置需要先转换为 JSON
+type DumpConfig struct {
+	DSN           string     `json:"dsn"`
+	Data          bool       `json:"data"`
+	Tables        []string   `json:"tables"`
+	IgnoreTables  []string   `json:"ignore_tables"`
+	NoDataTables  []string   `json:"no_data_tables"`
+	IgnoreEngines []string   `json:"ignore_engines"`
+	MaxTableSize  int64      `json:"max_table_size"`
+	DropTable     bool       `json:"drop_table"`
+	InsertIgnore  bool       `json:"insert_ignore"`
+	Concurrency   int        `json:"concurrency"`
+	ChunkSize     int        `json:"chunk_size"`
+	Checkpoint    string     `json:"checkpoint"`
+	MaskRules     []MaskRule `json:"mask_rules"`
+	// 单独设置的表, key 为表名
+	TableOptions map[string]TableConfig `json:"table_options"`
+	// 输出文件, {time} 替换为开始时间, 如 backup/db-{time}.sql.gz, 为空时输出到标准输出
+	Output string `json:"output"`
+	Gzip   bool   `json:"gzip"`
+	// cron 表达式, 用于 ScheduleFromConfig
+	Schedule string `json:"schedule"`
+}
+
+// TableConfig 一个表的导出配置, 未设置的项使用全局配置
+type TableConfig struct {
+	Where        string `json:"where"`
+	ChunkSize    *int   `json:"chunk_size"`
+	InsertIgnore *bool  `json:"insert_ignore"`
+	NoData       bool   `json:"no_data"`
+}
+
+// options 返回表配置对应的选项
+func (c TableConfig) options() []TableOption {
+	var opts []TableOption
+	if c.Where != "" {
+		opts = append(opts, TableWhere(c.Where))
+	}
+	if c.ChunkSize != nil {
+		opts = append(opts, TableChunkSize(*c.ChunkSize))
+	}
+	if c.InsertIgnore != nil {
+		opts = append(opts, TableInsertIgnore(*c.InsertIgnore))
+	}
+	if c.NoData {
+		opts = append(opts, TableNoData())
+	}
+	return opts
+}
+
+// LoadDumpConfig 读取 JSON 导出配置, .yaml, .yml 和 .toml 文件返回错误
+func LoadDumpConfig(path string) (*DumpConfig, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml":
+		return nil, fmt.Errorf("config %s: only JSON is supported", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config DumpConfig
+	err = json.Unmarshal([]byte(os.ExpandEnv(string(data))), &config)
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %v", path, err)
+	}
+	if config.DSN == "" {
+		return nil, fmt.Errorf("config %s: dsn is required", path)
+	}
+	for _, rule := range config.MaskRules {
+		err = rule.validate()
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %v", path, err)
+		}
+	}
+	// 断点续传需要截断和追加同一个文件, 压缩后的输出和每次不同的文件名都无法续传
+	if config.Checkpoint != "" && config.Gzip {
+		return nil, fmt.Errorf("config %s: gzip can not be used with checkpoint", path)
+	}
+	if config.Checkpoint != "" && strings.Contains(config.Output, "{time}") {
+		return nil, fmt.Errorf("config %s: {time} in output can not be used with checkpoint", path)
+	}
+	if config.Schedule != "" {
+		_, err = parseCron(config.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %v", path, err)
+		}
+	}
+	return &config, nil
+}
+
+// Options 返回配置对应的导出选项, 不包含输出
+func (c *DumpConfig) Options() []DumpOption {
+	opts := []DumpOption{
+		WithMaxTableSize(c.MaxTableSize),
+		WithConcurrency(c.Concurrency),
+		WithChunkSize(c.ChunkSize),
+		WithMaskRules(c.MaskRules...),
+	}
+	if c.Data {
+		opts = append(opts, WithData())
+	}
+	if len(c.Tables) > 0 {
+		opts = append(opts, WithTables(c.Tables...))
+	}
+	if len(c.IgnoreTables) > 0 {
+		opts = append(opts, WithIgnoreTables(c.IgnoreTables...))
+	}
+	if len(c.NoDataTables) > 0 {
+		opts = append(opts, WithNoDataTables(c.NoDataTables...))
+	}
+	if len(c.IgnoreEngines) > 0 {
+		opts = append(opts, WithIgnoreEngines(c.IgnoreEngines...))
+	}
+	if c.DropTable {
+		opts = append(opts, WithDropTable())
+	}
+	if c.InsertIgnore {
+		opts = append(opts, WithIgnoreInsertTable())
+	}
+	if c.Checkpoint != "" {
+		opts = append(opts, WithCheckpoint(c.Checkpoint))
+	}
+	for table, tc := range c.TableOptions {
+		opts = append(opts, WithTableOptions(table, tc.options()...))
+	}
+	return opts
+}
+
+// Run 按配置导出一次, opts 追加在配置的选项之后
+func (c *DumpConfig) Run(opts ...DumpOption) error {
+	return c.RunContext(context.Background(), opts...)
+}
+
+// RunContext 同 Run, ctx 结束后不再导出之后的表
+// 输出文件和 gzip 在导出后关闭, 关闭失败时返回错误, 如 gzip 的结尾没有写出
+func (c *DumpConfig) RunContext(ctx context.Context, opts ...DumpOption) error {
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if c.Output != "" {
+		name := strings.ReplaceAll(c.Output, "{time}", time.Now().Format("20060102-150405"))
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if c.Checkpoint != "" {
+			flags = os.O_CREATE | os.O_WRONLY
+		}
+		var err error
+		f, err = os.OpenFile(name, flags, 0644)
+		if err != nil {
+			return err
+		}
+		w = f
+	}
+	var zw *gzip.Writer
+	if c.Gzip {
+		zw = gzip.NewWriter(w)
+		w = zw
+	}
+
+	all := append(c.Options(), WithWriter(w))
+	err := runDump(ctx, c.DSN, newDumpOption(append(all, opts...)))
+	if zw != nil {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if f != nil {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// DumpFromConfig 读取 JSON 配置文件并导出一次
+func DumpFromConfig(path string, opts ...DumpOption) error {
+	config, err := LoadDumpConfig(path)
+	if err != nil {
+		return err
+	}
+	return config.Run(opts...)
+}
+
+// ScheduleFromConfig 读取 JSON 配置文件, 按其中的 schedule 创建定时导出任务
+func ScheduleFromConfig(path string, opts ...SchedulerOption) (*Scheduler, error) {
+	config, err := LoadDumpConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if config.Schedule == "" {
+		return nil, fmt.Errorf("config %s: schedule is required", path)
+	}
+	return NewScheduler(config.Schedule, func(ctx context.Context) error {
+		return config.RunContext(ctx)
+	}, opts...)
+}
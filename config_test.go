@@ -0,0 +1,115 @@
+package mysqldump
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDumpConfig(t *testing.T) {
+	t.Setenv("TEST_DB_PASSWORD", "secret")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prod.json")
+	err := os.WriteFile(path, []byte(`{
+		"dsn": "root:${TEST_DB_PASSWORD}@tcp(localhost:3306)/db?parseTime=true",
+		"data": true,
+		"ignore_tables": ["logs"],
+		"table_options": {"orders": {"where": "status = 1", "chunk_size": 0, "insert_ignore": true}},
+		"mask_rules": [{"column": "*_email", "mask": "email"}],
+		"output": "backup/db-{time}.sql.gz",
+		"gzip": true,
+		"schedule": "0 3 * * *"
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadDumpConfig(path)
+	if err != nil {
+		t.Fatalf("LoadDumpConfig() error = %v", err)
+	}
+	if config.DSN != "root:secret@tcp(localhost:3306)/db?parseTime=true" {
+		t.Errorf("DSN = %s", config.DSN)
+	}
+
+	var o dumpOption
+	for _, opt := range config.Options() {
+		opt(&o)
+	}
+	if !o.isData || len(o.ignoreTables) != 1 || len(o.maskRules) != 1 {
+		t.Errorf("Options() data = %v, ignore tables = %v, mask rules = %v", o.isData, o.ignoreTables, o.maskRules)
+	}
+	if to := o.tableOptions["orders"]; to == nil || to.where != "status = 1" || !to.chunkSet || to.chunkSize != 0 || !to.ignoreInsert || to.noData {
+		t.Errorf("Options() table options = %+v", to)
+	}
+
+	err = os.WriteFile(path, []byte(`{"dsn": "x", "schedule": "bad"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadDumpConfig(path); err == nil {
+		t.Errorf("LoadDumpConfig() want error for bad schedule")
+	}
+}
+
+func TestLoadDumpConfig_invalid(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "checkpoint.json", data: `{"dsn": "x", "checkpoint": "c.json", "output": "db.sql.gz", "gzip": true}`},
+		{name: "time.json", data: `{"dsn": "x", "checkpoint": "c.json", "output": "db-{time}.sql"}`},
+		{name: "prod.yaml", data: `dsn: x`},
+		{name: "prod.toml", data: `dsn = "x"`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadDumpConfig(path); err == nil {
+			t.Errorf("LoadDumpConfig(%s) want error", tt.name)
+		}
+	}
+}
+
+func TestDumpConfig_RunContext(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	pool := func(o *dumpOption) { o.sharedPool = db }
+	output := filepath.Join(t.TempDir(), "db.sql.gz")
+	config := &DumpConfig{DSN: "root@tcp(127.0.0.1:1)/test", Data: true, Output: output, Gzip: true}
+
+	// gzip 关闭后输出完整
+	if err := config.RunContext(context.Background(), pool); err != nil {
+		t.Fatalf("RunContext() error = %v", err)
+	}
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("read gzip output: %v", err)
+	}
+	if !strings.Contains(string(data), "INSERT INTO `b`") {
+		t.Errorf("output = %s, want table b", data)
+	}
+
+	// 取消后不再导出
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := config.RunContext(ctx, pool); !errors.Is(err, context.Canceled) {
+		t.Errorf("RunContext() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,444 @@
+package mysqldump
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tableDef SHOW CREATE TABLE 解析后的表定义
+type tableDef struct {
+	name       string
+	columns    []columnDef
+	primaryKey []string
+	// 主键的完整定义, 如 PRIMARY KEY (`id`)
+	primaryKeyDef string
+	indexes       []indexDef
+	// 外键, table 为当前表
+	foreignKeys []foreignKey
+	// 右括号后的表选项, 如 ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	options string
+	comment string
+}
+
+// columnDef 列定义
+type columnDef struct {
+	name string
+	// 小写的类型名, 如 int, varchar
+	typ string
+	// 类型括号中的内容, 如 255, 10,2, 'a','b'
+	args     string
+	unsigned bool
+	notNull  bool
+	autoInc  bool
+	// 默认值的原始 SQL, 如 'abc', 0, CURRENT_TIMESTAMP, 为空表示没有默认值
+	defaultValue string
+	onUpdate     string
+	comment      string
+	// 生成列表达式
+	generated string
+	stored    bool
+	// 列名之后的完整定义
+	definition string
+}
+
+// indexDef 索引, kind 为 UNIQUE, FULLTEXT, SPATIAL 或空
+type indexDef struct {
+	name    string
+	kind    string
+	columns []string
+	// 完整定义, 包括前缀长度和索引选项
+	definition string
+}
+
+// parseCreateTable 解析 SHOW CREATE TABLE 的结果
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func parseCreateTable(createSQL string) (*tableDef, error) {
+	tokens := meaningfulTokens(tokenizeSQL(createSQL))
+
+	// CREATE [TEMPORARY] TABLE [IF NOT EXISTS] name (
+	i := 0
+	for i < len(tokens) && !tokens[i].isKeyword("TABLE") {
+		i++
+	}
+	i++
+	if i+2 < len(tokens) && tokens[i].isKeyword("IF") {
+		i += 3
+	}
+	if i >= len(tokens) {
+		return nil, fmt.Errorf("invalid CREATE TABLE: %s", createSQL)
+	}
+	def := &tableDef{}
+	def.name, _ = tokens[i].identName()
+	i++
+	// db.table
+	if i+1 < len(tokens) && tokens[i].text == "." {
+		def.name, _ = tokens[i+1].identName()
+		i += 2
+	}
+	if i >= len(tokens) || tokens[i].text != "(" {
+		return nil, fmt.Errorf("invalid CREATE TABLE: %s", createSQL)
+	}
+
+	// 按顶层逗号切分定义
+	var defs [][]sqlToken
+	var cur []sqlToken
+	depth := 0
+	i++
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.text == "(" {
+			depth++
+		} else if t.text == ")" {
+			if depth == 0 {
+				break
+			}
+			depth--
+		} else if t.text == "," && depth == 0 {
+			defs = append(defs, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, t)
+	}
+	if i >= len(tokens) {
+		return nil, fmt.Errorf("invalid CREATE TABLE: %s", createSQL)
+	}
+	if len(cur) > 0 {
+		defs = append(defs, cur)
+	}
+	def.options = joinTokens(tokens[i+1:])
+	for k := i + 1; k+2 < len(tokens); k++ {
+		if tokens[k].isKeyword("COMMENT") && tokens[k+1].text == "=" && tokens[k+2].kind == tokenString {
+			def.comment = unquoteString(tokens[k+2].text)
+		}
+	}
+
+	for _, d := range defs {
+		if len(d) == 0 {
+			continue
+		}
+		first := d[0]
+		switch {
+		case first.kind == tokenIdent || (first.kind == tokenWord && !first.isKeyword("PRIMARY", "UNIQUE", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "CONSTRAINT", "CHECK", "FOREIGN")):
+			def.columns = append(def.columns, parseColumnDef(d))
+		case first.isKeyword("PRIMARY"):
+			def.primaryKey = keyColumns(d)
+			def.primaryKeyDef = joinTokens(d)
+		case first.isKeyword("UNIQUE", "KEY", "INDEX", "FULLTEXT", "SPATIAL"):
+			idx := indexDef{columns: keyColumns(d), definition: joinTokens(d)}
+			k := 0
+			if first.isKeyword("UNIQUE", "FULLTEXT", "SPATIAL") {
+				idx.kind = strings.ToUpper(first.text)
+				k++
+			}
+			if k < len(d) && d[k].isKeyword("KEY", "INDEX") {
+				k++
+			}
+			if k < len(d) && d[k].text != "(" {
+				idx.name, _ = d[k].identName()
+			}
+			def.indexes = append(def.indexes, idx)
+		case first.isKeyword("CONSTRAINT", "FOREIGN"):
+			if fk, ok := parseForeignKeyDef(d); ok {
+				fk.table = def.name
+				fk.definition = joinTokens(d)
+				def.foreignKeys = append(def.foreignKeys, fk)
+			}
+		}
+	}
+	return def, nil
+}
+
+// parseColumnDef 解析列定义
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func parseColumnDef(d []sqlToken) columnDef {
+	col := columnDef{}
+	col.name, _ = d[0].identName()
+	col.definition = joinTokens(d[1:])
+	if len(d) < 2 {
+		return col
+	}
+	col.typ = strings.ToLower(d[1].text)
+	k := 2
+	if k < len(d) && d[k].text == "(" {
+		end := matchParen(d, k)
+		col.args = joinTokens(d[k+1 : end])
+		k = end + 1
+	}
+	for ; k < len(d); k++ {
+		t := d[k]
+		switch {
+		case t.isKeyword("UNSIGNED"):
+			col.unsigned = true
+		case t.isKeyword("NOT") && k+1 < len(d) && d[k+1].isKeyword("NULL"):
+			col.notNull = true
+			k++
+		case t.isKeyword("AUTO_INCREMENT"):
+			col.autoInc = true
+		case t.isKeyword("DEFAULT") && k+1 < len(d):
+			k++
+			if d[k].text == "(" {
+				end := matchParen(d, k)
+				col.defaultValue = joinTokens(d[k : end+1])
+				k = end
+			} else {
+				col.defaultValue = d[k].text
+				// CURRENT_TIMESTAMP(3), b'0'
+				if k+1 < len(d) && d[k+1].text == "(" {
+					end := matchParen(d, k+1)
+					col.defaultValue += joinTokens(d[k+1 : end+1])
+					k = end
+				} else if (d[k].text == "-" || d[k].kind == tokenWord) && k+1 < len(d) && (d[k+1].kind == tokenString || d[k].text == "-") {
+					col.defaultValue += d[k+1].text
+					k++
+				}
+			}
+		case t.isKeyword("ON") && k+2 < len(d) && d[k+1].isKeyword("UPDATE"):
+			col.onUpdate = d[k+2].text
+			k += 2
+			if k+1 < len(d) && d[k+1].text == "(" {
+				end := matchParen(d, k+1)
+				col.onUpdate += joinTokens(d[k+1 : end+1])
+				k = end
+			}
+		case t.isKeyword("COMMENT") && k+1 < len(d):
+			col.comment = unquoteString(d[k+1].text)
+			k++
+		case t.isKeyword("AS") && k+1 < len(d) && d[k+1].text == "(":
+			end := matchParen(d, k+1)
+			col.generated = joinTokens(d[k+2 : end])
+			k = end
+		case t.isKeyword("STORED"):
+			col.stored = true
+		}
+	}
+	return col
+}
+
+// parseForeignKeyDef 解析 [CONSTRAINT name] FOREIGN KEY (cols) REFERENCES table (cols) [ON DELETE ...] [ON UPDATE ...]
+func parseForeignKeyDef(d []sqlToken) (foreignKey, bool) {
+	fk := foreignKey{}
+	k := 0
+	if d[k].isKeyword("CONSTRAINT") {
+		k++
+		if k < len(d) && !d[k].isKeyword("FOREIGN", "CHECK") {
+			fk.name, _ = d[k].identName()
+			k++
+		}
+	}
+	if k+1 >= len(d) || !d[k].isKeyword("FOREIGN") {
+		return fk, false
+	}
+	ref := k
+	for ref < len(d) && !d[ref].isKeyword("REFERENCES") {
+		ref++
+	}
+	if ref+1 >= len(d) {
+		return fk, false
+	}
+	fk.columns = keyColumns(d[k:ref])
+	fk.refTable, _ = d[ref+1].identName()
+	fk.refColumns = keyColumns(d[ref+1:])
+	for j := ref + 1; j+2 < len(d); j++ {
+		if d[j].isKeyword("ON") && d[j+1].isKeyword("DELETE", "UPDATE") {
+			action := d[j+2].text
+			if j+3 < len(d) && (d[j+2].isKeyword("SET", "NO")) {
+				action += " " + d[j+3].text
+			}
+			if d[j+1].isKeyword("DELETE") {
+				fk.onDelete = strings.ToUpper(action)
+			} else {
+				fk.onUpdate = strings.ToUpper(action)
+			}
+		}
+	}
+	return fk, true
+}
+
+// keyColumns 返回第一个括号中的列名, 忽略前缀长度和函数索引表达式
+func keyColumns(d []sqlToken) []string {
+	start := 0
+	for start < len(d) && d[start].text != "(" {
+		start++
+	}
+	if start >= len(d) {
+		return nil
+	}
+	end := matchParen(d, start)
+	var columns []string
+	depth := 0
+	for _, t := range d[start+1 : end] {
+		switch {
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			depth--
+		case depth == 0 && t.kind == tokenIdent:
+			name, _ := t.identName()
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// matchParen 返回与 d[start] 的左括号匹配的右括号下标, 没有时返回最后一个下标
+func matchParen(d []sqlToken, start int) int {
+	depth := 0
+	for k := start; k < len(d); k++ {
+		switch d[k].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return k
+			}
+		}
+	}
+	return len(d) - 1
+}
+
+// meaningfulTokens 去掉空白和注释
+func meaningfulTokens(tokens []sqlToken) []sqlToken {
+	var result []sqlToken
+	for _, t := range tokens {
+		if t.kind != tokenSpace && t.kind != tokenComment {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// joinTokens 以空格连接 token, 括号和逗号两侧不加空格
+func joinTokens(tokens []sqlToken) string {
+	var sb strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.text != ")" && t.text != "," && t.text != "(" && tokens[i-1].text != "(" && tokens[i-1].text != "," {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+// unquoteString 去掉字符串的引号并还原转义
+func unquoteString(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	q := s[0]
+	if q != '\'' && q != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	s = strings.ReplaceAll(s, string(q)+string(q), string(q))
+	return unescapeString(s)
+}
+
+// unescapeString 还原 MySQL 的反斜杠转义
+func unescapeString(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '0':
+			sb.WriteByte(0)
+		case 'Z':
+			sb.WriteByte(0x1a)
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// WithoutAutoIncrementValue 去掉 CREATE TABLE 中的 AUTO_INCREMENT=N 表选项
+// 只导出表结构用于对比或初始化环境时, 不会因数据变化而不同
+func WithoutAutoIncrementValue() DumpOption {
+	return func(option *dumpOption) {
+		option.noAutoIncrementValue = true
+	}
+}
+
+// stripAutoIncrement 去掉表选项中的 AUTO_INCREMENT=N, 列定义中的 AUTO_INCREMENT 不变
+func stripAutoIncrement(createSQL string) string {
+	tokens := tokenizeSQL(createSQL)
+	var sb strings.Builder
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth == 0 && t.isKeyword("AUTO_INCREMENT") {
+			j := skipSpace(tokens, i+1)
+			if j < len(tokens) && tokens[j].text == "=" {
+				j = skipSpace(tokens, j+1)
+				if j < len(tokens) && tokens[j].kind == tokenWord {
+					// 同时去掉前面的空白
+					s := strings.TrimRight(sb.String(), " \t\r\n")
+					sb.Reset()
+					sb.WriteString(s)
+					i = j
+					continue
+				}
+			}
+		}
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+// WithoutIfNotExists CREATE TABLE 不加 IF NOT EXISTS, 表已存在时恢复报错而不是跳过
+func WithoutIfNotExists() DumpOption {
+	return func(option *dumpOption) {
+		option.noIfNotExists = true
+	}
+}
+
+// addIfNotExists 在 CREATE [TEMPORARY] TABLE 后加上 IF NOT EXISTS, 已有时不变
+// 只处理语句开头的关键字, 注释, 字符串和列定义中的内容不变
+func addIfNotExists(createSQL string) string {
+	tokens := tokenizeSQL(createSQL)
+	i := skipSpace(tokens, 0)
+	if i >= len(tokens) || !tokens[i].isKeyword("CREATE") {
+		return createSQL
+	}
+	i = skipSpace(tokens, i+1)
+	if i < len(tokens) && tokens[i].isKeyword("TEMPORARY") {
+		i = skipSpace(tokens, i+1)
+	}
+	if i >= len(tokens) || !tokens[i].isKeyword("TABLE") {
+		return createSQL
+	}
+	if j := skipSpace(tokens, i+1); j < len(tokens) && tokens[j].isKeyword("IF") {
+		return createSQL
+	}
+	var sb strings.Builder
+	for _, t := range tokens[:i+1] {
+		sb.WriteString(t.text)
+	}
+	sb.WriteString(" IF NOT EXISTS")
+	for _, t := range tokens[i+1:] {
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package mysqldump
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testCreateTable = "CREATE TABLE `orders` (\n" +
+	"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `user_id` int NOT NULL,\n" +
+	"  `status` enum('new','paid') COLLATE utf8mb4_bin NOT NULL DEFAULT 'new' COMMENT 'order''s status',\n" +
+	"  `amount` decimal(10,2) DEFAULT '0.00',\n" +
+	"  `balance` int DEFAULT -1,\n" +
+	"  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),\n" +
+	"  `total` decimal(12,2) GENERATED ALWAYS AS ((`amount` * 2)) STORED,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `uk_user` (`user_id`,`status`),\n" +
+	"  KEY `idx_name` (`status`(10)),\n" +
+	"  FULLTEXT KEY `ft` (`status`),\n" +
+	"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE ON UPDATE SET NULL\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=utf8mb4 COMMENT='orders'"
+
+func Test_parseCreateTable(t *testing.T) {
+	def, err := parseCreateTable(testCreateTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.name != "orders" || def.comment != "orders" {
+		t.Errorf("name = %q, comment = %q", def.name, def.comment)
+	}
+	if !reflect.DeepEqual(def.primaryKey, []string{"id"}) {
+		t.Errorf("primaryKey = %v", def.primaryKey)
+	}
+	if len(def.columns) != 7 {
+		t.Fatalf("columns = %d, want 7", len(def.columns))
+	}
+
+	tests := []struct {
+		got  interface{}
+		want interface{}
+	}{
+		{def.columns[0].typ, "bigint"},
+		{def.columns[0].unsigned && def.columns[0].notNull && def.columns[0].autoInc, true},
+		{def.columns[2].args, "'new','paid'"},
+		{def.columns[2].defaultValue, "'new'"},
+		{def.columns[2].comment, "order's status"},
+		{def.columns[3].args, "10,2"},
+		{def.columns[3].defaultValue, "'0.00'"},
+		{def.columns[4].defaultValue, "-1"},
+		{def.columns[5].defaultValue, "CURRENT_TIMESTAMP(3)"},
+		{def.columns[5].onUpdate, "CURRENT_TIMESTAMP(3)"},
+		{def.columns[6].generated, "(`amount` * 2)"},
+		{def.columns[6].stored, true},
+		{len(def.indexes), 3},
+		{def.primaryKeyDef, "PRIMARY KEY(`id`)"},
+		{def.indexes[0], indexDef{name: "uk_user", kind: "UNIQUE", columns: []string{"user_id", "status"}, definition: "UNIQUE KEY `uk_user`(`user_id`,`status`)"}},
+		{def.indexes[1], indexDef{name: "idx_name", columns: []string{"status"}, definition: "KEY `idx_name`(`status`(10))"}},
+		{def.indexes[2].kind, "FULLTEXT"},
+		{def.foreignKeys, []foreignKey{{name: "fk_user", table: "orders", columns: []string{"user_id"}, refTable: "users", refColumns: []string{"id"}, onDelete: "CASCADE", onUpdate: "SET NULL",
+			definition: "CONSTRAINT `fk_user` FOREIGN KEY(`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE SET NULL"}}},
+	}
+	for i, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%d: got %#v, want %#v", i, tt.got, tt.want)
+		}
+	}
+}
+
+func Test_stripAutoIncrement(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "CREATE TABLE `t` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4",
+			want: "CREATE TABLE `t` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		{
+			in:   "CREATE TABLE `t` (`a` int) ENGINE=InnoDB COMMENT='AUTO_INCREMENT=1'",
+			want: "CREATE TABLE `t` (`a` int) ENGINE=InnoDB COMMENT='AUTO_INCREMENT=1'",
+		},
+		{
+			in:   "CREATE TABLE `t` (`a` int) AUTO_INCREMENT = 7",
+			want: "CREATE TABLE `t` (`a` int)",
+		},
+	}
+	for _, tt := range tests {
+		if got := stripAutoIncrement(tt.in); got != tt.want {
+			t.Errorf("stripAutoIncrement(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if def, err := parseCreateTable(stripAutoIncrement(testCreateTable)); err != nil || len(def.columns) != 7 || !def.columns[0].autoInc {
+		t.Errorf("parse stripped table: %v", err)
+	}
+}
+
+func Test_addIfNotExists(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"CREATE TABLE `t` (`a` int)", "CREATE TABLE IF NOT EXISTS `t` (`a` int)"},
+		{"CREATE TABLE IF NOT EXISTS `t` (`a` int)", "CREATE TABLE IF NOT EXISTS `t` (`a` int)"},
+		{"CREATE TEMPORARY TABLE `t` (`a` int)", "CREATE TEMPORARY TABLE IF NOT EXISTS `t` (`a` int)"},
+		{"CREATE TABLE `CREATE TABLE` (`a` int COMMENT 'CREATE TABLE x')", "CREATE TABLE IF NOT EXISTS `CREATE TABLE` (`a` int COMMENT 'CREATE TABLE x')"},
+		{"CREATE VIEW `v` AS select 1", "CREATE VIEW `v` AS select 1"},
+	}
+	for _, tt := range tests {
+		if got := addIfNotExists(tt.in); got != tt.want {
+			t.Errorf("addIfNotExists(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
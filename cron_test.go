@@ -0,0 +1,42 @@
+package mysqldump
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseCron(t *testing.T) {
+	from := time.Date(2024, 3, 17, 10, 30, 15, 0, time.UTC) // 周日
+	tests := []struct {
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{spec: "* * * * *", want: time.Date(2024, 3, 17, 10, 31, 0, 0, time.UTC)},
+		{spec: "0 3 * * *", want: time.Date(2024, 3, 18, 3, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2024, 3, 17, 11, 0, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2024, 3, 17, 10, 45, 0, 0, time.UTC)},
+		{spec: "0 2 * * 1-5", want: time.Date(2024, 3, 18, 2, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * *", want: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 12 * * 7", want: time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1,15 * 3", want: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := parseCron(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCron() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := s.next(from); !got.Equal(tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package mysqldump
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVFormatter 每个表导出为 CSV 或 TSV, 表结构不导出
+// 通常配合 WithCSV 使用, 每个表写到单独的文件
+type CSVFormatter struct {
+	// 分隔符, 默认为 ','
+	Comma rune
+	// NULL 的表示, 默认为空字符串
+	Null string
+	// 不输出列名行
+	NoHeader bool
+}
+
+// NewCSVFormatter 逗号分隔, 有列名行, NULL 为空字符串
+func NewCSVFormatter() *CSVFormatter {
+	return &CSVFormatter{Comma: ','}
+}
+
+// NewTSVFormatter 制表符分隔, 有列名行, NULL 为空字符串
+func NewTSVFormatter() *CSVFormatter {
+	return &CSVFormatter{Comma: '\t'}
+}
+
+// Ext 文件扩展名
+func (f *CSVFormatter) Ext() string {
+	if f.Comma == '\t' {
+		return ".tsv"
+	}
+	return ".csv"
+}
+
+func (f *CSVFormatter) writeRecord(w io.Writer, record []string) error {
+	cw := csv.NewWriter(w)
+	if f.Comma != 0 {
+		cw.Comma = f.Comma
+	}
+	_ = cw.Write(record)
+	cw.Flush()
+	return cw.Error()
+}
+
+func (f *CSVFormatter) Header(io.Writer, *DumpMeta) error { return nil }
+
+func (f *CSVFormatter) TableSchema(io.Writer, *TableMeta) error { return nil }
+
+func (f *CSVFormatter) TableDataBegin(w io.Writer, table *TableMeta) error {
+	if f.NoHeader {
+		return nil
+	}
+	return f.writeRecord(w, table.Columns)
+}
+
+func (f *CSVFormatter) Row(w io.Writer, table *TableMeta, row []interface{}) error {
+	record := make([]string, len(row))
+	for i, col := range row {
+		value, null, err := textValue(col, table.DataTypes[i])
+		if err != nil {
+			return err
+		}
+		if null {
+			value = f.Null
+		}
+		record[i] = value
+	}
+	return f.writeRecord(w, record)
+}
+
+func (f *CSVFormatter) TableDataEnd(io.Writer, *TableMeta) error { return nil }
+
+func (f *CSVFormatter) Footer(io.Writer, *DumpMeta) error { return nil }
+
+// WithCSV 每个表导出为 dir 下的 <表名>.csv (TSV 为 .tsv) 文件
+// f 为 nil 时使用 NewCSVFormatter
+func WithCSV(dir string, f *CSVFormatter) DumpOption {
+	if f == nil {
+		f = NewCSVFormatter()
+	}
+	return func(option *dumpOption) {
+		option.formatter = f
+		option.tableWriter = dirTableWriter(dir, f.Ext())
+	}
+}
+
+// dirTableWriter 返回在 dir 下为每个表创建 <表名><ext> 文件的 tableWriter
+func dirTableWriter(dir string, ext string) func(table string) io.WriteCloser {
+	return func(table string) io.WriteCloser {
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return errWriteCloser{err: err}
+		}
+		file, err := os.Create(filepath.Join(dir, table+ext))
+		if err != nil {
+			return errWriteCloser{err: err}
+		}
+		return file
+	}
+}
+
+// errWriteCloser 写入时返回创建失败的错误
+type errWriteCloser struct {
+	err error
+}
+
+func (w errWriteCloser) Write([]byte) (int, error) { return 0, w.err }
+
+func (w errWriteCloser) Close() error { return w.err }
+
+// textValue 将列值转换为不带引号和转义的文本, 供 CSV 等格式使用
+// 二进制类型转换为十六进制, 第二个返回值表示是否为 NULL
+func textValue(col interface{}, dataType string) (string, bool, error) {
+	if col == nil {
+		return "", true, nil
+	}
+	unsigned := strings.Contains(dataType, "UNSIGNED")
+	dataType = strings.TrimSpace(strings.Replace(dataType, "UNSIGNED", "", -1))
+	switch v := col.(type) {
+	case []byte:
+		switch dataType {
+		case "BIT", "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+			return hex.EncodeToString(v), false, nil
+		case "VECTOR":
+			if text, ok := vectorText(v); ok {
+				return text, false, nil
+			}
+			return hex.EncodeToString(v), false, nil
+		}
+		return string(v), false, nil
+	case string:
+		return v, false, nil
+	case int64, uint64:
+		value, err := formatInteger(v, unsigned)
+		return value, false, err
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), false, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), false, nil
+	case bool:
+		if v {
+			return "1", false, nil
+		}
+		return "0", false, nil
+	case time.Time:
+		if dataType == "DATE" {
+			return v.Format("2006-01-02"), false, nil
+		}
+		// DATETIME(6) 和 TIMESTAMP(6) 保留微秒, 没有小数部分时不输出
+		return v.Format("2006-01-02 15:04:05.999999"), false, nil
+	default:
+		return fmt.Sprint(v), false, nil
+	}
+}
@@ -0,0 +1,56 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVFormatter(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"id", "name", "data", "created"}, DataTypes: []string{"INT", "VARCHAR", "BLOB", "DATE"}}
+	row := []interface{}{int64(1), []byte("a,\"b\""), []byte{0x01, 0xff}, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	nulls := []interface{}{int64(2), nil, nil, nil}
+	tests := []struct {
+		name string
+		f    *CSVFormatter
+		want string
+	}{
+		{name: "csv", f: NewCSVFormatter(), want: "id,name,data,created\n1,\"a,\"\"b\"\"\",01ff,2024-01-02\n2,,,\n"},
+		{name: "tsv null", f: &CSVFormatter{Comma: '\t', Null: `\N`}, want: "id\tname\tdata\tcreated\n1\t\"a,\"\"b\"\"\"\t01ff\t2024-01-02\n2\t\\N\t\\N\t\\N\n"},
+		{name: "no header", f: &CSVFormatter{NoHeader: true}, want: "1,\"a,\"\"b\"\"\",01ff,2024-01-02\n2,,,\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.f.TableDataBegin(&sb, table); err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range [][]interface{}{row, nulls} {
+				if err := tt.f.Row(&sb, table, r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if sb.String() != tt.want {
+				t.Errorf("got %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_textValue_time(t *testing.T) {
+	tests := []struct {
+		value    time.Time
+		dataType string
+		want     string
+	}{
+		{value: time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC), dataType: "DATETIME", want: "2024-01-02 03:04:05.123456"},
+		{value: time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC), dataType: "TIMESTAMP", want: "2024-01-02 03:04:05.5"},
+		{value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), dataType: "DATETIME", want: "2024-01-02 03:04:05"},
+		{value: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), dataType: "DATE", want: "2024-01-02"},
+	}
+	for _, tt := range tests {
+		if got, _, _ := textValue(tt.value, tt.dataType); got != tt.want {
+			t.Errorf("textValue(%v, %s) = %s, want %s", tt.value, tt.dataType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package mysqldump
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// DataDiff DiffData 和 DiffDumpData 的结果, 只包括有差异的表
+type DataDiff struct {
+	Tables []TableDataDiff
+}
+
+// Empty 数据是否相同
+func (d *DataDiff) Empty() bool {
+	return len(d.Tables) == 0
+}
+
+// TableDataDiff 一个表中不同的行, 每行以主键的值表示, 联合主键的值以逗号分隔
+type TableDataDiff struct {
+	Name string
+	// 只在源中的行
+	MissingRows []string
+	// 只在目标中的行
+	ExtraRows []string
+	// 两边都有但内容不同的行
+	ChangedRows []string
+}
+
+func (d *TableDataDiff) empty() bool {
+	return len(d.MissingRows) == 0 && len(d.ExtraRows) == 0 && len(d.ChangedRows) == 0
+}
+
+// compareRows 比较主键相同的行, 值为行的内容或哈希
+func (d *TableDataDiff) compareRows(src, dst map[string]string) {
+	var missing, extra, changed []string
+	for key, value := range src {
+		other, ok := dst[key]
+		switch {
+		case !ok:
+			missing = append(missing, key)
+		case other != value:
+			changed = append(changed, key)
+		}
+	}
+	for key := range dst {
+		if _, ok := src[key]; !ok {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	sort.Strings(changed)
+	d.MissingRows = append(d.MissingRows, missing...)
+	d.ExtraRows = append(d.ExtraRows, extra...)
+	d.ChangedRows = append(d.ChangedRows, changed...)
+}
+
+// DiffData 按主键分块比较 srcDSN 和 dstDSN 中表的数据, 用于检查恢复或复制的结果
+// 每块先比较两边的行数和校验和, 与 ChecksumTables 相同, 不同时再比较每行的哈希, 只传输主键和哈希
+// 块的大小使用 WithChunkSize, 默认 1000 行, 支持导出的表选择和 WithOmitColumns, 没有主键的表不比较
+func DiffData(srcDSN, dstDSN string, opts ...DumpOption) (*DataDiff, error) {
+	o := newDumpOption(opts)
+	o.result = &DumpResult{}
+	if o.chunkSize <= 0 {
+		o.chunkSize = 1000
+	}
+
+	src, err := openDB(srcDSN, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	defer src.Close()
+	dst, err := openDB(dstDSN, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	defer dst.Close()
+
+	tables, _, err := selectTables(src, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	diff := &DataDiff{}
+	for _, table := range tables {
+		if o.views[table] || o.sequences[table] {
+			continue
+		}
+		td, err := diffTableData(src, dst, table, o)
+		if err != nil {
+			log.Printf("[error] [diff] %s: %v \n", table, err)
+			return nil, err
+		}
+		if td != nil {
+			diff.Tables = append(diff.Tables, *td)
+		}
+	}
+	return diff, nil
+}
+
+// diffTableData 分块比较一个表, 没有差异时返回 nil
+func diffTableData(src, dst queryer, table string, o *dumpOption) (*TableDataDiff, error) {
+	plan, err := newChecksumPlan(src, table, o)
+	if err != nil {
+		return nil, err
+	}
+	if plan.pkList == "" {
+		o.warn(Warning{Kind: WarningNoPrimaryKey, Table: table, Message: "no primary key, data not compared"})
+		return nil, nil
+	}
+	td := &TableDataDiff{Name: table}
+	err = plan.walk(src, func(chunk ChunkChecksum, where string) error {
+		rows, checksum, err := plan.checksum(dst, where)
+		if err != nil || rows == chunk.Rows && checksum == chunk.Checksum {
+			return err
+		}
+		query := fmt.Sprintf("SELECT %s, %s FROM %s%s", plan.pkList, plan.hash, plan.from, where)
+		srcRows, err := queryRowHashes(src, query)
+		if err != nil {
+			return err
+		}
+		dstRows, err := queryRowHashes(dst, query)
+		if err != nil {
+			return err
+		}
+		td.compareRows(srcRows, dstRows)
+		return nil
+	})
+	if err != nil || td.empty() {
+		return nil, err
+	}
+	return td, nil
+}
+
+// queryRowHashes 查询主键和行哈希, 哈希在最后一列
+func queryRowHashes(db queryer, query string) (map[string]string, error) {
+	rows, dataTypes, err := queryValues(db, query)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(rows))
+	for _, row := range rows {
+		n := len(row) - 1
+		fields := make([]string, n)
+		for i := range fields {
+			fields[i], err = compareValue(row[i], dataTypes[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		hashes[strings.Join(fields, ",")] = valueToString(row[n])
+	}
+	return hashes, nil
+}
+
+// compareValue 将列值转换为与 parseInsertRows 相同的 LOAD DATA 格式, 用于和导出文件中的值比较
+func compareValue(col interface{}, dataType string) (string, error) {
+	if bs, ok := col.([]byte); ok {
+		return escapeTabValue(string(bs)), nil
+	}
+	value, null, err := textValue(col, dataType)
+	if err != nil {
+		return "", err
+	}
+	if null {
+		return `\N`, nil
+	}
+	return escapeTabValue(value), nil
+}
+
+// DiffDumpData 比较导出文件中 INSERT 的数据与 dsn 数据库中的数据, 导出文件为源
+// 表的列和主键来自导出文件中的 CREATE TABLE, 只比较导出文件中有数据的表, 没有主键的表不比较
+// 一个表的数据在比较时保存在内存中, 导出文件中同一个表的数据需要是连续的
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func DiffDumpData(r io.Reader, dsn string, opts ...DumpOption) (*DataDiff, error) {
+	o := newDumpOption(opts)
+	o.result = &DumpResult{}
+
+	db, err := openDB(dsn, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	diff := &DataDiff{}
+	defs := make(map[string]*tableDef)
+	// 当前表的列, 主键在列中的下标和导出文件中的行
+	var current string
+	var columns []string
+	var pkIndex []int
+	var rows map[string]string
+	flush := func() error {
+		if current == "" || pkIndex == nil {
+			current = ""
+			return nil
+		}
+		td := &TableDataDiff{Name: current}
+		dbRows, err := queryTableRows(db, current, columns, pkIndex)
+		if err != nil {
+			return err
+		}
+		td.compareRows(rows, dbRows)
+		if !td.empty() {
+			diff.Tables = append(diff.Tables, *td)
+		}
+		current = ""
+		return nil
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		stmt, rerr := readStatement(br)
+		if rerr != nil && rerr != io.EOF {
+			return nil, rerr
+		}
+		tokens := statementTokens(stmt)
+		switch {
+		case len(tokens) > 0 && tokens[0].isKeyword("CREATE") && createObjectKind(tokens) == "TABLE":
+			if err = flush(); err != nil {
+				return nil, err
+			}
+			def, err := parseCreateTable(stmt)
+			if err != nil {
+				return nil, err
+			}
+			defs[def.name] = def
+		case len(tokens) > 0 && tokens[0].isKeyword("INSERT", "REPLACE"):
+			insert, ok := parseInsertRows(stmt)
+			if !ok {
+				return nil, fmt.Errorf("unsupported INSERT: %.100s", statementText(stmt))
+			}
+			if insert.table != current {
+				if err = flush(); err != nil {
+					return nil, err
+				}
+				current, pkIndex, rows = insert.table, nil, make(map[string]string)
+				def := defs[current]
+				selected := o.isAllTable && !containsFold(o.ignoreTables, current) || !o.isAllTable && containsFold(o.tables, current)
+				if selected && def != nil && len(def.primaryKey) > 0 {
+					columns = insert.columns
+					if len(columns) == 0 {
+						for _, col := range def.columns {
+							columns = append(columns, col.name)
+						}
+					}
+					pkIndex = columnIndexes(columns, def.primaryKey)
+				}
+				if selected && pkIndex == nil {
+					o.warn(Warning{Kind: WarningNoPrimaryKey, Table: current, Message: "no primary key in dump, data not compared"})
+				}
+			}
+			if pkIndex == nil {
+				break
+			}
+			for _, line := range insert.lines {
+				fields := strings.Split(strings.TrimSuffix(line, "\n"), "\t")
+				if len(fields) != len(columns) {
+					return nil, fmt.Errorf("table %s: %d values for %d columns", current, len(fields), len(columns))
+				}
+				rows[rowKey(fields, pkIndex)] = strings.Join(fields, "\t")
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+	}
+	if err = flush(); err != nil {
+		log.Printf("[error] [diff] %s: %v \n", current, err)
+		return nil, err
+	}
+	return diff, nil
+}
+
+// queryTableRows 查询表的所有行, 转换为与 parseInsertRows 相同的格式
+func queryTableRows(db queryer, table string, columns []string, pkIndex []int) (map[string]string, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT %s FROM %s", quoteColumns(columns), QuoteIdentifier(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	row := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(row))
+	for i := range row {
+		pointers[i] = &row[i]
+	}
+	fields := make([]string, len(columns))
+	for rows.Next() {
+		err = rows.Scan(pointers...)
+		if err != nil {
+			return nil, err
+		}
+		for i, col := range row {
+			fields[i], err = compareValue(col, columnTypes[i].DatabaseTypeName())
+			if err != nil {
+				return nil, err
+			}
+		}
+		result[rowKey(fields, pkIndex)] = strings.Join(fields, "\t")
+	}
+	return result, rows.Err()
+}
+
+// columnIndexes 返回 names 在 columns 中的下标, 有不存在的列时返回 nil
+func columnIndexes(columns, names []string) []int {
+	indexes := make([]int, 0, len(names))
+	for _, name := range names {
+		found := -1
+		for i, column := range columns {
+			if strings.EqualFold(column, name) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			return nil
+		}
+		indexes = append(indexes, found)
+	}
+	return indexes
+}
+
+// rowKey 以逗号连接主键的值
+func rowKey(fields []string, pkIndex []int) string {
+	values := make([]string, len(pkIndex))
+	for i, index := range pkIndex {
+		values[i] = fields[index]
+	}
+	return strings.Join(values, ",")
+}
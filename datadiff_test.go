@@ -0,0 +1,48 @@
+package mysqldump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTableDataDiff_compareRows(t *testing.T) {
+	td := &TableDataDiff{Name: "t"}
+	td.compareRows(
+		map[string]string{"1": "a", "2": "b", "3": "c", "5": "e"},
+		map[string]string{"1": "a", "2": "x", "4": "d", "5": "y"},
+	)
+	want := &TableDataDiff{Name: "t", MissingRows: []string{"3"}, ExtraRows: []string{"4"}, ChangedRows: []string{"2", "5"}}
+	if !reflect.DeepEqual(td, want) {
+		t.Errorf("compareRows() = %+v, want %+v", td, want)
+	}
+}
+
+// compareValue 的结果需要与导出文件中解析出的值相同
+func Test_compareValue(t *testing.T) {
+	row := []interface{}{int64(1), []byte("it's\ta"), nil, []byte{0x00, 0xff}, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), true}
+	types := []string{"INT", "VARCHAR", "TEXT", "BLOB", "DATETIME", "BOOL"}
+	var insert strings.Builder
+	if err := (&sqlFormatter{o: &dumpOption{}}).Row(&insert, &TableMeta{Name: "t", DataTypes: types}, row); err != nil {
+		t.Fatal(err)
+	}
+	parsed, ok := parseInsertRows(insert.String())
+	if !ok {
+		t.Fatalf("parseInsertRows(%q) failed", insert.String())
+	}
+	fields := make([]string, len(row))
+	for i, col := range row {
+		var err error
+		fields[i], err = compareValue(col, types[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := strings.Join(fields, "\t") + "\n"; got != parsed.lines[0] {
+		t.Errorf("compareValue() = %q, dump = %q", got, parsed.lines[0])
+	}
+	if got := rowKey(fields, columnIndexes([]string{"id", "name"}, []string{"NAME", "id"})); got != `it's\ta,1` {
+		t.Errorf("rowKey() = %q", got)
+	}
+}
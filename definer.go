@@ -0,0 +1,59 @@
+package mysqldump
+
+import "strings"
+
+// WithSkipDefiner 去掉视图, 触发器, 存储过程和事件中的 DEFINER=`user`@`host`,
+// 恢复时使用执行恢复的用户, 避免目标库没有该用户时报错 1449
+func WithSkipDefiner() DumpOption {
+	return func(option *dumpOption) {
+		option.skipDefiner = true
+	}
+}
+
+// stripDefiner 去掉语句中的 DEFINER 子句, 字符串和注释中的内容不变
+func stripDefiner(stmt string) string {
+	tokens := tokenizeSQL(stmt)
+	var sb strings.Builder
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].isKeyword("DEFINER") {
+			sb.WriteString(tokens[i].text)
+			continue
+		}
+		// DEFINER [ ] = [ ] user[@host] | CURRENT_USER[()]
+		j := skipSpace(tokens, i+1)
+		if j >= len(tokens) || tokens[j].text != "=" {
+			// SQL SECURITY DEFINER
+			sb.WriteString(tokens[i].text)
+			continue
+		}
+		j = skipSpace(tokens, j+1)
+		if j >= len(tokens) {
+			sb.WriteString(tokens[i].text)
+			continue
+		}
+		if tokens[j].isKeyword("CURRENT_USER") {
+			j++
+			if j+1 < len(tokens) && tokens[j].text == "(" && tokens[j+1].text == ")" {
+				j += 2
+			}
+		} else {
+			j++
+			if j+1 < len(tokens) && tokens[j].text == "@" {
+				j += 2
+			}
+		}
+		// 去掉后面的一个空白
+		if j < len(tokens) && tokens[j].kind == tokenSpace {
+			j++
+		}
+		i = j - 1
+	}
+	return sb.String()
+}
+
+func skipSpace(tokens []sqlToken, i int) int {
+	for i < len(tokens) && tokens[i].kind == tokenSpace {
+		i++
+	}
+	return i
+}
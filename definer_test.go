@@ -0,0 +1,27 @@
+package mysqldump
+
+import "testing"
+
+func Test_stripDefiner(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "CREATE OR REPLACE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS select 1",
+			want: "CREATE OR REPLACE ALGORITHM=UNDEFINED SQL SECURITY DEFINER VIEW `v` AS select 1",
+		},
+		{
+			in:   "CREATE DEFINER=`app`@`10.0.0.%` TRIGGER `t` BEFORE INSERT ON `a` FOR EACH ROW SET NEW.x = 'DEFINER=`x`@`y`'",
+			want: "CREATE TRIGGER `t` BEFORE INSERT ON `a` FOR EACH ROW SET NEW.x = 'DEFINER=`x`@`y`'",
+		},
+		{in: "CREATE DEFINER = CURRENT_USER() PROCEDURE `p`() BEGIN END", want: "CREATE PROCEDURE `p`() BEGIN END"},
+		{in: "CREATE DEFINER=root@localhost EVENT `e` ON SCHEDULE EVERY 1 DAY DO SELECT 1", want: "CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO SELECT 1"},
+		{in: "CREATE TABLE `t` (`definer` int)", want: "CREATE TABLE `t` (`definer` int)"},
+	}
+	for _, tt := range tests {
+		if got := stripDefiner(tt.in); got != tt.want {
+			t.Errorf("stripDefiner(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package mysqldump
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrInvalidDSN DSN 格式错误或缺少导出需要的设置, 错误信息中说明如何修改
+var ErrInvalidDSN = errors.New("invalid dsn")
+
+// ValidateDSN 连接数据库前检查 DSN 能否用于导出, Dump 开始时也会检查
+// 检查格式, 是否指定了数据库, INSERT 格式导出数据时是否设置了 parseTime=true,
+// 以及 WithReadOnlyGuard 时是否设置了 multiStatements=true, 错误信息中的密码已隐藏
+// 检查的是加上 WithoutDSNDefaults 所述默认参数之后的 DSN
+func ValidateDSN(dsn string, opts ...DumpOption) error {
+	o := newDumpOption(opts)
+	_, err := checkDSN(o.prepareDSN(dsn), o)
+	return err
+}
+
+// WithoutDSNDefaults 不修改 DSN 的参数, 按原样连接
+// 默认在 DSN 没有设置时加上导出需要的参数: parseTime=true, charset=utf8mb4, maxAllowedPacket=0 (使用服务端的设置)
+func WithoutDSNDefaults() DumpOption {
+	return func(option *dumpOption) {
+		option.noDSNDefaults = true
+	}
+}
+
+// prepareDSN 加上 DSN 中没有设置的默认参数, DSN 格式错误时原样返回, 由 checkDSN 报告错误
+func (o *dumpOption) prepareDSN(dsn string) string {
+	if o.noDSNDefaults {
+		return dsn
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return dsn
+	}
+	// FormatDSN 只输出与默认值不同的参数, 可以据此判断是否设置过
+	explicit := cfg.FormatDSN()
+	// parseTime=false 与默认值相同, FormatDSN 不输出, 从原 DSN 判断; 显式设置时保留, 由 checkDSN 报告 INSERT 格式需要它
+	if !dsnHasParam(dsn, "parseTime") {
+		cfg.ParseTime = true
+	}
+	if !dsnHasParam(explicit, "maxAllowedPacket") {
+		cfg.MaxAllowedPacket = 0
+	}
+	formatted := cfg.FormatDSN()
+	// 字符集没有导出的字段, 直接加在参数中
+	if !dsnHasParam(explicit, "charset") {
+		separator := "?"
+		if strings.Contains(formatted, "?") {
+			separator = "&"
+		}
+		formatted += separator + "charset=utf8mb4"
+	}
+	return formatted
+}
+
+// checkDSN 解析并检查 DSN, 返回解析后的配置
+func checkDSN(dsn string, o *dumpOption) (*mysql.Config, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v, expected user:password@tcp(host:3306)/dbname?parseTime=true", ErrInvalidDSN, err)
+	}
+	if cfg.DBName == "" {
+		return nil, fmt.Errorf("%w: no database in %s, add the database name after the slash, e.g. user:password@tcp(host:3306)/dbname",
+			ErrInvalidDSN, redactDSN(cfg))
+	}
+	if o.readOnlyGuard && cfg.MultiStatements {
+		return nil, fmt.Errorf("%w: multiStatements=true is not allowed with WithReadOnlyGuard, remove it from %s",
+			ErrInvalidDSN, redactDSN(cfg))
+	}
+	if o.isData && o.needsParseTime() && !cfg.ParseTime {
+		return nil, fmt.Errorf("%w: parseTime=true is required to dump DATE, DATETIME and TIMESTAMP values, add it to %s",
+			ErrInvalidDSN, redactDSN(cfg))
+	}
+	return cfg, nil
+}
+
+// needsParseTime INSERT 格式的日期值需要驱动返回 time.Time, CSV 等格式直接使用驱动返回的文本
+func (o *dumpOption) needsParseTime() bool {
+	switch o.formatter.(type) {
+	case *sqlFormatter, *compatFormatter:
+		return true
+	}
+	return false
+}
+
+// dsnHasParam DSN 的参数中是否有 name, 不检查密码等 / 之前的部分
+func dsnHasParam(dsn, name string) bool {
+	_, query, _ := strings.Cut(dsn[strings.LastIndex(dsn, "/")+1:], "?")
+	for _, param := range strings.Split(query, "&") {
+		if key, _, _ := strings.Cut(param, "="); key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// redactDSN 返回隐藏密码后的 DSN, 用于错误信息和日志
+func redactDSN(cfg *mysql.Config) string {
+	cfg = cfg.Clone()
+	if cfg.Passwd != "" {
+		cfg.Passwd = "xxxxx"
+	}
+	return cfg.FormatDSN()
+}
@@ -0,0 +1,102 @@
+package mysqldump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		dsn     string
+		opts    []DumpOption
+		wantErr string
+	}{
+		{name: "schema only", dsn: "root:pass@tcp(127.0.0.1:3306)/test"},
+		{name: "data", dsn: "root:pass@tcp(127.0.0.1:3306)/test?parseTime=true", opts: []DumpOption{WithData()}},
+		{name: "csv without parseTime", dsn: "root:pass@tcp(127.0.0.1:3306)/test", opts: []DumpOption{WithData(), WithFormatter(&CSVFormatter{})}},
+		{name: "bad format", dsn: "root:pass@127.0.0.1:3306/test", wantErr: "expected user:password@tcp(host:3306)/dbname"},
+		{name: "no database", dsn: "root:secret@tcp(127.0.0.1:3306)/", wantErr: "no database"},
+		{name: "parseTime added", dsn: "root:secret@tcp(127.0.0.1:3306)/test", opts: []DumpOption{WithData()}},
+		{name: "multiStatements", dsn: "root:secret@tcp(127.0.0.1:3306)/test?multiStatements=true"},
+		{
+			name:    "multiStatements read-only",
+			dsn:     "root:secret@tcp(127.0.0.1:3306)/test?multiStatements=true",
+			opts:    []DumpOption{WithReadOnlyGuard()},
+			wantErr: "multiStatements=true is not allowed",
+		},
+		{name: "explicit parseTime=false", dsn: "root:secret@tcp(127.0.0.1:3306)/test?parseTime=false", opts: []DumpOption{WithData()}, wantErr: "parseTime=true is required"},
+		{name: "no parseTime", dsn: "root:secret@tcp(127.0.0.1:3306)/test", opts: []DumpOption{WithData(), WithoutDSNDefaults()}, wantErr: "parseTime=true is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDSN(tt.dsn, tt.opts...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateDSN() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidDSN) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateDSN() error = %v, want %q", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret") {
+				t.Errorf("ValidateDSN() error contains password: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetDBNameFromDSN(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{dsn: "root:pass@tcp(127.0.0.1:3306)/test?charset=utf8mb4", want: "test"},
+		{dsn: "root:pass@tcp(127.0.0.1:3306)/test", want: "test"},
+		{dsn: "root:p/a?ss@tcp(127.0.0.1:3306)/test?parseTime=true", want: "test"},
+		{dsn: "root@unix(/var/run/mysqld/mysqld.sock)/test", want: "test"},
+	}
+	for _, tt := range tests {
+		got, err := GetDBNameFromDSN(tt.dsn)
+		if err != nil || got != tt.want {
+			t.Errorf("GetDBNameFromDSN(%q) = %q, %v, want %q", tt.dsn, got, err, tt.want)
+		}
+	}
+	if _, err := GetDBNameFromDSN("root:pass@tcp(127.0.0.1:3306)/"); err == nil {
+		t.Error("GetDBNameFromDSN() want error without database")
+	}
+}
+
+func Test_dumpOption_prepareDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		opts []DumpOption
+		want string
+	}{
+		{name: "defaults", dsn: "root:pass@tcp(127.0.0.1:3306)/test", want: "root:pass@tcp(127.0.0.1:3306)/test?parseTime=true&maxAllowedPacket=0&charset=utf8mb4"},
+		{
+			name: "explicit",
+			dsn:  "root:pass@tcp(127.0.0.1:3306)/test?charset=latin1&maxAllowedPacket=1048576&parseTime=true",
+			want: "root:pass@tcp(127.0.0.1:3306)/test?charset=latin1&parseTime=true&maxAllowedPacket=1048576",
+		},
+		{name: "explicit parseTime=false", dsn: "root:pass@tcp(127.0.0.1:3306)/test?parseTime=false", want: "root:pass@tcp(127.0.0.1:3306)/test?maxAllowedPacket=0&charset=utf8mb4"},
+		{name: "password", dsn: "root:charset=x?@tcp(127.0.0.1:3306)/test", want: "root:charset=x?@tcp(127.0.0.1:3306)/test?parseTime=true&maxAllowedPacket=0&charset=utf8mb4"},
+		{
+			name: "unix socket",
+			dsn:  "root:pass@unix(/var/run/mysqld/mysqld.sock)/test?compress=true",
+			want: "root:pass@unix(/var/run/mysqld/mysqld.sock)/test?compress=true&parseTime=true&maxAllowedPacket=0&charset=utf8mb4",
+		},
+		{name: "opt out", dsn: "root:pass@tcp(127.0.0.1:3306)/test", opts: []DumpOption{WithoutDSNDefaults()}, want: "root:pass@tcp(127.0.0.1:3306)/test"},
+		{name: "invalid", dsn: "bad dsn", want: "bad dsn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newDumpOption(tt.opts).prepareDSN(tt.dsn); got != tt.want {
+				t.Errorf("prepareDSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrDumperClosed Dumper 已关闭
+var ErrDumperClosed = errors.New("dumper closed")
+
+// Dumper 可以重复执行的导出, 配置一次, 连接池在多次导出间复用, 实现 io.WriterTo
+//
+//	d := mysqldump.NewDumper(dsn, mysqldump.WithData())
+//	defer d.Close()
+//	err := d.Run(ctx, w)
+type Dumper struct {
+	dsn  string
+	opts []DumpOption
+	// 当前导出已写出的字节数
+	written atomic.Int64
+
+	// 导出依次执行, mu 同时保护 db 和 closed
+	mu sync.Mutex
+	// 第一次导出时打开, Close 时关闭
+	db     *sql.DB
+	closed bool
+}
+
+// NewDumper 创建导出, opts 中的 WithWriter 会被 Run 和 WriteTo 的参数覆盖, 不支持 WithCheckpoint
+func NewDumper(dsn string, opts ...DumpOption) *Dumper {
+	return &Dumper{dsn: dsn, opts: opts}
+}
+
+// Run 导出到 w, 可以多次调用, 每次使用新的选项状态和结果, 连接池在第一次导出时打开, 之后复用
+// 多个 goroutine 同时调用时依次执行, 避免共用连接池的导出互相等待连接; ctx 结束后写出失败, 导出停止
+// 不再使用时调用 Close 关闭连接池
+func (d *Dumper) Run(ctx context.Context, w io.Writer) error {
+	_, err := d.run(ctx, w)
+	return err
+}
+
+// WriteTo 导出到 w, 返回写出的字节数
+func (d *Dumper) WriteTo(w io.Writer) (int64, error) {
+	return d.run(context.Background(), w)
+}
+
+// Written 返回当前导出已写出的字节数, 可以在导出过程中从其他 goroutine 调用
+func (d *Dumper) Written() int64 {
+	return d.written.Load()
+}
+
+// Close 关闭连接池, 等待正在执行的导出结束, 之后的 Run 返回 ErrDumperClosed
+func (d *Dumper) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	if d.db == nil {
+		return nil
+	}
+	err := d.db.Close()
+	d.db = nil
+	return err
+}
+
+func (d *Dumper) run(ctx context.Context, w io.Writer) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return 0, ErrDumperClosed
+	}
+	d.written.Store(0)
+	counter := &dumperWriter{ctx: ctx, w: w, written: &d.written}
+	o := newDumpOption(append(d.opts[:len(d.opts):len(d.opts)], WithWriter(counter)))
+	db, err := d.pool(o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return 0, err
+	}
+	o.sharedPool = db
+	err = runDump(ctx, d.dsn, o)
+	return d.written.Load(), err
+}
+
+// pool 返回复用的连接池, 第一次调用时检查 DSN 并打开
+func (d *Dumper) pool(o *dumpOption) (*sql.DB, error) {
+	if d.db != nil {
+		return d.db, nil
+	}
+	dsn := o.prepareDSN(d.dsn)
+	_, err := checkDSN(dsn, o)
+	if err != nil {
+		return nil, err
+	}
+	d.db, err = openReadOnlyDB(dsn, o)
+	return d.db, err
+}
+
+// dumperWriter 原子地累加写出的字节数, ctx 结束后返回 ctx 的错误
+type dumperWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	written *atomic.Int64
+}
+
+func (w *dumperWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := w.w.Write(p)
+	w.written.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,90 @@
+package mysqldump
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDumper_WriteTo(t *testing.T) {
+	var _ io.WriterTo = &Dumper{}
+
+	var sb strings.Builder
+	d := NewDumper("root:pass@tcp(127.0.0.1:1)/test?timeout=1s")
+	n, err := d.WriteTo(&sb)
+	if err == nil {
+		t.Fatal("WriteTo() want connection error")
+	}
+	if n != int64(sb.Len()) || d.Written() != n {
+		t.Errorf("WriteTo() = %d, Written() = %d, output %d bytes", n, d.Written(), sb.Len())
+	}
+}
+
+func TestDumper_Run(t *testing.T) {
+	d := NewDumper("root:pass@tcp(127.0.0.1:1)/test?timeout=1s")
+	var sb strings.Builder
+	if err := d.Run(context.Background(), &sb); err == nil {
+		t.Fatal("Run() want connection error")
+	}
+	pool := d.db
+	if pool == nil {
+		t.Fatal("Run() did not keep the connection pool")
+	}
+	if err := d.Run(context.Background(), &sb); err == nil || d.db != pool {
+		t.Errorf("Run() error = %v, pool reused = %v", err, d.db == pool)
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := d.Run(context.Background(), &sb); !errors.Is(err, ErrDumperClosed) {
+		t.Errorf("Run() after Close error = %v, want ErrDumperClosed", err)
+	}
+
+	// DSN 错误时不打开连接池
+	d = NewDumper("root:pass@tcp(127.0.0.1:1)/")
+	if err := d.Run(context.Background(), &sb); !errors.Is(err, ErrInvalidDSN) || d.db != nil {
+		t.Errorf("Run() error = %v, want ErrInvalidDSN", err)
+	}
+}
+
+func Test_dumperWriter_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var written atomic.Int64
+	var sb strings.Builder
+	w := &dumperWriter{ctx: ctx, w: &sb, written: &written}
+	if _, err := w.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := w.Write([]byte("def")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Write() error = %v, want context.Canceled", err)
+	}
+	if sb.String() != "abc" || written.Load() != 3 {
+		t.Errorf("output = %q, written = %d", sb.String(), written.Load())
+	}
+}
+
+func Test_countWriter_onWrite(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	var calls []int64
+	c := &countWriter{w: io.Discard, onWrite: func(n int64) error {
+		calls = append(calls, n)
+		if n > 5 {
+			return errQuota
+		}
+		return nil
+	}}
+	if _, err := c.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write([]byte("defg")); err != errQuota {
+		t.Errorf("Write() error = %v, want %v", err, errQuota)
+	}
+	if len(calls) != 2 || calls[0] != 3 || calls[1] != 7 || c.n != 7 {
+		t.Errorf("calls = %v, n = %d", calls, c.n)
+	}
+}
@@ -0,0 +1,198 @@
+package mysqldump
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"time"
+)
+
+// estimateSampleRows 估算时每个表读取的行数
+const estimateSampleRows = 1000
+
+// TableEstimate 表的估算结果
+type TableEstimate struct {
+	Name string
+	// information_schema.TABLES 中的估算行数, 只导出结构的表为 0
+	Rows int64
+	// 数据和索引占用的空间
+	DataLength int64
+	// 估算的导出字节数
+	OutputBytes int64
+	// 估算的导出耗时
+	Duration time.Duration
+}
+
+// DumpEstimate 导出的估算结果
+type DumpEstimate struct {
+	Database string
+	Tables   []TableEstimate
+	// 估算的总字节数和总耗时, 并发导出时耗时按并发数折算
+	OutputBytes int64
+	Duration    time.Duration
+}
+
+// Rows 估算的总行数
+func (e *DumpEstimate) Rows() int64 {
+	var rows int64
+	for _, t := range e.Tables {
+		rows += t.Rows
+	}
+	return rows
+}
+
+// EstimateDump 估算导出的大小和耗时, 用于判断导出能否在维护窗口内完成
+// 表的选择与 Dump 相同, 每个表读取少量行计算每行的字节数和耗时, 再按 information_schema 中的行数外推
+// 行数来自 information_schema, InnoDB 表的误差可能较大
+func EstimateDump(dsn string, opts ...DumpOption) (*DumpEstimate, error) {
+	o := newDumpOption(opts)
+
+	db, err := openDB(dsn, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	dbName, err := GetDBNameFromDSN(dsn)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	_, err = db.Exec(fmt.Sprintf("USE %s", QuoteIdentifier(dbName)))
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	return estimateDump(db, dbName, o)
+}
+
+// estimateDump 在已切换到 dbName 的 db 上估算
+func estimateDump(db *sql.DB, dbName string, o *dumpOption) (*DumpEstimate, error) {
+	tables, noDataMap, err := selectTables(db, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	stats, err := getTableRows(db)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+
+	estimate := &DumpEstimate{Database: dbName}
+	var total time.Duration
+	for _, table := range tables {
+		st := stats[table]
+		te := TableEstimate{Name: table, DataLength: st.size}
+		schemaBytes, schemaTime, err := estimateSchema(db, table, o)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return nil, err
+		}
+		te.OutputBytes, te.Duration = schemaBytes, schemaTime
+
+		if o.isData && !noDataMap[table] && st.rows > 0 {
+			te.Rows = st.rows
+			rows, bytes, cost, err := sampleTableData(db, table, o)
+			if err != nil {
+				log.Printf("[error] %v \n", err)
+				return nil, err
+			}
+			if rows > 0 {
+				scale := float64(st.rows) / float64(rows)
+				te.OutputBytes += int64(float64(bytes) * scale)
+				te.Duration += time.Duration(float64(cost) * scale)
+			}
+		}
+		estimate.Tables = append(estimate.Tables, te)
+		estimate.OutputBytes += te.OutputBytes
+		total += te.Duration
+	}
+	if o.concurrency > 1 {
+		total /= time.Duration(o.concurrency)
+	}
+	estimate.Duration = total
+	return estimate, nil
+}
+
+// tableRows information_schema.TABLES 中的行数和大小
+type tableRows struct {
+	rows int64
+	size int64
+}
+
+func getTableRows(db *sql.DB) (map[string]tableRows, error) {
+	rows, err := db.Query("SELECT TABLE_NAME, IFNULL(TABLE_ROWS, 0), IFNULL(DATA_LENGTH, 0) + IFNULL(INDEX_LENGTH, 0) " +
+		"FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]tableRows)
+	for rows.Next() {
+		var name string
+		var st tableRows
+		err = rows.Scan(&name, &st.rows, &st.size)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = st
+	}
+	return result, rows.Err()
+}
+
+// estimateSchema 导出表结构, 返回字节数和耗时
+func estimateSchema(db *sql.DB, table string, o *dumpOption) (int64, time.Duration, error) {
+	if o.noSchema {
+		return 0, 0, nil
+	}
+	start := time.Now()
+	counter := &countWriter{w: io.Discard}
+	buf := bufio.NewWriter(counter)
+	var err error
+	switch {
+	case o.views[table]:
+		err = writeViewStruct(db, table, buf, o)
+	case o.sequences[table]:
+		err = writeSequenceStruct(db, table, buf, o)
+	default:
+		err = writeTableStruct(db, table, buf, o)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	err = buf.Flush()
+	return counter.n, time.Since(start), err
+}
+
+// sampleTableData 导出表的前 estimateSampleRows 行, 返回行数, 字节数和耗时
+func sampleTableData(db *sql.DB, table string, o *dumpOption) (int64, int64, time.Duration, error) {
+	selectList, partial, conds, err := buildTableSelect(db, table, o)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	query := fmt.Sprintf("%s %s FROM %s", o.selectKeyword(), selectList, QuoteIdentifier(table))
+	for i, cond := range conds {
+		if i == 0 {
+			query += " WHERE " + cond
+		} else {
+			query += " AND " + cond
+		}
+	}
+	query += fmt.Sprintf(" LIMIT %d", estimateSampleRows)
+
+	start := time.Now()
+	counter := &countWriter{w: io.Discard}
+	buf := bufio.NewWriter(counter)
+	w := &tableDataWriter{meta: &TableMeta{Name: table, PartialColumns: partial}, buf: buf, o: o}
+	n, err := w.writeRows(db, query)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	err = buf.Flush()
+	return int64(n), counter.n, time.Since(start), err
+}
@@ -0,0 +1,35 @@
+package mysqldump
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func Test_estimateDump(t *testing.T) {
+	db, f := newFakeDumpDB(t)
+	f.on("TABLE_ROWS", []string{"name VARCHAR", "rows BIGINT", "size BIGINT"},
+		[]driver.Value{[]byte("a"), int64(200), int64(16384)},
+		[]driver.Value{[]byte("b"), int64(50), int64(16384)})
+
+	o := newDumpOption([]DumpOption{WithAllTable(), WithData(), WithNoDataTables("b"), WithCompact()})
+	estimate, err := estimateDump(db, "test", o)
+	if err != nil {
+		t.Fatalf("estimateDump() error = %v", err)
+	}
+	if len(estimate.Tables) != 2 {
+		t.Fatalf("estimateDump() tables = %+v", estimate.Tables)
+	}
+	a, b := estimate.Tables[0], estimate.Tables[1]
+	// a 读取了 2 行, 按 200 行外推; b 只导出结构
+	schemaBytes := int64(len("CREATE TABLE IF NOT EXISTS `a` (`id` int);\n"))
+	rowBytes := int64(len("INSERT INTO `a` VALUES (1);\n"))
+	if a.Rows != 200 || a.DataLength != 16384 || a.OutputBytes != schemaBytes+200*rowBytes {
+		t.Errorf("table a = %+v, want %d output bytes", a, schemaBytes+200*rowBytes)
+	}
+	if b.Rows != 0 || b.OutputBytes != schemaBytes {
+		t.Errorf("table b = %+v, want %d output bytes", b, schemaBytes)
+	}
+	if estimate.Rows() != 200 || estimate.OutputBytes != a.OutputBytes+b.OutputBytes {
+		t.Errorf("estimate = %+v", estimate)
+	}
+}
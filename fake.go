@@ -0,0 +1,109 @@
+package mysqldump
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// 生成假数据使用的词表
+var (
+	fakeFirstNames = []string{
+		"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda",
+		"William", "Elizabeth", "David", "Barbara", "Richard", "Susan", "Joseph", "Jessica",
+		"Thomas", "Sarah", "Charles", "Karen", "Daniel", "Nancy", "Matthew", "Lisa",
+	}
+	fakeLastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+		"Rodriguez", "Martinez", "Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor",
+		"Moore", "Jackson", "Martin", "Lee", "Thompson", "White", "Harris", "Clark",
+	}
+	fakeStreets = []string{
+		"Main St", "Oak Ave", "Pine Rd", "Maple Dr", "Cedar Ln", "Elm St", "Lake Rd", "Hill St",
+		"Park Ave", "River Rd", "Sunset Blvd", "Washington St",
+	}
+	fakeCities = []string{
+		"Springfield", "Riverside", "Fairview", "Franklin", "Greenville", "Bristol", "Clinton",
+		"Salem", "Madison", "Georgetown", "Arlington", "Ashland",
+	}
+	fakeDomains = []string{"example.com", "example.net", "example.org"}
+)
+
+// fakeRand 由种子和原值派生的确定性随机数, 相同输入总是得到相同输出
+type fakeRand struct {
+	sum [sha256.Size]byte
+	pos int
+}
+
+func newFakeRand(seed string, value interface{}) *fakeRand {
+	return &fakeRand{sum: sha256.Sum256([]byte(seed + "\x00" + valueToString(value)))}
+}
+
+func (r *fakeRand) next() uint64 {
+	if r.pos+8 > len(r.sum) {
+		r.sum = sha256.Sum256(r.sum[:])
+		r.pos = 0
+	}
+	n := binary.BigEndian.Uint64(r.sum[r.pos:])
+	r.pos += 8
+	return n
+}
+
+func (r *fakeRand) pick(list []string) string {
+	return list[r.next()%uint64(len(list))]
+}
+
+// FakeName 生成确定性的假姓名, 如 Mary Johnson
+func FakeName(seed string) ColumnTransform {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		r := newFakeRand(seed, value)
+		return r.pick(fakeFirstNames) + " " + r.pick(fakeLastNames)
+	}
+}
+
+// FakeEmail 生成确定性的假邮箱, 如 mary.johnson42@example.com
+func FakeEmail(seed string) ColumnTransform {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		r := newFakeRand(seed, value)
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(r.pick(fakeFirstNames)), strings.ToLower(r.pick(fakeLastNames)),
+			r.next()%1000, r.pick(fakeDomains))
+	}
+}
+
+// FakeAddress 生成确定性的假地址, 如 123 Oak Ave, Salem
+func FakeAddress(seed string) ColumnTransform {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		r := newFakeRand(seed, value)
+		return fmt.Sprintf("%d %s, %s", r.next()%9900+100, r.pick(fakeStreets), r.pick(fakeCities))
+	}
+}
+
+// FakePhone 生成确定性的假电话, 保留原值的格式, 只替换其中的数字
+func FakePhone(seed string) ColumnTransform {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		r := newFakeRand(seed, value)
+		s := valueToString(value)
+		var b strings.Builder
+		for _, c := range s {
+			if c >= '0' && c <= '9' {
+				b.WriteByte(byte('0' + r.next()%10))
+				continue
+			}
+			b.WriteRune(c)
+		}
+		return b.String()
+	}
+}
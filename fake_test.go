@@ -0,0 +1,35 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFake(t *testing.T) {
+	fakers := map[string]ColumnTransform{
+		"name":    FakeName("s"),
+		"email":   FakeEmail("s"),
+		"address": FakeAddress("s"),
+		"phone":   FakePhone("s"),
+	}
+	for name, faker := range fakers {
+		a := faker([]byte("alice@corp.com"))
+		b := faker("alice@corp.com")
+		if a != b {
+			t.Errorf("%s: same input got %v and %v", name, a, b)
+		}
+		if faker(nil) != nil {
+			t.Errorf("%s: nil input should stay nil", name)
+		}
+	}
+
+	if FakeName("s")("a") == FakeName("other")("a") && FakeName("s")("b") == FakeName("other")("b") {
+		t.Errorf("FakeName: seed is ignored")
+	}
+	if email := FakeEmail("s")("x").(string); !strings.Contains(email, "@example.") {
+		t.Errorf("FakeEmail() = %s", email)
+	}
+	if phone := FakePhone("s")("138-1234-5678").(string); len(phone) != 13 || phone[3] != '-' || phone[8] != '-' {
+		t.Errorf("FakePhone() = %s, format not kept", phone)
+	}
+}
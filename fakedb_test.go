@@ -0,0 +1,185 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB 按查询文本返回预设结果的驱动, 测试不需要 MySQL 的导出流程
+// 查询中的 ? 替换为参数后匹配, 先添加的结果优先, 没有匹配的查询返回错误, Exec 总是成功
+type fakeDB struct {
+	mu      sync.Mutex
+	results []fakeResult
+	// 执行过的查询和语句, 以及执行的连接编号
+	queries []string
+	conns   []int
+	// 已打开的连接数
+	opened int
+}
+
+type fakeResult struct {
+	// 查询包含 match 时返回
+	match   string
+	columns []string
+	// DatabaseTypeName, 与 columns 对应
+	types []string
+	rows  [][]driver.Value
+	// 不为 nil 时查询返回该错误
+	err error
+}
+
+// newFakeDB 返回使用 fakeDB 的连接池, 测试结束时关闭
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	f := &fakeDB{}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, f
+}
+
+// on 添加查询结果, columns 为 "名称 类型" 的形式, 如 "id INT"
+func (f *fakeDB) on(match string, columns []string, rows ...[]driver.Value) {
+	r := fakeResult{match: match}
+	for _, column := range columns {
+		name, typ, _ := strings.Cut(column, " ")
+		r.columns = append(r.columns, name)
+		r.types = append(r.types, typ)
+	}
+	r.rows = rows
+	f.mu.Lock()
+	f.results = append(f.results, r)
+	f.mu.Unlock()
+}
+
+// fail 使包含 match 的查询返回 err, 优先于 on 添加的结果
+func (f *fakeDB) fail(match string, err error) {
+	f.mu.Lock()
+	f.results = append([]fakeResult{{match: match, err: err}}, f.results...)
+	f.mu.Unlock()
+}
+
+// executed 返回执行过的查询和语句
+func (f *fakeDB) executed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+// executedConns 返回每个查询和语句执行的连接编号, 与 executed 对应
+func (f *fakeDB) executedConns() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.conns...)
+}
+
+func (f *fakeDB) record(conn int, query string) {
+	f.mu.Lock()
+	f.queries = append(f.queries, query)
+	f.conns = append(f.conns, conn)
+	f.mu.Unlock()
+}
+
+func (f *fakeDB) query(conn int, query string, args []driver.NamedValue) (*fakeRows, error) {
+	for _, arg := range args {
+		query = strings.Replace(query, "?", fmt.Sprintf("'%v'", arg.Value), 1)
+	}
+	f.record(conn, query)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.results {
+		if strings.Contains(query, r.match) {
+			if r.err != nil {
+				return nil, r.err
+			}
+			return &fakeRows{result: r}, nil
+		}
+	}
+	return nil, fmt.Errorf("fakedb: unexpected query %s", query)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return f.Open("") }
+func (f *fakeDB) Driver() driver.Driver                        { return f }
+
+func (f *fakeDB) Open(string) (driver.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opened++
+	return fakeConn{f: f, id: f.opened}, nil
+}
+
+type fakeConn struct {
+	f  *fakeDB
+	id int
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fakedb: prepare not supported")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+func (c fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.f.query(c.id, query, args)
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.f.record(c.id, query)
+	return driver.RowsAffected(0), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	result fakeResult
+	i      int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string { return r.result.types[index] }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.i])
+	r.i++
+	return nil
+}
+
+// newFakeDumpDB 返回有 a 和 b 两个表的 fakeDB, 每个表有 id 主键和两行数据
+func newFakeDumpDB(t *testing.T) (*sql.DB, *fakeDB) {
+	db, f := newFakeDB(t)
+	f.on("SELECT VERSION()", []string{"version VARCHAR"}, []driver.Value{[]byte("8.0.36")})
+	f.on("SHOW TABLES", []string{"table VARCHAR"}, []driver.Value{[]byte("a")}, []driver.Value{[]byte("b")})
+	f.on("TABLE_TYPE = 'VIEW'", []string{"table VARCHAR"})
+	for _, table := range []string{"a", "b"} {
+		f.on("SHOW CREATE TABLE `"+table+"`", []string{"table VARCHAR", "create VARCHAR"},
+			[]driver.Value{[]byte(table), []byte("CREATE TABLE `" + table + "` (`id` int)")})
+	}
+	f.on("information_schema.COLUMNS", []string{"name VARCHAR", "extra VARCHAR"}, []driver.Value{[]byte("id"), []byte("")})
+	f.on("CONSTRAINT_NAME = 'PRIMARY'", []string{"name VARCHAR"}, []driver.Value{[]byte("id")})
+	f.on("SELECT `id` FROM", []string{"id INT"}, []driver.Value{int64(1)}, []driver.Value{int64(2)})
+	return db, f
+}
+
+// fakeDump 使用 db 作为连接池导出, 返回输出
+func fakeDump(db *sql.DB, opts ...DumpOption) (string, error) {
+	var sb strings.Builder
+	o := newDumpOption(append(opts, WithWriter(&sb)))
+	o.sharedPool = db
+	err := runDump(context.Background(), "root@tcp(127.0.0.1:1)/test", o)
+	return sb.String(), err
+}
@@ -0,0 +1,408 @@
+package mysqldump
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DumpMeta 导出信息, 传给 Formatter 的 Header 和 Footer
+type DumpMeta struct {
+	Database string
+	// DSN 中的地址, 不含端口
+	Host string
+	// DSN 中的用户名
+	User string
+	// SELECT VERSION() 的结果
+	ServerVersion string
+	// WithServerVariables 记录的全局变量
+	ServerVariables []ServerVariable
+	// 使用的选项, 如 data, drop-table, tables=a,b
+	Options []string
+	// 本工具的版本, 从构建信息中读取
+	ToolVersion string
+	// 执行导出的系统用户和主机名, 如 alice@build01
+	Invoker   string
+	StartTime time.Time
+	// Footer 时为结束时间
+	EndTime time.Time
+	// Footer 时为已导出的结果
+	Result *DumpResult
+}
+
+// TableMeta 表信息, 传给 Formatter 的表相关方法
+type TableMeta struct {
+	Name string
+	// SHOW CREATE TABLE 的结果, 视图为 CREATE OR REPLACE VIEW
+	CreateSQL string
+	// 是否为视图, 视图没有数据
+	View bool
+	// 是否为 MariaDB 的序列, 序列没有数据, CreateSQL 为 CREATE SEQUENCE
+	Sequence bool
+	// 序列的下一个值, 导出数据时才有
+	SequenceValue string
+	// 导出数据的列和类型, TableDataBegin 之后可用
+	Columns   []string
+	DataTypes []string
+	// 是否只导出了部分列或包含不可见列, 此时 INSERT 需要指定列名
+	PartialColumns bool
+}
+
+// Formatter 导出内容的格式, 核心逻辑负责查询, Formatter 负责生成输出
+// 方法的调用顺序为 Header, 每个表 TableSchema, TableDataBegin, Row..., TableDataEnd, 最后 Footer
+// 并发导出时不同表的方法会在不同 goroutine 中调用, 按分区并发导出时同一个表的 Row 也会并发调用
+type Formatter interface {
+	Header(w io.Writer, meta *DumpMeta) error
+	TableSchema(w io.Writer, table *TableMeta) error
+	TableDataBegin(w io.Writer, table *TableMeta) error
+	// row 和其中 []byte 的内容在调用后会被复用, 需要保留时应复制
+	Row(w io.Writer, table *TableMeta, row []interface{}) error
+	TableDataEnd(w io.Writer, table *TableMeta) error
+	Footer(w io.Writer, meta *DumpMeta) error
+}
+
+// WithFormatter 使用自定义格式导出, 默认为 MySQL SQL 语句
+func WithFormatter(f Formatter) DumpOption {
+	return func(option *dumpOption) {
+		option.formatter = f
+	}
+}
+
+// sqlFormatter 默认格式, 输出 MySQL 可执行的 SQL
+type sqlFormatter struct {
+	o *dumpOption
+}
+
+func (f *sqlFormatter) Header(w io.Writer, meta *DumpMeta) error {
+	err := f.o.writeHeaderComment(w, meta, "MySQL Database Dump")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, f.o.spacing("\n\n"))
+	if err == nil && f.o.timeZone != nil {
+		_, err = io.WriteString(w, "SET TIME_ZONE='"+timeZoneName(f.o.timeZone)+"';\n"+f.o.spacing("\n"))
+	}
+	if err == nil && f.o.tidb {
+		_, err = io.WriteString(w, tidbAutoRandomSQL+"\n"+f.o.spacing("\n"))
+	}
+	if err == nil && f.o.schemaFirst {
+		_, err = io.WriteString(w, "SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;\n"+f.o.spacing("\n"))
+	}
+	if err == nil && f.o.isDropDatabase {
+		use := "USE " + QuoteIdentifier(meta.Database) + ";\n"
+		if f.o.qualifiedNames {
+			use = ""
+		}
+		_, err = fmt.Fprintf(w, "DROP DATABASE IF EXISTS %s;\n%s;\n%s%s",
+			QuoteIdentifier(meta.Database), f.o.createDatabaseSQL, use, f.o.spacing("\n\n"))
+	}
+	return err
+}
+
+func (f *sqlFormatter) TableSchema(w io.Writer, table *TableMeta) error {
+	kind := "TABLE"
+	switch {
+	case table.View:
+		kind = "VIEW"
+	case table.Sequence:
+		kind = "SEQUENCE"
+	}
+	// 删除表
+	if f.o.isDropTable {
+		_, _ = fmt.Fprintf(w, "DROP %s IF EXISTS %s;\n", kind, f.o.objectName(table.Name))
+	}
+
+	// 导出表结构
+	switch {
+	case table.View:
+		_, _ = io.WriteString(w, f.o.banner("View structure for "+commentName(table.Name)))
+	case table.Sequence:
+		_, _ = io.WriteString(w, f.o.banner("Sequence structure for "+commentName(table.Name)))
+	default:
+		_, _ = io.WriteString(w, f.o.banner("Table structure for "+commentName(table.Name)))
+	}
+	_, _ = io.WriteString(w, f.o.qualifyCreateSQL(table.CreateSQL)+";\n")
+	if table.SequenceValue != "" {
+		_, _ = io.WriteString(w, sequenceSetval(f.o.objectName(table.Name), table.SequenceValue))
+	}
+	_, err := io.WriteString(w, f.o.spacing("\n\n\n"))
+	return err
+}
+
+func (f *sqlFormatter) TableDataBegin(w io.Writer, table *TableMeta) error {
+	_, err := io.WriteString(w, f.o.banner("Records of "+commentName(table.Name)))
+	if err == nil && f.o.isTruncateTable {
+		_, err = fmt.Fprintf(w, "TRUNCATE TABLE %s;\n", f.o.objectName(table.Name))
+	}
+	// TiDB 忽略 LOCK TABLES 和 DISABLE KEYS, 不输出
+	if err == nil && f.o.isAddLocks && !f.o.tidb {
+		_, err = fmt.Fprintf(w, "LOCK TABLES %s WRITE;\n", f.o.objectName(table.Name))
+	}
+	if err == nil && f.o.isDisableKeys && !f.o.tidb {
+		_, err = fmt.Fprintf(w, "/*!40000 ALTER TABLE %s DISABLE KEYS */;\n", f.o.objectName(table.Name))
+	}
+	return err
+}
+
+// appendInsertPrefix 追加 INSERT 语句到 VALUES ( 的部分
+func (f *sqlFormatter) appendInsertPrefix(dst []byte, table *TableMeta) []byte {
+	switch {
+	case f.o.incremental != nil:
+		// 增量数据可能已存在, 使用 REPLACE 覆盖
+		dst = append(dst, "REPLACE INTO "...)
+	case f.o.ignoreInsert(table.Name):
+		dst = append(dst, "INSERT IGNORE INTO "...)
+	default:
+		dst = append(dst, "INSERT INTO "...)
+	}
+	dst = f.o.appendObjectName(dst, table.Name)
+	if table.PartialColumns {
+		dst = append(dst, " ("...)
+		for i, column := range table.Columns {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendIdentifier(dst, column)
+		}
+		dst = append(dst, ')')
+	}
+	return append(dst, " VALUES ("...)
+}
+
+// WithHexBlob 二进制列的输出格式, 默认为 true, 输出为 0x 开头的十六进制
+// false 时输出为 _binary '...' 转义后的字符串, 与 mysqldump --skip-hex-blob 相同, BIT 列仍输出为十六进制
+func WithHexBlob(hex bool) DumpOption {
+	return func(option *dumpOption) {
+		option.noHexBlob = !hex
+	}
+}
+
+// isBinaryStringType 是否为二进制字符串类型, 不包括 BIT
+func isBinaryStringType(dataType string) bool {
+	switch dataType {
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return true
+	}
+	return false
+}
+
+// WithJSONCast JSON 列输出为 CAST('...' AS JSON), 恢复时由服务端校验 JSON 格式, 默认输出为字符串
+func WithJSONCast() DumpOption {
+	return func(option *dumpOption) {
+		option.jsonCast = true
+	}
+}
+
+// WithCompactJSON 去掉 JSON 列中的空白, 如服务端返回的 {"a": 1} 输出为 {"a":1}, 不改变键的顺序
+// 默认按服务端返回的内容输出
+func WithCompactJSON() DumpOption {
+	return func(option *dumpOption) {
+		option.compactJSON = true
+	}
+}
+
+// appendValue 追加 INSERT 中的值, 在 FormatValue 的基础上处理 WithHexBlob, WithJSONCast 和 WithCompactJSON
+func (f *sqlFormatter) appendValue(dst []byte, col interface{}, dataType string) ([]byte, error) {
+	if bs, ok := col.([]byte); ok && len(bs) > 0 && f.o.noHexBlob && isBinaryStringType(dataType) {
+		dst = append(dst, "_binary '"...)
+		dst = appendEscaped(dst, bs)
+		return append(dst, '\''), nil
+	}
+	if dataType == "VECTOR" {
+		if value, ok := f.o.vectorValue(col); ok {
+			return append(dst, value...), nil
+		}
+	}
+	if col != nil && dataType == "JSON" && (f.o.jsonCast || f.o.compactJSON) {
+		value, ok := col.([]byte)
+		if !ok {
+			value = appendText(nil, col)
+		}
+		if f.o.compactJSON {
+			var compacted bytes.Buffer
+			if json.Compact(&compacted, value) == nil {
+				value = compacted.Bytes()
+			}
+		}
+		if f.o.jsonCast {
+			dst = append(dst, "CAST("...)
+		}
+		dst = append(dst, '\'')
+		dst = appendEscaped(dst, value)
+		dst = append(dst, '\'')
+		if f.o.jsonCast {
+			dst = append(dst, " AS JSON)"...)
+		}
+		return dst, nil
+	}
+	return appendValue(dst, col, dataType)
+}
+
+// appendFallbackValue 不支持的类型按原始内容输出, UTF-8 文本为字符串, 其他为十六进制, 如 GEOMETRY
+func appendFallbackValue(dst []byte, col interface{}) []byte {
+	switch v := col.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return append(dst, "''"...)
+		}
+		if !utf8.Valid(v) {
+			return appendHex(append(dst, "0x"...), v)
+		}
+		return appendQuotedText(dst, v)
+	case time.Time:
+		return appendQuotedTime(dst, v, "2006-01-02 15:04:05.999999")
+	default:
+		return appendQuotedText(dst, fmt.Sprint(v))
+	}
+}
+
+// warnUnsupportedType 每个列只警告一次
+func (o *dumpOption) warnUnsupportedType(table *TableMeta, column int) {
+	key := table.Name + "." + table.Columns[column]
+	o.mu.Lock()
+	if o.unsupportedColumns[key] {
+		o.mu.Unlock()
+		return
+	}
+	if o.unsupportedColumns == nil {
+		o.unsupportedColumns = make(map[string]bool)
+	}
+	o.unsupportedColumns[key] = true
+	o.mu.Unlock()
+	o.warn(Warning{
+		Kind:    WarningUnsupportedType,
+		Table:   table.Name,
+		Column:  table.Columns[column],
+		Message: "unsupported type " + table.DataTypes[column] + ", dumped as raw value",
+	})
+}
+
+// rowBufferPool 复用 Row 拼接语句的内存, 并发导出时每个表各自取用
+var rowBufferPool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 1024)
+	return &b
+}}
+
+// maxPooledRowBuffer 超过这个大小的内存不放回, 避免个别大行长期占用内存
+const maxPooledRowBuffer = 1 << 20
+
+// streamValueSize 超过这个大小的二进制值不拼接到语句中, 分块转换后直接写到 writer
+const streamValueSize = 16 << 10
+
+// writeBinary 写出已拼接的语句和二进制值, 每次转换 streamValueSize 字节, b 作为转换的缓冲
+// 最后一块和结尾的引号留在返回的 b 中, 与语句的剩余部分一起写出
+func (f *sqlFormatter) writeBinary(w io.Writer, b []byte, value []byte) ([]byte, error) {
+	encode, suffix := appendHex, ""
+	if f.o.noHexBlob {
+		b = append(b, "_binary '"...)
+		encode, suffix = appendEscaped[[]byte], "'"
+	} else {
+		b = append(b, "0x"...)
+	}
+	for len(value) > 0 {
+		n := min(len(value), streamValueSize)
+		_, err := w.Write(b)
+		if err != nil {
+			return b[:0], err
+		}
+		b = encode(b[:0], value[:n])
+		value = value[n:]
+	}
+	return append(b, suffix...), nil
+}
+
+func (f *sqlFormatter) Row(w io.Writer, table *TableMeta, row []interface{}) error {
+	bp := rowBufferPool.Get().(*[]byte)
+	defer func() {
+		if cap(*bp) <= maxPooledRowBuffer {
+			rowBufferPool.Put(bp)
+		}
+	}()
+
+	err := f.checkStreamRow(table, row, (*bp)[:0])
+	if err != nil {
+		return err
+	}
+	b := f.appendInsertPrefix((*bp)[:0], table)
+	for i, col := range row {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if isStreamValue(col, table.DataTypes[i]) {
+			b, err = f.writeBinary(w, b, col.([]byte))
+			*bp = b
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		n := len(b)
+		b, err = f.appendValue(b, col, table.DataTypes[i])
+		if errors.Is(err, ErrUnsupportedType) {
+			f.o.warnUnsupportedType(table, i)
+			b, err = appendFallbackValue(b[:n], col), nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	b = append(b, ");\n"...)
+	*bp = b
+	_, err = w.Write(b)
+	return err
+}
+
+// isStreamValue 超过 streamValueSize 的二进制值由 writeBinary 直接写出
+func isStreamValue(col interface{}, dataType string) bool {
+	bs, ok := col.([]byte)
+	return ok && len(bs) > streamValueSize && isBinaryStringType(dataType)
+}
+
+// checkStreamRow 行中有直接写出的二进制值时, 先确认其他列都能格式化, 避免写出一半的 INSERT 后才出错
+// b 为格式化使用的缓冲, 没有需要直接写出的值时不做任何事
+func (f *sqlFormatter) checkStreamRow(table *TableMeta, row []interface{}, b []byte) error {
+	stream := false
+	for i, col := range row {
+		stream = stream || isStreamValue(col, table.DataTypes[i])
+	}
+	if !stream {
+		return nil
+	}
+	for i, col := range row {
+		if isStreamValue(col, table.DataTypes[i]) {
+			continue
+		}
+		var err error
+		b, err = f.appendValue(b[:0], col, table.DataTypes[i])
+		if err != nil && !errors.Is(err, ErrUnsupportedType) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *sqlFormatter) TableDataEnd(w io.Writer, table *TableMeta) error {
+	if f.o.isDisableKeys && !f.o.tidb {
+		_, _ = fmt.Fprintf(w, "/*!40000 ALTER TABLE %s ENABLE KEYS */;\n", f.o.objectName(table.Name))
+	}
+	if f.o.isAddLocks && !f.o.tidb {
+		_, _ = io.WriteString(w, "UNLOCK TABLES;\n")
+	}
+	_, err := io.WriteString(w, f.o.spacing("\n\n"))
+	return err
+}
+
+func (f *sqlFormatter) Footer(w io.Writer, meta *DumpMeta) error {
+	if f.o.schemaFirst {
+		_, err := io.WriteString(w, "SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;\n"+f.o.spacing("\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return f.o.writeFooterComment(w, meta)
+}
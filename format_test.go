@@ -0,0 +1,261 @@
+package mysqldump
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_sqlFormatter_Row(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"id", "name"}, DataTypes: []string{"INT", "VARCHAR"}}
+	blob := &TableMeta{Name: "t", Columns: []string{"id", "data"}, DataTypes: []string{"INT", "BLOB"}}
+	doc := &TableMeta{Name: "t", Columns: []string{"id", "doc"}, DataTypes: []string{"INT", "JSON"}}
+	geo := &TableMeta{Name: "t", Columns: []string{"id", "shape"}, DataTypes: []string{"INT", "GEOMETRY"}}
+	partial := &TableMeta{Name: "t", Columns: []string{"id", "name"}, DataTypes: []string{"INT", "VARCHAR"}, PartialColumns: true}
+	tests := []struct {
+		name  string
+		opts  []DumpOption
+		table *TableMeta
+		row   []interface{}
+		want  string
+	}{
+		{name: "insert", table: table, row: []interface{}{int64(1), []byte("a'b")}, want: "INSERT INTO `t` VALUES (1,'a\\'b');\n"},
+		{name: "null", table: table, row: []interface{}{int64(1), nil}, want: "INSERT INTO `t` VALUES (1,NULL);\n"},
+		{name: "ignore", opts: []DumpOption{WithIgnoreInsertTable()}, table: table, row: []interface{}{int64(2), []byte("x")}, want: "INSERT IGNORE INTO `t` VALUES (2,'x');\n"},
+		{name: "hex blob", table: blob, row: []interface{}{int64(1), []byte("a'\x00")}, want: "INSERT INTO `t` VALUES (1,0x612700);\n"},
+		{name: "binary string", opts: []DumpOption{WithHexBlob(false)}, table: blob, row: []interface{}{int64(1), []byte("a'\x00")}, want: "INSERT INTO `t` VALUES (1,_binary 'a\\'\\0');\n"},
+		{name: "empty binary string", opts: []DumpOption{WithHexBlob(false)}, table: blob, row: []interface{}{int64(1), []byte{}}, want: "INSERT INTO `t` VALUES (1,'');\n"},
+		{name: "json", table: doc, row: []interface{}{int64(1), []byte(`{"a": "it's"}`)}, want: "INSERT INTO `t` VALUES (1,'{\\\"a\\\": \\\"it\\'s\\\"}');\n"},
+		{name: "json cast", opts: []DumpOption{WithJSONCast()}, table: doc, row: []interface{}{int64(1), []byte(`{"a": 1}`)}, want: "INSERT INTO `t` VALUES (1,CAST('{\\\"a\\\": 1}' AS JSON));\n"},
+		{name: "compact json", opts: []DumpOption{WithCompactJSON()}, table: doc, row: []interface{}{int64(1), []byte(`{"b": [1, 2], "a": {"c": null}}`)}, want: "INSERT INTO `t` VALUES (1,'{\\\"b\\\":[1,2],\\\"a\\\":{\\\"c\\\":null}}');\n"},
+		{name: "compact json invalid", opts: []DumpOption{WithCompactJSON()}, table: doc, row: []interface{}{int64(1), []byte(`{bad `)}, want: "INSERT INTO `t` VALUES (1,'{bad ');\n"},
+		{name: "unsupported binary", table: geo, row: []interface{}{int64(1), []byte{0x00, 0x00, 0x00, 0x00, 0xff}}, want: "INSERT INTO `t` VALUES (1,0x00000000FF);\n"},
+		{name: "unsupported text", table: geo, row: []interface{}{int64(1), []byte("it's")}, want: "INSERT INTO `t` VALUES (1,'it\\'s');\n"},
+		{name: "columns", table: partial, row: []interface{}{int64(3), []byte("y")}, want: "INSERT INTO `t` (`id`,`name`) VALUES (3,'y');\n"},
+		{
+			name:  "hostile names",
+			table: &TableMeta{Name: "my`db.t t", Columns: []string{"select", "a`b", "c.d"}, DataTypes: []string{"INT", "INT", "INT"}, PartialColumns: true},
+			row:   []interface{}{int64(1), int64(2), int64(3)},
+			want:  "INSERT INTO `my``db.t t` (`select`,`a``b`,`c.d`) VALUES (1,2,3);\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			var sb strings.Builder
+			if err := o.formatter.Row(&sb, tt.table, tt.row); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("Row() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+// maxWriteRecorder 记录单次写入的最大长度
+type maxWriteRecorder struct {
+	strings.Builder
+	max int
+}
+
+func (r *maxWriteRecorder) Write(p []byte) (int, error) {
+	r.max = max(r.max, len(p))
+	return r.Builder.Write(p)
+}
+
+func Test_sqlFormatter_RowLargeBinary(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"id", "data", "name"}, DataTypes: []string{"INT", "LONGBLOB", "VARCHAR"}}
+	data := []byte(strings.Repeat("\x00'\xff", streamValueSize))
+	tests := []struct {
+		name  string
+		opts  []DumpOption
+		value string
+	}{
+		{name: "hex", value: "0x" + strings.Repeat("0027FF", streamValueSize)},
+		{name: "binary string", opts: []DumpOption{WithHexBlob(false)}, value: "_binary '" + strings.Repeat("\\0\\'\xff", streamValueSize) + "'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			var w maxWriteRecorder
+			if err := o.formatter.Row(&w, table, []interface{}{int64(1), data, []byte("a")}); err != nil {
+				t.Fatal(err)
+			}
+			want := "INSERT INTO `t` VALUES (1," + tt.value + ",'a');\n"
+			if w.String() != want {
+				t.Errorf("Row() = %.80q..., len %d, want len %d", w.String(), w.Len(), len(want))
+			}
+			// 每次最多写出一块转换后的内容
+			if w.max > 4*streamValueSize {
+				t.Errorf("max write = %d, value not streamed", w.max)
+			}
+		})
+	}
+}
+
+func Test_sqlFormatter_RowLargeBinaryInvalidColumn(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"data", "created"}, DataTypes: []string{"LONGBLOB", "DATETIME"}}
+	data := make([]byte, 2*streamValueSize)
+	var sb strings.Builder
+	// 二进制值之后的列格式化失败时不能写出半条语句
+	if err := newDumpOption(nil).formatter.Row(&sb, table, []interface{}{data, int64(1)}); err == nil {
+		t.Fatal("Row() error = nil, want error")
+	}
+	if sb.Len() != 0 {
+		t.Errorf("Row() wrote %d bytes before failing", sb.Len())
+	}
+}
+
+func Test_sqlFormatter_RowUnsupportedWarning(t *testing.T) {
+	var warnings []Warning
+	o := newDumpOption([]DumpOption{WithWarningHandler(func(w Warning) { warnings = append(warnings, w) })})
+	table := &TableMeta{Name: "t", Columns: []string{"id", "shape"}, DataTypes: []string{"INT", "GEOMETRY"}}
+	for i := 0; i < 2; i++ {
+		if err := o.formatter.Row(io.Discard, table, []interface{}{int64(i), []byte{0xff}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningUnsupportedType || warnings[0].Column != "shape" {
+		t.Errorf("warnings = %v", warnings)
+	}
+}
+
+func Test_sqlFormatter_TableDataBegin(t *testing.T) {
+	table := &TableMeta{Name: "t"}
+	banner := "-- ----------------------------\n-- Records of t\n-- ----------------------------\n"
+	tests := []struct {
+		name  string
+		table *TableMeta
+		opts  []DumpOption
+		want  string
+	}{
+		{name: "default", want: banner},
+		{name: "truncate", opts: []DumpOption{WithTruncateTable()}, want: banner + "TRUNCATE TABLE `t`;\n"},
+		{
+			name: "locks",
+			opts: []DumpOption{WithAddLocks(), WithDisableKeys()},
+			want: banner + "LOCK TABLES `t` WRITE;\n/*!40000 ALTER TABLE `t` DISABLE KEYS */;\n",
+		},
+		{
+			name:  "hostile name",
+			table: &TableMeta{Name: "a`b\nDROP TABLE x; --"},
+			opts:  []DumpOption{WithTruncateTable()},
+			want: "-- ----------------------------\n-- Records of a`b DROP TABLE x; --\n-- ----------------------------\n" +
+				"TRUNCATE TABLE `a``b\nDROP TABLE x; --`;\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			table := table
+			if tt.table != nil {
+				table = tt.table
+			}
+			var sb strings.Builder
+			if err := o.formatter.TableDataBegin(&sb, table); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("TableDataBegin() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_sqlFormatter_Header(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithAddDropDatabase(), WithHeaderTemplate(nil)})
+	o.createDatabaseSQL = "CREATE DATABASE `shop` /*!40100 DEFAULT CHARACTER SET utf8mb4 */"
+	var sb strings.Builder
+	if err := o.formatter.Header(&sb, &DumpMeta{Database: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\n\nDROP DATABASE IF EXISTS `shop`;\n" +
+		"CREATE DATABASE `shop` /*!40100 DEFAULT CHARACTER SET utf8mb4 */;\n" +
+		"USE `shop`;\n\n\n"
+	if sb.String() != want {
+		t.Errorf("Header() = %q, want %q", sb.String(), want)
+	}
+}
+
+func Test_sqlFormatter_HeaderTimeZone(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithTimeZone(time.UTC), WithHeaderTemplate(nil)})
+	var sb strings.Builder
+	if err := o.formatter.Header(&sb, &DumpMeta{Database: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\n\nSET TIME_ZONE='+00:00';\n\n"; sb.String() != want {
+		t.Errorf("Header() = %q, want %q", sb.String(), want)
+	}
+	if names := o.optionNames(); len(names) != 1 || names[0] != "time-zone=+00:00" {
+		t.Errorf("optionNames() = %v", names)
+	}
+}
+
+func Test_sqlFormatter_TableDataEnd(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithAddLocks(), WithDisableKeys()})
+	var sb strings.Builder
+	if err := o.formatter.TableDataEnd(&sb, &TableMeta{Name: "t"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "/*!40000 ALTER TABLE `t` ENABLE KEYS */;\nUNLOCK TABLES;\n\n\n"
+	if sb.String() != want {
+		t.Errorf("TableDataEnd() = %q, want %q", sb.String(), want)
+	}
+}
+
+func benchmarkRow() (*TableMeta, []interface{}) {
+	table := &TableMeta{
+		Name:      "orders",
+		Columns:   []string{"id", "user_id", "amount", "status", "note", "payload", "created_at"},
+		DataTypes: []string{"BIGINT", "UNSIGNED INT", "DECIMAL", "VARCHAR", "TEXT", "BLOB", "DATETIME"},
+	}
+	row := []interface{}{
+		int64(123456789),
+		int64(42),
+		[]byte("1999.99"),
+		[]byte("paid"),
+		[]byte(strings.Repeat("it's a note\n", 20)),
+		[]byte(strings.Repeat("\x00\x01\xfe\xff", 64)),
+		time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+	}
+	return table, row
+}
+
+func Benchmark_sqlFormatter_Row(b *testing.B) {
+	table, row := benchmarkRow()
+	o := newDumpOption(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := o.formatter.Row(io.Discard, table, row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_sqlFormatter_RowParallel(b *testing.B) {
+	table, row := benchmarkRow()
+	o := newDumpOption(nil)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := o.formatter.Row(io.Discard, table, row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func Benchmark_sqlFormatter_RowLargeBlob(b *testing.B) {
+	table := &TableMeta{Name: "files", Columns: []string{"id", "data"}, DataTypes: []string{"INT", "LONGBLOB"}}
+	row := []interface{}{int64(1), []byte(strings.Repeat("\x00\x01\xfe\xff", 1<<18))}
+	o := newDumpOption(nil)
+	b.ReportAllocs()
+	b.SetBytes(1 << 20)
+	for i := 0; i < b.N; i++ {
+		if err := o.formatter.Row(io.Discard, table, row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// WithGrants 在表之后导出对当前数据库有权限的账号的 CREATE USER 和 GRANT 语句,
+// MySQL 8.0 同时导出这些账号被授予的角色, 恢复后的环境不需要手动重建权限
+// 只在默认 SQL 格式中输出, 需要读取 mysql 库的权限
+func WithGrants() DumpOption {
+	return func(option *dumpOption) {
+		option.grants = true
+	}
+}
+
+// getGrantees 返回对当前数据库有库, 表或列权限的账号, 格式为 'user'@'host'
+func getGrantees(db queryer) ([]string, error) {
+	rows, err := db.Query("SELECT GRANTEE FROM information_schema.SCHEMA_PRIVILEGES WHERE TABLE_SCHEMA = DATABASE() " +
+		"UNION SELECT GRANTEE FROM information_schema.TABLE_PRIVILEGES WHERE TABLE_SCHEMA = DATABASE() " +
+		"UNION SELECT GRANTEE FROM information_schema.COLUMN_PRIVILEGES WHERE TABLE_SCHEMA = DATABASE()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grantees []string
+	for rows.Next() {
+		var grantee string
+		err = rows.Scan(&grantee)
+		if err != nil {
+			return nil, err
+		}
+		grantees = append(grantees, grantee)
+	}
+	sort.Strings(grantees)
+	return grantees, rows.Err()
+}
+
+// getGrantedRoles 返回授予 grantees 的 MySQL 8.0 角色, 格式与 grantees 相同
+func getGrantedRoles(db queryer, grantees []string) ([]string, error) {
+	granted := make(map[string]bool)
+	for _, grantee := range grantees {
+		granted[grantee] = true
+	}
+	rows, err := db.Query("SELECT FROM_USER, FROM_HOST, TO_USER, TO_HOST FROM mysql.role_edges")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	var roles []string
+	for rows.Next() {
+		var fromUser, fromHost, toUser, toHost string
+		err = rows.Scan(&fromUser, &fromHost, &toUser, &toHost)
+		if err != nil {
+			return nil, err
+		}
+		role := accountName(fromUser, fromHost)
+		if granted[accountName(toUser, toHost)] && !granted[role] && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles, rows.Err()
+}
+
+// accountName 返回 'user'@'host' 格式的账号名
+func accountName(user, host string) string {
+	return "'" + EscapeString(user) + "'@'" + EscapeString(host) + "'"
+}
+
+// getAccountGrants 返回账号的 CREATE USER 和 GRANT 语句
+// 不支持 SHOW CREATE USER 的旧版本只返回 GRANT 语句
+func getAccountGrants(ctx context.Context, conn *sql.Conn, account string) ([]string, error) {
+	var stmts []string
+	var createUser string
+	err := conn.QueryRowContext(ctx, "SHOW CREATE USER "+account).Scan(&createUser)
+	if err == nil {
+		if strings.HasPrefix(createUser, "CREATE USER ") && !strings.HasPrefix(createUser, "CREATE USER IF NOT EXISTS ") {
+			createUser = "CREATE USER IF NOT EXISTS " + strings.TrimPrefix(createUser, "CREATE USER ")
+		}
+		stmts = append(stmts, createUser)
+	} else {
+		log.Printf("[warn] [dump] show create user %s: %v\n", account, err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SHOW GRANTS FOR "+account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var grant string
+		err = rows.Scan(&grant)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, grant)
+	}
+	return stmts, rows.Err()
+}
+
+// writeGrants 导出账号和权限, 角色在用户之前导出
+func writeGrants(ctx context.Context, db *sql.DB, w io.Writer, o *dumpOption) error {
+	grantees, err := getGrantees(db)
+	if err != nil {
+		return err
+	}
+	var roles []string
+	if o.serverVersion.supportsRoles() {
+		roles, err = getGrantedRoles(db, grantees)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 会话变量只对当前连接生效
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// MySQL 8.0.17 开始可以将密码哈希输出为十六进制, 避免二进制内容无法恢复
+	_, _ = conn.ExecContext(ctx, "SET SESSION print_identified_with_as_hex = 1")
+
+	for _, account := range append(roles, grantees...) {
+		stmts, err := getAccountGrants(ctx, conn, account)
+		if err != nil {
+			return err
+		}
+		var sb strings.Builder
+		sb.WriteString(o.banner("Grants for " + commentName(account)))
+		for _, stmt := range stmts {
+			sb.WriteString(stmt + ";\n")
+		}
+		sb.WriteString(o.spacing("\n\n"))
+		_, err = io.WriteString(w, sb.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package mysqldump
+
+import "testing"
+
+func Test_accountName(t *testing.T) {
+	tests := []struct {
+		user, host string
+		want       string
+	}{
+		{"app", "%", "'app'@'%'"},
+		{"o'neil", "10.0.0.%", `'o\'neil'@'10.0.0.%'`},
+	}
+	for _, tt := range tests {
+		if got := accountName(tt.user, tt.host); got != tt.want {
+			t.Errorf("accountName(%q, %q) = %q, want %q", tt.user, tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package mysqldump
+
+import "strings"
+
+// WithSelectHints 在导出数据的 SELECT 后加上优化器提示或查询修饰符, 原样写入查询
+// 如 WithSelectHints("/*+ MAX_EXECUTION_TIME(600000) */", "SQL_NO_CACHE")
+// 优化器提示 /*+ ... */ 总是放在修饰符前面; SELECT 不支持 LOW_PRIORITY, 降低优先级可以使用
+// /*+ RESOURCE_GROUP(name) */ 或 WithSessionVars 设置 innodb 相关的会话变量
+func WithSelectHints(hints ...string) DumpOption {
+	return func(option *dumpOption) {
+		option.selectHints = append(option.selectHints, hints...)
+	}
+}
+
+// selectKeyword 返回数据查询开头的 SELECT 及提示
+func (o *dumpOption) selectKeyword() string {
+	if len(o.selectHints) == 0 {
+		return "SELECT"
+	}
+	var comments, modifiers []string
+	for _, hint := range o.selectHints {
+		hint = strings.TrimSpace(hint)
+		switch {
+		case hint == "":
+		case strings.HasPrefix(hint, "/*+"):
+			comments = append(comments, hint)
+		default:
+			modifiers = append(modifiers, hint)
+		}
+	}
+	return strings.Join(append(append([]string{"SELECT"}, comments...), modifiers...), " ")
+}
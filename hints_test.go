@@ -0,0 +1,27 @@
+package mysqldump
+
+import "testing"
+
+func Test_dumpOption_selectKeyword(t *testing.T) {
+	tests := []struct {
+		name  string
+		hints []string
+		want  string
+	}{
+		{name: "none", want: "SELECT"},
+		{name: "modifier", hints: []string{"SQL_NO_CACHE"}, want: "SELECT SQL_NO_CACHE"},
+		{
+			name:  "optimizer hint first",
+			hints: []string{"SQL_NO_CACHE", " /*+ MAX_EXECUTION_TIME(1000) */", ""},
+			want:  "SELECT /*+ MAX_EXECUTION_TIME(1000) */ SQL_NO_CACHE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption([]DumpOption{WithSelectHints(tt.hints...)})
+			if got := o.selectKeyword(); got != tt.want {
+				t.Errorf("selectKeyword() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
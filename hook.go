@@ -0,0 +1,26 @@
+package mysqldump
+
+import (
+	"context"
+	"io"
+)
+
+// Hooks 导出过程中的回调, w 为当前的输出, 写入的内容会出现在对应位置
+// 回调返回错误时中止导出, 并发导出时表的回调在不同 goroutine 中调用
+type Hooks struct {
+	// BeforeDump 在文件头之后, 第一个表之前调用, 断点续传时不调用
+	BeforeDump func(ctx context.Context, w io.Writer, meta *DumpMeta) error
+	// BeforeTable 在表结构之前调用, 断点续传中未完成的表不调用
+	BeforeTable func(ctx context.Context, w io.Writer, table *TableMeta) error
+	// AfterTable 在表数据之后调用
+	AfterTable func(ctx context.Context, w io.Writer, table *TableMeta, result TableResult) error
+	// AfterDump 在所有表之后, 文件尾之前调用
+	AfterDump func(ctx context.Context, w io.Writer, meta *DumpMeta) error
+}
+
+// WithHooks 设置导出过程中的回调
+func WithHooks(hooks Hooks) DumpOption {
+	return func(option *dumpOption) {
+		option.hooks = hooks
+	}
+}
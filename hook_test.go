@@ -0,0 +1,62 @@
+package mysqldump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithHooks(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	hooks := Hooks{
+		BeforeDump: func(ctx context.Context, w io.Writer, meta *DumpMeta) error {
+			_, err := io.WriteString(w, "-- before dump "+meta.Database+"\n")
+			return err
+		},
+		BeforeTable: func(ctx context.Context, w io.Writer, table *TableMeta) error {
+			_, err := io.WriteString(w, "-- before table "+table.Name+"\n")
+			return err
+		},
+		AfterTable: func(ctx context.Context, w io.Writer, table *TableMeta, result TableResult) error {
+			_, err := fmt.Fprintf(w, "-- after table %s rows %d\n", table.Name, result.Rows)
+			return err
+		},
+		AfterDump: func(ctx context.Context, w io.Writer, meta *DumpMeta) error {
+			_, err := io.WriteString(w, "-- after dump\n")
+			return err
+		},
+	}
+	out, err := fakeDump(db, WithAllTable(), WithData(), WithHooks(hooks))
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	// 回调的输出按顺序出现在对应位置
+	last := -1
+	for _, want := range []string{
+		"-- before dump test\n",
+		"-- before table a\n",
+		"CREATE TABLE IF NOT EXISTS `a`",
+		"INSERT INTO `a` VALUES (2);",
+		"-- after table a rows 2\n",
+		"-- before table b\n",
+		"-- after table b rows 2\n",
+		"-- after dump\n",
+		"-- Dump completed",
+	} {
+		i := strings.Index(out, want)
+		if i <= last {
+			t.Fatalf("Dump() output has %q at %d, after %d:\n%s", want, i, last, out)
+		}
+		last = i
+	}
+
+	// 回调返回错误时中止导出
+	errStop := errors.New("stop")
+	hooks = Hooks{BeforeTable: func(context.Context, io.Writer, *TableMeta) error { return errStop }}
+	if _, err = fakeDump(db, WithAllTable(), WithData(), WithHooks(hooks)); !errors.Is(err, errStop) {
+		t.Errorf("Dump() error = %v, want %v", err, errStop)
+	}
+}
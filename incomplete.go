@@ -0,0 +1,21 @@
+package mysqldump
+
+import (
+	"io"
+	"strings"
+)
+
+// incompleteMarker 导出失败或取消时写在输出末尾的注释, Validate 据此返回 ErrIncomplete
+const incompleteMarker = "-- DUMP INCOMPLETE"
+
+// writeIncomplete 在 SQL 格式的输出末尾写上 incompleteMarker 和错误, 自定义的 Formatter 不写
+// 之前的输出可能停在语句中间, 标记另起一行
+func (o *dumpOption) writeIncomplete(w io.Writer, err error) {
+	switch o.formatter.(type) {
+	case *sqlFormatter, *compatFormatter, *postgresFormatter, *sqliteFormatter:
+	default:
+		return
+	}
+	reason := strings.Join(strings.Fields(err.Error()), " ")
+	_, _ = io.WriteString(w, "\n"+incompleteMarker+": "+reason+"\n")
+}
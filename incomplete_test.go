@@ -0,0 +1,28 @@
+package mysqldump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_writeIncomplete(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []DumpOption
+		want string
+	}{
+		{name: "sql", want: "\n-- DUMP INCOMPLETE: read: connection reset\n"},
+		{name: "compat", opts: []DumpOption{WithMySQLDumpCompat()}, want: "\n-- DUMP INCOMPLETE: read: connection reset\n"},
+		{name: "custom formatter", opts: []DumpOption{WithFormatter(NewCSVFormatter())}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			newDumpOption(tt.opts).writeIncomplete(&sb, errors.New("read:\nconnection reset"))
+			if sb.String() != tt.want {
+				t.Errorf("writeIncomplete() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package mysqldump
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// IncrementalState 增量导出状态, 记录每个表上次导出的水位
+type IncrementalState struct {
+	Tables map[string]Watermark `json:"tables"`
+	// 上次导出时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Watermark 表的水位列和上次导出的最大值
+type Watermark struct {
+	Column string `json:"column"`
+	Value  string `json:"value"`
+}
+
+type incrementalOption struct {
+	stateFile string
+	// 每个表的水位列, 如 updated_at 或自增主键
+	columns map[string]string
+	state   IncrementalState
+	// 本次导出的水位, 表导出成功后由 finish 写入 state
+	pending map[string]Watermark
+}
+
+// WithIncremental 增量导出, columns 指定每个表的水位列 (如 updated_at 或自增主键)
+// 只导出水位大于上次导出值的行, 导出成功后更新 stateFile
+// 增量数据以 REPLACE INTO 导出, DialectPostgres 时为 ON CONFLICT (主键) DO UPDATE, 表需要有主键; 没有指定水位列的表只导出表结构
+func WithIncremental(stateFile string, columns map[string]string) DumpOption {
+	return func(option *dumpOption) {
+		option.incremental = &incrementalOption{
+			stateFile: stateFile,
+			columns:   columns,
+		}
+	}
+}
+
+// LoadIncrementalState 读取增量导出状态文件, 文件不存在时返回空状态
+func LoadIncrementalState(name string) (IncrementalState, error) {
+	state := IncrementalState{Tables: make(map[string]Watermark)}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return state, fmt.Errorf("incremental state %s: %v", name, err)
+	}
+	if state.Tables == nil {
+		state.Tables = make(map[string]Watermark)
+	}
+	return state, nil
+}
+
+// saveIncrementalState 先写临时文件再重命名, 避免中途失败破坏状态文件
+func saveIncrementalState(name string, state IncrementalState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// incrementalWhere 返回表的增量条件, 并记录本次导出的水位
+// 先取当前最大值作为上界, 避免导出过程中新写入的行在下次被遗漏
+func incrementalWhere(db queryer, inc *incrementalOption, table string) (string, error) {
+	column := inc.columns[table]
+	var max interface{}
+	err := db.QueryRow(fmt.Sprintf("SELECT MAX(%s) FROM %s", QuoteIdentifier(column), QuoteIdentifier(table))).Scan(&max)
+	if err != nil {
+		return "", err
+	}
+	return inc.where(table, max), nil
+}
+
+// where 根据本次的最大值 max 返回增量条件并记录本次的水位, max 为驱动返回的原始值, 空表时为 nil
+func (inc *incrementalOption) where(table string, max interface{}) string {
+	column := inc.columns[table]
+	last, ok := inc.state.Tables[table]
+	if ok && last.Column != column {
+		// 水位列变化, 重新全量导出
+		ok = false
+	}
+
+	value, valid := watermarkValue(max)
+	if !valid {
+		// 空表, 保留上次的水位
+		return "1 = 0"
+	}
+
+	if inc.pending == nil {
+		inc.pending = make(map[string]Watermark)
+	}
+	inc.pending[table] = Watermark{Column: column, Value: value}
+	where := fmt.Sprintf("%s <= '%s'", QuoteIdentifier(column), EscapeString(value))
+	if ok {
+		where = fmt.Sprintf("%s > '%s' AND ", QuoteIdentifier(column), EscapeString(last.Value)) + where
+	}
+	return where
+}
+
+// finish 将本次导出成功的表的水位写入 state, 失败跳过的表和断点续传中之前完成的表保留上次的水位, 下次重新导出
+func (inc *incrementalOption) finish(tables []TableResult) {
+	for _, table := range tables {
+		if w, ok := inc.pending[table.Name]; ok {
+			inc.state.Tables[table.Name] = w
+		}
+	}
+}
+
+// watermarkValue 水位的 SQL 文本, NULL 时返回 false
+// parseTime 时日期列为 time.Time, 驱动已按会话时区 (DSN 的 loc 或 WithTimeZone) 解析, 按原样格式化为 MySQL 的日期文本,
+// 不能使用 time.Time 默认的 RFC3339, 带 T 和时区后缀的文本与日期列比较时结果取决于服务端版本
+func watermarkValue(max interface{}) (string, bool) {
+	switch v := max.(type) {
+	case nil:
+		return "", false
+	case []byte:
+		return string(v), true
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999"), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
@@ -0,0 +1,151 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_incrementalOption_where(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	inc := &incrementalOption{
+		columns: map[string]string{"orders": "updated_at", "users": "id", "logs": "id"},
+		state: IncrementalState{Tables: map[string]Watermark{
+			"orders": {Column: "updated_at", Value: "2024-01-01 00:00:00"},
+			"users":  {Column: "created_at", Value: "2024-01-01 00:00:00"},
+			"logs":   {Column: "id", Value: "7"},
+		}},
+	}
+	tests := []struct {
+		table     string
+		max       interface{}
+		want      string
+		watermark string
+	}{
+		{
+			// parseTime 时为 time.Time, 按会话时区的日期文本输出, 不是 RFC3339
+			table:     "orders",
+			max:       time.Date(2024, 1, 2, 3, 4, 5, 120000000, loc),
+			want:      "`updated_at` > '2024-01-01 00:00:00' AND `updated_at` <= '2024-01-02 03:04:05.12'",
+			watermark: "2024-01-02 03:04:05.12",
+		},
+		{
+			// 水位列变化, 重新全量导出
+			table:     "users",
+			max:       []byte("42"),
+			want:      "`id` <= '42'",
+			watermark: "42",
+		},
+		{
+			// 空表保留上次的水位
+			table:     "logs",
+			max:       nil,
+			want:      "1 = 0",
+			watermark: "7",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if got := inc.where(tt.table, tt.max); got != tt.want {
+				t.Errorf("where() = %q, want %q", got, tt.want)
+			}
+			w, ok := inc.pending[tt.table]
+			if !ok {
+				w = inc.state.Tables[tt.table]
+			}
+			if w.Value != tt.watermark {
+				t.Errorf("watermark = %q, want %q", w.Value, tt.watermark)
+			}
+		})
+	}
+}
+
+func Test_watermarkValue(t *testing.T) {
+	tests := []struct {
+		max   interface{}
+		want  string
+		valid bool
+	}{
+		{nil, "", false},
+		{[]byte("2024-01-02"), "2024-01-02", true},
+		{int64(10), "10", true},
+		{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "2024-01-02 03:04:05", true},
+	}
+	for _, tt := range tests {
+		got, valid := watermarkValue(tt.max)
+		if got != tt.want || valid != tt.valid {
+			t.Errorf("watermarkValue(%v) = %q, %v, want %q, %v", tt.max, got, valid, tt.want, tt.valid)
+		}
+	}
+}
+
+func TestIncrementalState_saveAndLoad(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadIncrementalState(name)
+	if err != nil || len(state.Tables) != 0 {
+		t.Fatalf("LoadIncrementalState() = %+v, %v, want empty state", state, err)
+	}
+	state.Tables["orders"] = Watermark{Column: "updated_at", Value: "2024-01-02 03:04:05"}
+	if err = saveIncrementalState(name, state); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadIncrementalState(name)
+	if err != nil || got.Tables["orders"] != state.Tables["orders"] {
+		t.Errorf("LoadIncrementalState() = %+v, %v", got, err)
+	}
+}
+
+func Test_incrementalWhere(t *testing.T) {
+	db, f := newFakeDB(t)
+	// parseTime 时驱动返回 time.Time
+	f.on("SELECT MAX(`updated_at`) FROM `orders`", []string{"max DATETIME"},
+		[]driver.Value{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)})
+	inc := &incrementalOption{
+		columns: map[string]string{"orders": "updated_at"},
+		state:   IncrementalState{Tables: map[string]Watermark{"orders": {Column: "updated_at", Value: "2024-01-01 00:00:00"}}},
+	}
+	where, err := incrementalWhere(db, inc, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "`updated_at` > '2024-01-01 00:00:00' AND `updated_at` <= '2024-01-02 03:04:05'"; where != want {
+		t.Errorf("incrementalWhere() = %q, want %q", where, want)
+	}
+}
+
+func Test_incrementalOption_finish(t *testing.T) {
+	inc := &incrementalOption{
+		state:   IncrementalState{Tables: map[string]Watermark{"a": {Column: "id", Value: "1"}, "b": {Column: "id", Value: "1"}}},
+		pending: map[string]Watermark{"a": {Column: "id", Value: "5"}, "b": {Column: "id", Value: "5"}},
+	}
+	// b 导出失败, 不在结果中, 保留上次的水位
+	inc.finish([]TableResult{{Name: "a"}})
+	if inc.state.Tables["a"].Value != "5" || inc.state.Tables["b"].Value != "1" {
+		t.Errorf("state = %+v", inc.state.Tables)
+	}
+}
+
+// errWriter 总是写出失败
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWithIncremental_writeFailure(t *testing.T) {
+	db, f := newFakeDumpDB(t)
+	f.on("SELECT MAX(`id`)", []string{"max INT"}, []driver.Value{int64(2)})
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	o := newDumpOption([]DumpOption{WithTables("a"), WithData(), WithWriter(errWriter{}),
+		WithIncremental(stateFile, map[string]string{"a": "id"})})
+	o.sharedPool = db
+	if err := runDump(context.Background(), "root@tcp(127.0.0.1:1)/test", o); err == nil {
+		t.Fatal("runDump() error = nil, want write error")
+	}
+	// 写出失败时不更新水位
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		t.Errorf("state file written after a failed dump, stat error = %v", err)
+	}
+}
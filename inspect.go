@@ -0,0 +1,144 @@
+package mysqldump
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// DumpSummary ParseDump 返回的导出文件概要
+type DumpSummary struct {
+	// USE 和 CREATE DATABASE 中的数据库
+	Databases []string
+	// SET NAMES 设置的字符集
+	Charset string
+	// 按第一次出现的顺序记录的表和视图
+	Tables      []DumpTableSummary
+	HasData     bool
+	HasViews    bool
+	HasTriggers bool
+	// 存储过程和函数
+	HasRoutines bool
+	HasEvents   bool
+	Statements  int64
+	Bytes       int64
+}
+
+// DumpTableSummary 导出文件中的一个表
+type DumpTableSummary struct {
+	Name string
+	View bool
+	// 是否有 CREATE TABLE 或 CREATE VIEW
+	HasSchema bool
+	// INSERT 的行数, LOAD DATA 的行数无法统计
+	Rows    int64
+	HasData bool
+}
+
+// ParseDump 读取导出文件并返回概要, 不执行任何语句
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func ParseDump(r io.Reader) (*DumpSummary, error) {
+	summary := &DumpSummary{}
+	index := make(map[string]int)
+	table := func(name string) *DumpTableSummary {
+		i, ok := index[name]
+		if !ok {
+			i = len(summary.Tables)
+			index[name] = i
+			summary.Tables = append(summary.Tables, DumpTableSummary{Name: name})
+		}
+		return &summary.Tables[i]
+	}
+	addDatabase := func(name string) {
+		for _, db := range summary.Databases {
+			if db == name {
+				return
+			}
+		}
+		summary.Databases = append(summary.Databases, name)
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		stmt, err := readStatement(br)
+		if err != nil && err != io.EOF {
+			return summary, err
+		}
+		summary.Bytes += int64(len(stmt))
+		tokens := statementTokens(stmt)
+		// 官方 mysqldump 的 DELIMITER ;; 会在语句开头留下多余的 ;
+		for len(tokens) > 0 && tokens[0].text == ";" {
+			tokens = tokens[1:]
+		}
+		if len(tokens) > 0 {
+			summary.Statements++
+			kind, name := classifyStatement(stmt)
+			switch {
+			case tokens[0].isKeyword("USE") && len(tokens) > 1:
+				if db, ok := tokens[1].identName(); ok {
+					addDatabase(db)
+				}
+			case tokens[0].isKeyword("SET") && len(tokens) > 2 && tokens[1].isKeyword("NAMES") && summary.Charset == "":
+				summary.Charset, _ = tokens[2].identName()
+				if tokens[2].kind == tokenString {
+					summary.Charset = unquoteString(tokens[2].text)
+				}
+			case tokens[0].isKeyword("CREATE"):
+				switch createObjectKind(tokens) {
+				case "DATABASE", "SCHEMA":
+					if db := createDatabaseName(tokens); db != "" {
+						addDatabase(db)
+					}
+				case "TABLE":
+					table(name).HasSchema = true
+				case "VIEW":
+					t := table(name)
+					t.HasSchema, t.View = true, true
+					summary.HasViews = true
+				case "TRIGGER":
+					summary.HasTriggers = true
+				case "PROCEDURE", "FUNCTION":
+					summary.HasRoutines = true
+				case "EVENT":
+					summary.HasEvents = true
+				}
+			case kind == stmtData && name != "" && !tokens[0].isKeyword("LOCK", "ALTER", "TRUNCATE"):
+				t := table(name)
+				t.HasData = true
+				t.Rows += countInsertRows(stmt)
+				summary.HasData = true
+			}
+		}
+		if err == io.EOF {
+			return summary, nil
+		}
+	}
+}
+
+// createObjectKind 返回 CREATE 语句创建的对象类型, 跳过 OR REPLACE, DEFINER 等修饰
+func createObjectKind(tokens []sqlToken) string {
+	for _, t := range tokens[1:] {
+		if t.isKeyword("DATABASE", "SCHEMA", "TABLE", "VIEW", "TRIGGER", "PROCEDURE", "FUNCTION", "EVENT", "SEQUENCE", "INDEX", "USER", "ROLE") {
+			return strings.ToUpper(t.text)
+		}
+	}
+	return ""
+}
+
+// createDatabaseName 返回 CREATE DATABASE [IF NOT EXISTS] 的数据库名
+func createDatabaseName(tokens []sqlToken) string {
+	for i, t := range tokens {
+		if !t.isKeyword("DATABASE", "SCHEMA") {
+			continue
+		}
+		for _, next := range tokens[i+1:] {
+			if next.isKeyword("IF", "NOT", "EXISTS") {
+				continue
+			}
+			name, _ := next.identName()
+			return name
+		}
+	}
+	return ""
+}
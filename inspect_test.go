@@ -0,0 +1,42 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDump(t *testing.T) {
+	dump := "/*!40101 SET NAMES utf8mb4 */;\n" +
+		"CREATE DATABASE /*!32312 IF NOT EXISTS*/ `shop` /*!40100 DEFAULT CHARACTER SET utf8mb4 */;\nUSE `shop`;\n" +
+		"CREATE TABLE `orders` (`id` int);\n" +
+		"LOCK TABLES `orders` WRITE;\nINSERT INTO `orders` VALUES (1),(2);\nINSERT INTO `orders` VALUES (3);\nUNLOCK TABLES;\n" +
+		"CREATE TABLE `empty` (`id` int);\n" +
+		"CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS select 1;\n" +
+		"DELIMITER ;;\n/*!50003 CREATE*/ /*!50017 DEFINER=`root`@`localhost`*/ /*!50003 TRIGGER `trg` BEFORE INSERT ON `orders` FOR EACH ROW SET NEW.id = NEW.id */;;\nDELIMITER ;\n"
+	summary, err := ParseDump(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Databases) != 1 || summary.Databases[0] != "shop" || summary.Charset != "utf8mb4" {
+		t.Errorf("databases=%v charset=%q", summary.Databases, summary.Charset)
+	}
+	if !summary.HasData || !summary.HasViews || !summary.HasTriggers || summary.HasRoutines {
+		t.Errorf("data=%v views=%v triggers=%v routines=%v", summary.HasData, summary.HasViews, summary.HasTriggers, summary.HasRoutines)
+	}
+	want := []DumpTableSummary{
+		{Name: "orders", HasSchema: true, Rows: 3, HasData: true},
+		{Name: "empty", HasSchema: true},
+		{Name: "v", View: true, HasSchema: true},
+	}
+	if len(summary.Tables) != len(want) {
+		t.Fatalf("tables = %+v", summary.Tables)
+	}
+	for i := range want {
+		if summary.Tables[i] != want[i] {
+			t.Errorf("table %d = %+v, want %+v", i, summary.Tables[i], want[i])
+		}
+	}
+	if summary.Bytes != int64(len(dump)) {
+		t.Errorf("bytes = %d, want %d", summary.Bytes, len(dump))
+	}
+}
@@ -0,0 +1,101 @@
+package mysqldump
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// JSONLFormatter 每个表导出为 JSON Lines, 每行一个对象, 键为列名, 顺序与表的列相同
+// 整数和浮点数为数字, DECIMAL 为字符串以保留精度, JSON 列原样嵌入,
+// 二进制类型为 base64 字符串, 日期时间为字符串, NULL 为 null
+type JSONLFormatter struct{}
+
+// WithJSONL 每个表导出为 dir 下的 <表名>.jsonl 文件
+func WithJSONL(dir string) DumpOption {
+	return func(option *dumpOption) {
+		option.formatter = &JSONLFormatter{}
+		option.tableWriter = dirTableWriter(dir, ".jsonl")
+	}
+}
+
+func (f *JSONLFormatter) Header(io.Writer, *DumpMeta) error { return nil }
+
+func (f *JSONLFormatter) TableSchema(io.Writer, *TableMeta) error { return nil }
+
+func (f *JSONLFormatter) TableDataBegin(io.Writer, *TableMeta) error { return nil }
+
+func (f *JSONLFormatter) Row(w io.Writer, table *TableMeta, row []interface{}) error {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, col := range row {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		key, _ := json.Marshal(table.Columns[i])
+		sb.Write(key)
+		sb.WriteByte(':')
+		value, err := jsonValue(col, table.DataTypes[i])
+		if err != nil {
+			return err
+		}
+		sb.Write(value)
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (f *JSONLFormatter) TableDataEnd(io.Writer, *TableMeta) error { return nil }
+
+func (f *JSONLFormatter) Footer(io.Writer, *DumpMeta) error { return nil }
+
+// jsonValue 将列值转换为 JSON
+func jsonValue(col interface{}, dataType string) ([]byte, error) {
+	if col == nil {
+		return []byte("null"), nil
+	}
+	unsigned := strings.Contains(dataType, "UNSIGNED")
+	dataType = strings.TrimSpace(strings.Replace(dataType, "UNSIGNED", "", -1))
+	switch v := col.(type) {
+	case int64, uint64:
+		value, err := formatInteger(v, unsigned)
+		return []byte(value), err
+	case float32:
+		return json.Marshal(v)
+	case float64:
+		return json.Marshal(v)
+	case bool:
+		return json.Marshal(v)
+	case time.Time:
+		if dataType == "DATE" {
+			return json.Marshal(v.Format("2006-01-02"))
+		}
+		// DATETIME(6) 和 TIMESTAMP(6) 保留微秒, 没有小数部分时不输出
+		return json.Marshal(v.Format("2006-01-02 15:04:05.999999"))
+	case []byte:
+		switch dataType {
+		case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR", "FLOAT", "DOUBLE", "JSON":
+			if json.Valid(v) {
+				return v, nil
+			}
+		case "BIT", "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+			return json.Marshal(base64.StdEncoding.EncodeToString(v))
+		case "VECTOR":
+			// 数组的文本形式是合法的 JSON
+			if text, ok := vectorText(v); ok {
+				return []byte(text), nil
+			}
+			return json.Marshal(base64.StdEncoding.EncodeToString(v))
+		}
+		return json.Marshal(string(v))
+	default:
+		value, _, err := textValue(col, dataType)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	}
+}
@@ -0,0 +1,53 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLFormatter(t *testing.T) {
+	table := &TableMeta{
+		Name:      "t",
+		Columns:   []string{"id", "price", "name", "attrs", "data", "created", "score"},
+		DataTypes: []string{"BIGINT UNSIGNED", "DECIMAL", "VARCHAR", "JSON", "BLOB", "DATETIME", "DOUBLE"},
+	}
+	tests := []struct {
+		name string
+		row  []interface{}
+		want string
+	}{
+		{
+			name: "types",
+			row:  []interface{}{uint64(18446744073709551615), []byte("12.50"), []byte(`a"b`), []byte(`{"k":[1,2]}`), []byte{0x01, 0x02}, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), float64(1.5)},
+			want: `{"id":18446744073709551615,"price":"12.50","name":"a\"b","attrs":{"k":[1,2]},"data":"AQI=","created":"2024-01-02 03:04:05","score":1.5}` + "\n",
+		},
+		{
+			name: "fractional seconds",
+			row:  []interface{}{int64(1), nil, nil, nil, nil, time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC), nil},
+			want: `{"id":1,"price":null,"name":null,"attrs":null,"data":null,"created":"2024-01-02 03:04:05.123456","score":null}` + "\n",
+		},
+		{
+			name: "unsigned as int64",
+			row:  []interface{}{int64(-2), nil, nil, nil, nil, nil, nil},
+			want: `{"id":18446744073709551614,"price":null,"name":null,"attrs":null,"data":null,"created":null,"score":null}` + "\n",
+		},
+		{
+			name: "null",
+			row:  []interface{}{int64(1), nil, nil, nil, nil, nil, nil},
+			want: `{"id":1,"price":null,"name":null,"attrs":null,"data":null,"created":null,"score":null}` + "\n",
+		},
+	}
+	f := &JSONLFormatter{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := f.Row(&sb, table, tt.row); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("Row() = %s, want %s", sb.String(), tt.want)
+			}
+		})
+	}
+}
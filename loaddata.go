@@ -0,0 +1,324 @@
+package mysqldump
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// WithLoadData 恢复时将连续的 INSERT 转换为 LOAD DATA LOCAL INFILE 导入, 大表比逐条 INSERT 快很多
+// 服务端未开启 local_infile 时使用 INSERT, 无法解析的 INSERT 也使用原语句执行
+func WithLoadData() SourceOption {
+	return func(o *sourceOption) {
+		o.loadData = true
+	}
+}
+
+// insertRows INSERT 语句解析后的数据
+type insertRows struct {
+	// INSERT, INSERT IGNORE 或 REPLACE
+	mode    string
+	table   string
+	columns []string
+	// 每行已转换为 LOAD DATA 的格式, 以换行结尾
+	lines []string
+}
+
+// key 相同的 INSERT 可以写到同一个 LOAD DATA
+func (r *insertRows) key() string {
+	return r.mode + "\x00" + r.table + "\x00" + strings.Join(r.columns, "\x00")
+}
+
+// parseInsertRows 解析 INSERT [IGNORE] INTO / REPLACE INTO `t` [(cols)] VALUES (...),(...)
+// 值只能是字符串, 数字, NULL, TRUE/FALSE 和十六进制, 其他情况返回 false
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func parseInsertRows(stmt string) (*insertRows, bool) {
+	tokens := tokenizeSQL(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+	// 有意义的 token 下标
+	var idx []int
+	for i, t := range tokens {
+		if t.kind == tokenComment {
+			return nil, false
+		}
+		if t.kind != tokenSpace {
+			idx = append(idx, i)
+		}
+	}
+	k := 0
+	next := func() (sqlToken, bool) {
+		if k >= len(idx) {
+			return sqlToken{}, false
+		}
+		t := tokens[idx[k]]
+		k++
+		return t, true
+	}
+
+	r := &insertRows{}
+	t, _ := next()
+	switch {
+	case t.isKeyword("INSERT"):
+		r.mode = "INSERT"
+		t, _ = next()
+		if t.isKeyword("IGNORE") {
+			r.mode = "IGNORE"
+			t, _ = next()
+		}
+	case t.isKeyword("REPLACE"):
+		r.mode = "REPLACE"
+		t, _ = next()
+	default:
+		return nil, false
+	}
+	if !t.isKeyword("INTO") {
+		return nil, false
+	}
+	t, _ = next()
+	if t.kind != tokenIdent {
+		return nil, false
+	}
+	r.table, _ = t.identName()
+
+	t, _ = next()
+	if t.text == "(" {
+		for {
+			t, _ = next()
+			name, ok := t.identName()
+			if !ok {
+				return nil, false
+			}
+			r.columns = append(r.columns, name)
+			t, _ = next()
+			if t.text == ")" {
+				break
+			}
+			if t.text != "," {
+				return nil, false
+			}
+		}
+		t, _ = next()
+	}
+	if !t.isKeyword("VALUES") {
+		return nil, false
+	}
+
+	for {
+		t, ok := next()
+		if !ok || t.text != "(" {
+			return nil, false
+		}
+		var line strings.Builder
+		for field := 0; ; field++ {
+			// 一个值由一个或多个相邻的 token 组成, 如 -1, 1.5, X'0A', CAST('{}' AS JSON)
+			start := k
+			depth := 0
+			for k < len(idx) {
+				text := tokens[idx[k]].text
+				if depth == 0 && (text == "," || text == ")") {
+					break
+				}
+				if text == "(" {
+					depth++
+				} else if text == ")" {
+					depth--
+				}
+				k++
+			}
+			if k >= len(idx) || start == k {
+				return nil, false
+			}
+			var valueTokens []sqlToken
+			for _, i := range idx[start:k] {
+				valueTokens = append(valueTokens, tokens[i])
+			}
+			value, ok := loadDataValue(valueTokens)
+			if !ok {
+				return nil, false
+			}
+			if field > 0 {
+				line.WriteByte('\t')
+			}
+			line.WriteString(value)
+			t, _ = next()
+			if t.text == ")" {
+				break
+			}
+		}
+		line.WriteByte('\n')
+		r.lines = append(r.lines, line.String())
+
+		t, ok = next()
+		if !ok {
+			return r, true
+		}
+		if t.text != "," {
+			return nil, false
+		}
+	}
+}
+
+// loadDataValue 将 INSERT 中的一个值转换为 LOAD DATA 默认格式的字段
+func loadDataValue(tokens []sqlToken) (string, bool) {
+	if len(tokens) == 1 {
+		t := tokens[0]
+		switch {
+		case t.kind == tokenString:
+			return escapeTabValue(unquoteString(t.text)), true
+		case t.isKeyword("NULL"):
+			return `\N`, true
+		case t.isKeyword("TRUE"):
+			return "1", true
+		case t.isKeyword("FALSE"):
+			return "0", true
+		case t.kind == tokenWord && (strings.HasPrefix(t.text, "0x") || strings.HasPrefix(t.text, "0X")):
+			b, err := hex.DecodeString(t.text[2:])
+			if err != nil {
+				return "", false
+			}
+			return escapeTabValue(string(b)), true
+		}
+	}
+	// _binary 'abc', _utf8mb4 'abc'
+	if len(tokens) == 2 && tokens[0].kind == tokenWord && strings.HasPrefix(tokens[0].text, "_") && tokens[1].kind == tokenString {
+		return escapeTabValue(unquoteString(tokens[1].text)), true
+	}
+	// CAST('{}' AS JSON)
+	if len(tokens) == 6 && tokens[0].isKeyword("CAST") && tokens[1].text == "(" && tokens[2].kind == tokenString &&
+		tokens[3].isKeyword("AS") && tokens[4].isKeyword("JSON") && tokens[5].text == ")" {
+		return escapeTabValue(unquoteString(tokens[2].text)), true
+	}
+	// X'0A'
+	if len(tokens) == 2 && tokens[0].isKeyword("X") && tokens[1].kind == tokenString {
+		b, err := hex.DecodeString(unquoteString(tokens[1].text))
+		if err != nil {
+			return "", false
+		}
+		return escapeTabValue(string(b)), true
+	}
+	// 数字, 如 -1, 1.5, 1e10
+	var sb strings.Builder
+	for _, t := range tokens {
+		for _, c := range []byte(t.text) {
+			if !(c >= '0' && c <= '9' || c == '.' || c == '-' || c == '+' || c == 'e' || c == 'E') {
+				return "", false
+			}
+		}
+		sb.WriteString(t.text)
+	}
+	return sb.String(), true
+}
+
+// loadDataSeq 区分同时注册的 reader
+var loadDataSeq int64
+
+// loadDataLoader 将连续的 INSERT 写到同一个 LOAD DATA LOCAL INFILE 中
+type loadDataLoader struct {
+	// 与其他语句使用同一个连接, LOAD DATA 在恢复的事务中
+	db       execer
+	database string
+	debug    bool
+
+	key  string
+	name string
+	pw   *io.PipeWriter
+	buf  *bufio.Writer
+	done chan error
+}
+
+// newLoadDataLoader 服务端未开启 local_infile 时返回 nil
+func newLoadDataLoader(db execer, database string, debug bool) *loadDataLoader {
+	var enabled int
+	err := db.QueryRowContext(context.Background(), "SELECT @@GLOBAL.local_infile").Scan(&enabled)
+	if err != nil || enabled != 1 {
+		log.Printf("[warn] local_infile is disabled, use INSERT instead of LOAD DATA\n")
+		return nil
+	}
+	return &loadDataLoader{db: db, database: database, debug: debug}
+}
+
+// add 写入 INSERT 的数据, 不是可以转换的 INSERT 时返回 false
+func (l *loadDataLoader) add(stmt string) (bool, error) {
+	rows, ok := parseInsertRows(stmt)
+	if !ok {
+		return false, nil
+	}
+	if rows.key() != l.key {
+		err := l.flush()
+		if err != nil {
+			return true, err
+		}
+		l.start(rows)
+	}
+	for _, line := range rows.lines {
+		_, err := l.buf.WriteString(line)
+		if err != nil {
+			// LOAD DATA 已失败, 错误由 flush 返回
+			return true, l.flush()
+		}
+	}
+	return true, nil
+}
+
+// start 开始一个 LOAD DATA, 数据通过 pipe 写入
+func (l *loadDataLoader) start(rows *insertRows) {
+	pr, pw := io.Pipe()
+	l.key = rows.key()
+	l.name = fmt.Sprintf("mysqldump-load-%d", atomic.AddInt64(&loadDataSeq, 1))
+	l.pw = pw
+	l.buf = bufio.NewWriterSize(pw, 64*1024)
+	l.done = make(chan error, 1)
+	mysql.RegisterReaderHandler(l.name, func() io.Reader { return pr })
+
+	query := "LOAD DATA LOCAL INFILE 'Reader::" + l.name + "'"
+	switch rows.mode {
+	case "IGNORE":
+		query += " IGNORE"
+	case "REPLACE":
+		query += " REPLACE"
+	}
+	query += " INTO TABLE " + QuoteIdentifier(l.database) + "." + QuoteIdentifier(rows.table) + " CHARACTER SET binary"
+	if len(rows.columns) > 0 {
+		quoted := make([]string, len(rows.columns))
+		for i, column := range rows.columns {
+			quoted[i] = QuoteIdentifier(column)
+		}
+		query += " (" + strings.Join(quoted, ",") + ")"
+	}
+	if l.debug {
+		log.Printf("[debug] [query]\n%s\n", query)
+	}
+
+	go func() {
+		_, err := l.db.ExecContext(context.Background(), query)
+		if err != nil {
+			err = fmt.Errorf("%s: %v", query, err)
+		}
+		// LOAD DATA 失败时让写入方返回错误
+		pr.CloseWithError(err)
+		l.done <- err
+	}()
+}
+
+// flush 结束当前的 LOAD DATA 并等待执行完成
+func (l *loadDataLoader) flush() error {
+	if l.pw == nil {
+		return nil
+	}
+	err := l.buf.Flush()
+	_ = l.pw.Close()
+	lerr := <-l.done
+	mysql.DeregisterReaderHandler(l.name)
+	l.key, l.pw, l.buf = "", nil, nil
+	if lerr != nil {
+		return lerr
+	}
+	return err
+}
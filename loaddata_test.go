@@ -0,0 +1,55 @@
+package mysqldump
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseInsertRows(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want *insertRows
+	}{
+		{
+			name: "values",
+			stmt: "INSERT INTO `t` VALUES (1,'a\\tb','it''s',NULL,-1.5,0x0A5C);",
+			want: &insertRows{mode: "INSERT", table: "t", lines: []string{"1\ta\\tb\tit's\t\\N\t-1.5\t\\n\\\\\n"}},
+		},
+		{
+			name: "multi rows with columns",
+			stmt: "INSERT IGNORE INTO `t` (`id`, `name`) VALUES (1, 'a'), (2, 'b,c')",
+			want: &insertRows{mode: "IGNORE", table: "t", columns: []string{"id", "name"}, lines: []string{"1\ta\n", "2\tb,c\n"}},
+		},
+		{
+			name: "replace",
+			stmt: "REPLACE INTO `t` VALUES (true,X'41')",
+			want: &insertRows{mode: "REPLACE", table: "t", lines: []string{"1\tA\n"}},
+		},
+		{
+			name: "binary string",
+			stmt: "INSERT INTO `t` VALUES (1,_binary 'a\\0\\'')",
+			want: &insertRows{mode: "INSERT", table: "t", lines: []string{"1\ta\\0'\n"}},
+		},
+		{
+			name: "json cast",
+			stmt: "INSERT INTO `t` VALUES (1,CAST('{\"a\": \"it\\'s\"}' AS JSON),2)",
+			want: &insertRows{mode: "INSERT", table: "t", lines: []string{"1\t{\"a\": \"it's\"}\t2\n"}},
+		},
+		{name: "function", stmt: "INSERT INTO `t` VALUES (NOW())"},
+		{name: "select", stmt: "INSERT INTO `t` SELECT * FROM `s`"},
+		{name: "not insert", stmt: "CREATE TABLE `t` (`id` int)"},
+		{name: "truncated", stmt: "INSERT INTO `t` VALUES (1,"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseInsertRows(tt.stmt)
+			if ok != (tt.want != nil) {
+				t.Fatalf("parseInsertRows() ok = %v, want %v", ok, tt.want != nil)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseInsertRows() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// transactionalEngines 支持一致性快照的存储引擎
+var transactionalEngines = map[string]bool{
+	"innodb":  true,
+	"xtradb":  true,
+	"tokudb":  true,
+	"rocksdb": true,
+}
+
+// WithLockNonTransactionalTables 导出 MyISAM 等非事务引擎表的数据时, 使用 LOCK TABLES ... READ LOCAL 锁住该表,
+// 一致性快照不能防止这些表在导出过程中被修改. 锁在单独的连接上获取, 数据也在该连接上读取, 表的数据导出后释放
+func WithLockNonTransactionalTables() DumpOption {
+	return func(option *dumpOption) {
+		option.lockNonTransactional = true
+	}
+}
+
+// getNonTransactionalTables 返回非事务引擎的表, 视图没有引擎不包含在内
+func getNonTransactionalTables(db queryer) (map[string]bool, error) {
+	status, err := getTableStatus(db)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]bool)
+	for name, st := range status {
+		if st.engine != "" && !transactionalEngines[strings.ToLower(st.engine)] {
+			tables[name] = true
+		}
+	}
+	return tables, nil
+}
+
+// lockTableRead 从连接池取一个连接并对表加 READ LOCAL 锁, 关闭连接时释放
+// LOCK TABLES 会结束事务, 不使用共享快照的连接
+func lockTableRead(ctx context.Context, db *sql.DB, table string, o *dumpOption) (*dumpConn, error) {
+	conn, err := openSessionConn(ctx, db, o)
+	if err != nil {
+		return nil, err
+	}
+	_, err = conn.Exec("LOCK TABLES " + QuoteIdentifier(table) + " READ LOCAL")
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	conn.release = []string{"UNLOCK TABLES"}
+	return conn, nil
+}
@@ -0,0 +1,50 @@
+package mysqldump
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestWithLockNonTransactionalTables(t *testing.T) {
+	db, f := newFakeDumpDB(t)
+	f.on("IFNULL(ENGINE, '')", []string{"name VARCHAR", "engine VARCHAR", "size BIGINT"},
+		[]driver.Value{[]byte("a"), []byte("MyISAM"), int64(0)},
+		[]driver.Value{[]byte("b"), []byte("InnoDB"), int64(0)})
+	out, err := fakeDump(db, WithAllTable(), WithData(), WithSingleTransaction(), WithLockNonTransactionalTables())
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	for _, want := range []string{"INSERT INTO `a` VALUES (1);", "INSERT INTO `b` VALUES (1);"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dump() output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	// a 的锁, 数据和解锁在同一个连接上, b 使用快照连接不加锁
+	index := func(query string) int {
+		for i, q := range f.executed() {
+			if strings.Contains(q, query) {
+				return i
+			}
+		}
+		t.Fatalf("%q not executed: %v", query, f.executed())
+		return -1
+	}
+	conns := f.executedConns()
+	lock, data, unlock := index("LOCK TABLES `a` READ LOCAL"), index("SELECT `id` FROM `a`"), index("UNLOCK TABLES")
+	if !(lock < data && data < unlock) {
+		t.Errorf("lock at %d, data at %d, unlock at %d, want in order", lock, data, unlock)
+	}
+	if conns[lock] != conns[data] || conns[data] != conns[unlock] {
+		t.Errorf("lock, data and unlock run on conns %d, %d, %d, want the same conn", conns[lock], conns[data], conns[unlock])
+	}
+	if other := conns[index("SELECT `id` FROM `b`")]; other == conns[lock] {
+		t.Errorf("data of b read on the locked conn %d", other)
+	}
+	for _, q := range f.executed() {
+		if strings.Contains(q, "LOCK TABLES `b`") {
+			t.Errorf("transactional table locked: %s", q)
+		}
+	}
+}
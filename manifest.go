@@ -0,0 +1,106 @@
+package mysqldump
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Manifest 导出清单, 不需要解析 SQL 注释即可用于归档, 校验和自动化
+type Manifest struct {
+	Database      string       `json:"database"`
+	ServerVersion string       `json:"server_version"`
+	ServerFlavor  ServerFlavor `json:"server_flavor"`
+	// 使用的选项, 与文件头相同
+	Options   []string      `json:"options"`
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Duration  time.Duration `json:"duration"`
+	Bytes     int64         `json:"bytes"`
+	// 导出内容的 sha256, 断点续传的导出为空
+	Checksum       string           `json:"checksum,omitempty"`
+	BinlogFile     string           `json:"binlog_file,omitempty"`
+	BinlogPosition uint64           `json:"binlog_position,omitempty"`
+	GTIDExecuted   string           `json:"gtid_executed,omitempty"`
+	Tables         []ManifestTable  `json:"tables"`
+	FailedTables   []FailedTable    `json:"failed_tables,omitempty"`
+	Variables      []ServerVariable `json:"server_variables,omitempty"`
+}
+
+// ManifestTable 清单中的表
+type ManifestTable struct {
+	Name     string        `json:"name"`
+	Rows     int64         `json:"rows"`
+	Bytes    int64         `json:"bytes"`
+	Duration time.Duration `json:"duration"`
+	// 表结构和数据输出的 sha256, 断点续传的表为空
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// WithManifest 导出成功后将清单以 JSON 写到 path
+// 清单包含数据库, 服务端版本, 每个表的行数, 字节数和 sha256, 使用的选项, binlog 位置和起止时间
+func WithManifest(path string) DumpOption {
+	return func(option *dumpOption) {
+		option.manifest = path
+	}
+}
+
+// ReadManifest 读取 WithManifest 写出的清单
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s: %v", path, err)
+	}
+	return &m, nil
+}
+
+// newManifest 根据导出结果生成清单
+func newManifest(result *DumpResult, options []string) *Manifest {
+	m := &Manifest{
+		Database:       result.Database,
+		ServerVersion:  result.ServerVersion.Raw,
+		ServerFlavor:   result.ServerVersion.Flavor,
+		Options:        options,
+		StartTime:      result.StartTime,
+		EndTime:        result.EndTime,
+		Duration:       result.EndTime.Sub(result.StartTime),
+		Bytes:          result.Bytes,
+		Checksum:       result.Checksum,
+		BinlogFile:     result.BinlogFile,
+		BinlogPosition: result.BinlogPosition,
+		GTIDExecuted:   result.GTIDExecuted,
+		Tables:         make([]ManifestTable, 0, len(result.Tables)),
+		FailedTables:   result.FailedTables,
+		Variables:      result.ServerVariables,
+	}
+	for _, table := range result.Tables {
+		m.Tables = append(m.Tables, ManifestTable{
+			Name:     table.Name,
+			Rows:     table.Rows,
+			Bytes:    table.Bytes,
+			Duration: table.Duration,
+			Checksum: table.Checksum,
+		})
+	}
+	return m
+}
+
+// writeManifest 写出清单, 先写临时文件再重命名, 不会留下不完整的清单
+func writeManifest(path string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
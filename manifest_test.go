@@ -0,0 +1,42 @@
+package mysqldump
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	result := &DumpResult{
+		Database:       "shop",
+		ServerVersion:  ParseServerVersion("8.0.36"),
+		StartTime:      start,
+		EndTime:        start.Add(2 * time.Second),
+		Bytes:          1024,
+		Checksum:       "abc",
+		BinlogFile:     "binlog.000001",
+		BinlogPosition: 157,
+		Tables: []TableResult{
+			{Name: "users", Rows: 10, Bytes: 600, Duration: time.Second, Checksum: "u1"},
+			{Name: "orders", Rows: 0, Bytes: 200},
+		},
+	}
+	m := newManifest(result, []string{"data"})
+	if m.Duration != 2*time.Second || m.ServerFlavor != FlavorMySQL || len(m.Tables) != 2 || m.Tables[0].Checksum != "u1" {
+		t.Fatalf("newManifest() = %+v", m)
+	}
+
+	path := filepath.Join(t.TempDir(), "dump.manifest.json")
+	if err := writeManifest(path, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("ReadManifest() = %+v, want %+v", got, m)
+	}
+}
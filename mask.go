@@ -0,0 +1,163 @@
+package mysqldump
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ColumnTransform 列值转换函数, 用于导出时对数据脱敏
+// value 为驱动返回的原始值 ([]byte, int64, float64, time.Time 等), NULL 时为 nil
+// 返回 nil 表示导出为 NULL, []byte 的内容在处理下一行时会被覆盖, 不能保留
+// 返回 string 时不论列类型都作为加引号的字符串输出, 如 MaskHash 用于 INT 列时
+type ColumnTransform func(value interface{}) interface{}
+
+// WithColumnTransform 对指定表的指定列在导出时进行转换
+func WithColumnTransform(table, column string, transform ColumnTransform) DumpOption {
+	return func(option *dumpOption) {
+		if option.transforms == nil {
+			option.transforms = make(map[string]map[string]ColumnTransform)
+		}
+		if option.transforms[table] == nil {
+			option.transforms[table] = make(map[string]ColumnTransform)
+		}
+		option.transforms[table][strings.ToLower(column)] = transform
+	}
+}
+
+// getColumnTransforms 按列顺序返回表的转换函数, 没有转换的列为 nil
+// 被转换的列记录到 o.result
+func getColumnTransforms(o *dumpOption, table string, columns []string, dataTypes []string) []ColumnTransform {
+	tableTransforms := o.transforms[table]
+	if len(tableTransforms) == 0 && len(o.maskRules) == 0 {
+		return nil
+	}
+	transforms := make([]ColumnTransform, len(columns))
+	for i, column := range columns {
+		mask := "custom"
+		transform := tableTransforms[strings.ToLower(column)]
+		if transform == nil {
+			mask, transform = findMaskRule(o.maskRules, table, column, dataTypes[i])
+		}
+		if transform == nil {
+			continue
+		}
+		transforms[i] = transform
+		if o.result != nil {
+			o.recordMaskedColumn(MaskedColumn{Table: table, Column: column, Mask: mask})
+		}
+	}
+	return transforms
+}
+
+// recordMaskedColumn 记录被转换的列, 按分区导出时同一个表会多次计算转换函数, 只记录一次
+func (o *dumpOption) recordMaskedColumn(column MaskedColumn) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, c := range o.result.MaskedColumns {
+		if c == column {
+			return
+		}
+	}
+	o.result.MaskedColumns = append(o.result.MaskedColumns, column)
+}
+
+// valueToString 将驱动返回的值转换为字符串
+func valueToString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// MaskNull 导出为 NULL
+func MaskNull(interface{}) interface{} {
+	return nil
+}
+
+// MaskHash 导出为原值的 sha256 十六进制字符串
+func MaskHash(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(valueToString(value)))
+	return hex.EncodeToString(sum[:])
+}
+
+// MaskEmail 保留邮箱首字母和域名, 如 john@example.com 导出为 j***@example.com
+func MaskEmail(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	s := valueToString(value)
+	at := strings.LastIndex(s, "@")
+	if at <= 0 {
+		return maskRunes(s, 1, 0)
+	}
+	return maskRunes(s[:at], 1, 0) + s[at:]
+}
+
+// MaskPhone 只保留最后 4 位数字, 如 13812345678 导出为 *******5678
+func MaskPhone(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	s := valueToString(value)
+	keep := 4
+	var b strings.Builder
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			if digits > keep {
+				b.WriteByte('*')
+			} else {
+				b.WriteRune(r)
+			}
+			digits--
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MaskName 只保留首字符, 如 Alice 导出为 A****
+func MaskName(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return maskRunes(valueToString(value), 1, 0)
+}
+
+// maskRunes 保留前 head 个和后 tail 个字符, 其余替换为 *
+func maskRunes(s string, head, tail int) string {
+	n := utf8.RuneCountInString(s)
+	if n <= head+tail {
+		return strings.Repeat("*", n)
+	}
+	var b strings.Builder
+	i := 0
+	for _, r := range s {
+		if i < head || i >= n-tail {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('*')
+		}
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform ColumnTransform
+		value     interface{}
+		want      interface{}
+	}{
+		{name: "email", transform: MaskEmail, value: []byte("john@example.com"), want: "j***@example.com"},
+		{name: "email invalid", transform: MaskEmail, value: "john", want: "j***"},
+		{name: "phone", transform: MaskPhone, value: []byte("138-1234-5678"), want: "***-****-5678"},
+		{name: "name", transform: MaskName, value: []byte("张三丰"), want: "张**"},
+		{name: "null", transform: MaskNull, value: []byte("x"), want: nil},
+		{name: "hash nil", transform: MaskHash, value: nil, want: nil},
+		{name: "hash", transform: MaskHash, value: []byte("a"), want: "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.transform(tt.value)
+			if got != tt.want {
+				t.Errorf("%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask_integerColumn(t *testing.T) {
+	table := &TableMeta{Name: "users", Columns: []string{"id", "born"}, DataTypes: []string{"INT", "DATE"}}
+	values := "('1**','2" + strings.Repeat("*", 18) + "');\n"
+	tests := []struct {
+		name string
+		opts []DumpOption
+		want string
+	}{
+		{name: "mysql", want: "INSERT INTO `users` VALUES " + values},
+		{name: "postgres", opts: []DumpOption{WithDialect(DialectPostgres)}, want: "INSERT INTO \"users\" VALUES " + values},
+		{name: "sqlite", opts: []DumpOption{WithDialect(DialectSQLite)}, want: "INSERT INTO \"users\" VALUES " + values},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append(tt.opts, WithCompact(), WithMaskRules(MaskRule{Table: "users", Mask: "name"}))
+			o := newDumpOption(opts)
+			// 脱敏结果为 string, 整数和日期列也要加引号
+			row := []interface{}{int64(123), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+			for i, transform := range getColumnTransforms(o, table.Name, table.Columns, table.DataTypes) {
+				row[i] = transform(row[i])
+			}
+			var sb strings.Builder
+			if err := o.formatter.Row(&sb, table, row); err != nil {
+				t.Fatalf("Row() error = %v", err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("Row() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package mysqldump
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 导出耗时直方图的桶, 单位秒
+var metricsDurationBuckets = []float64{1, 10, 30, 60, 300, 600, 1800, 3600, 7200, 14400, 28800}
+
+// Metrics 导出指标, 按数据库区分, 不依赖 Prometheus 客户端库
+// 通过 PublishExpvar 发布到 expvar; 实现了 http.Handler, 没有 Prometheus 客户端时可以直接作为抓取地址
+// 已有 prometheus.Registerer 时, 在 WithNotifier 中更新注册的指标, 不需要 Metrics, 例如:
+//
+//	dumps := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "mysqldump_dumps_total"}, []string{"database", "status"})
+//	lastSuccess := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "mysqldump_last_success_timestamp_seconds"}, []string{"database"})
+//	reg.MustRegister(dumps, lastSuccess)
+//	opt := mysqldump.WithNotifier(mysqldump.NotifierFunc(func(r *mysqldump.DumpResult, err error) error {
+//		if err != nil {
+//			dumps.WithLabelValues(r.Database, "failed").Inc()
+//			return nil
+//		}
+//		dumps.WithLabelValues(r.Database, "succeeded").Inc()
+//		lastSuccess.WithLabelValues(r.Database).Set(float64(r.EndTime.Unix()))
+//		return nil
+//	}))
+type Metrics struct {
+	mu        sync.Mutex
+	databases map[string]*databaseMetrics
+}
+
+type databaseMetrics struct {
+	Dumps         int64 `json:"dumps"`
+	Failures      int64 `json:"failures"`
+	TablesDumped  int64 `json:"tables_dumped"`
+	RowsDumped    int64 `json:"rows_dumped"`
+	BytesWritten  int64 `json:"bytes_written"`
+	LastSuccess   int64 `json:"last_success_timestamp"`
+	LastFailure   int64 `json:"last_failure_timestamp"`
+	durationCount []int64
+	DurationSum   float64 `json:"duration_seconds_sum"`
+}
+
+// NewMetrics 创建导出指标
+func NewMetrics() *Metrics {
+	return &Metrics{databases: make(map[string]*databaseMetrics)}
+}
+
+// WithMetrics 记录导出指标到 m
+func WithMetrics(m *Metrics) DumpOption {
+	return func(option *dumpOption) {
+		option.metrics = m
+	}
+}
+
+func (m *Metrics) observe(result *DumpResult, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// DSN 无法解析时没有数据库名, 单独记录, 不与空标签混在一起
+	database := result.Database
+	if database == "" {
+		database = "unknown"
+	}
+	d := m.databases[database]
+	if d == nil {
+		d = &databaseMetrics{durationCount: make([]int64, len(metricsDurationBuckets)+1)}
+		m.databases[database] = d
+	}
+	d.Dumps++
+	d.TablesDumped += int64(len(result.Tables))
+	d.RowsDumped += result.Rows()
+	d.BytesWritten += result.Bytes
+	if err != nil {
+		d.Failures++
+		d.LastFailure = result.EndTime.Unix()
+		return
+	}
+	d.LastSuccess = result.EndTime.Unix()
+
+	duration := result.EndTime.Sub(result.StartTime).Seconds()
+	d.DurationSum += duration
+	i := sort.SearchFloat64s(metricsDurationBuckets, duration)
+	d.durationCount[i]++
+}
+
+// PublishExpvar 以 name 发布到 expvar, 同一个 name 只能发布一次
+func (m *Metrics) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		snapshot := make(map[string]databaseMetrics, len(m.databases))
+		for db, d := range m.databases {
+			snapshot[db] = *d
+		}
+		return snapshot
+	}))
+}
+
+// ServeHTTP 以 Prometheus 文本格式输出指标
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dbs := make([]string, 0, len(m.databases))
+	for db := range m.databases {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	counters := []struct {
+		name, help, kind string
+		value            func(d *databaseMetrics) int64
+	}{
+		{"mysqldump_dumps_total", "Total number of dumps.", "counter", func(d *databaseMetrics) int64 { return d.Dumps }},
+		{"mysqldump_failures_total", "Total number of failed dumps.", "counter", func(d *databaseMetrics) int64 { return d.Failures }},
+		{"mysqldump_tables_dumped_total", "Total number of tables dumped.", "counter", func(d *databaseMetrics) int64 { return d.TablesDumped }},
+		{"mysqldump_rows_dumped_total", "Total number of rows dumped.", "counter", func(d *databaseMetrics) int64 { return d.RowsDumped }},
+		{"mysqldump_bytes_written_total", "Total number of bytes written.", "counter", func(d *databaseMetrics) int64 { return d.BytesWritten }},
+		{"mysqldump_last_success_timestamp_seconds", "Unix time of the last successful dump.", "gauge", func(d *databaseMetrics) int64 { return d.LastSuccess }},
+		{"mysqldump_last_failure_timestamp_seconds", "Unix time of the last failed dump.", "gauge", func(d *databaseMetrics) int64 { return d.LastFailure }},
+	}
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", c.name, c.help, c.name, c.kind)
+		for _, db := range dbs {
+			fmt.Fprintf(w, "%s{database=%q} %d\n", c.name, db, c.value(m.databases[db]))
+		}
+	}
+
+	const name = "mysqldump_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Duration of successful dumps.\n# TYPE %s histogram\n", name, name)
+	for _, db := range dbs {
+		d := m.databases[db]
+		var cumulative int64
+		for i, bound := range metricsDurationBuckets {
+			cumulative += d.durationCount[i]
+			fmt.Fprintf(w, "%s_bucket{database=%q,le=%q} %d\n", name, db, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		cumulative += d.durationCount[len(metricsDurationBuckets)]
+		fmt.Fprintf(w, "%s_bucket{database=%q,le=\"+Inf\"} %d\n", name, db, cumulative)
+		fmt.Fprintf(w, "%s_sum{database=%q} %s\n", name, db, strconv.FormatFloat(d.DurationSum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{database=%q} %d\n", name, db, cumulative)
+	}
+}
+
+// LastSuccess 返回数据库最后一次导出成功的时间, 没有成功过返回零值
+func (m *Metrics) LastSuccess(database string) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d := m.databases[database]; d != nil && d.LastSuccess > 0 {
+		return time.Unix(d.LastSuccess, 0)
+	}
+	return time.Time{}
+}
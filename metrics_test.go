@@ -0,0 +1,61 @@
+package mysqldump
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	start := time.Unix(1700000000, 0)
+	m.observe(&DumpResult{
+		Database:  "test",
+		StartTime: start,
+		EndTime:   start.Add(20 * time.Second),
+		Bytes:     100,
+		Tables:    []TableResult{{Name: "a", Rows: 3}, {Name: "b", Rows: 4}},
+	}, nil)
+	m.observe(&DumpResult{Database: "test", StartTime: start, EndTime: start.Add(time.Hour)}, errors.New("boom"))
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`mysqldump_dumps_total{database="test"} 2`,
+		`mysqldump_failures_total{database="test"} 1`,
+		`mysqldump_rows_dumped_total{database="test"} 7`,
+		`mysqldump_tables_dumped_total{database="test"} 2`,
+		`mysqldump_last_success_timestamp_seconds{database="test"} 1700000020`,
+		`mysqldump_duration_seconds_bucket{database="test",le="10"} 0`,
+		`mysqldump_duration_seconds_bucket{database="test",le="30"} 1`,
+		`mysqldump_duration_seconds_count{database="test"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("ServeHTTP() missing %s\n%s", want, body)
+		}
+	}
+	if got := m.LastSuccess("test"); got.Unix() != 1700000020 {
+		t.Errorf("LastSuccess() = %v", got)
+	}
+}
+
+func TestMetrics_failedDSNCheck(t *testing.T) {
+	m := NewMetrics()
+	// DSN 检查失败时数据库名已知, 按数据库记录
+	err := Dump("root@tcp(127.0.0.1:1)/test?multiStatements=true", WithReadOnlyGuard(), WithMetrics(m), WithWriter(&strings.Builder{}))
+	if err == nil {
+		t.Fatal("Dump() want error for multiStatements with WithReadOnlyGuard")
+	}
+	if d := m.databases["test"]; d == nil || d.Failures != 1 {
+		t.Errorf("metrics = %v, want a failure for database test", m.databases)
+	}
+
+	// 无法解析的 DSN 记录为 unknown
+	_ = Dump("not a dsn", WithMetrics(m), WithWriter(&strings.Builder{}))
+	if d := m.databases["unknown"]; d == nil || d.Failures != 1 {
+		t.Errorf("metrics = %v, want a failure for database unknown", m.databases)
+	}
+}
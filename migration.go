@@ -0,0 +1,131 @@
+package mysqldump
+
+import (
+	"io"
+	"strings"
+)
+
+// GenerateMigration 比较两个导出文件的表结构, 返回将 from 的结构变为 to 的语句, 语句不以 ; 结尾
+// 包括新增和删除的表, 列, 索引和外键, 表选项的变化不处理
+// 先删除外键, 最后添加外键, 执行期间关闭 FOREIGN_KEY_CHECKS
+func GenerateMigration(from, to io.Reader) ([]string, error) {
+	fromSchema, err := readDumpSchema(from)
+	if err != nil {
+		return nil, err
+	}
+	toSchema, err := readDumpSchema(to)
+	if err != nil {
+		return nil, err
+	}
+	return migrationStatements(fromSchema, toSchema), nil
+}
+
+// migrationStatements 生成将 from 变为 to 的语句
+func migrationStatements(from, to *schemaDef) []string {
+	var dropForeignKeys, creates, alters, drops, addForeignKeys []string
+	for _, name := range to.names {
+		old, ok := from.tables[name]
+		if !ok {
+			creates = append(creates, to.createSQL[name])
+			continue
+		}
+		def := to.tables[name]
+		table := QuoteIdentifier(name)
+
+		fromKeys := make(map[string]foreignKey)
+		for _, fk := range old.foreignKeys {
+			fromKeys[fk.name] = fk
+		}
+		toKeys := make(map[string]foreignKey)
+		for _, fk := range def.foreignKeys {
+			toKeys[fk.name] = fk
+			if oldKey, ok := fromKeys[fk.name]; !ok || oldKey.definition != fk.definition {
+				addForeignKeys = append(addForeignKeys, "ALTER TABLE "+table+" ADD "+fk.definition)
+			}
+		}
+		for _, fk := range old.foreignKeys {
+			if newKey, ok := toKeys[fk.name]; !ok || newKey.definition != fk.definition {
+				dropForeignKeys = append(dropForeignKeys, "ALTER TABLE "+table+" DROP FOREIGN KEY "+QuoteIdentifier(fk.name))
+			}
+		}
+
+		if clauses := alterClauses(old, def); len(clauses) > 0 {
+			alters = append(alters, "ALTER TABLE "+table+"\n  "+strings.Join(clauses, ",\n  "))
+		}
+	}
+	for _, name := range from.names {
+		if _, ok := to.tables[name]; !ok {
+			drops = append(drops, "DROP TABLE IF EXISTS "+QuoteIdentifier(name))
+		}
+	}
+
+	var statements []string
+	for _, group := range [][]string{dropForeignKeys, creates, alters, drops, addForeignKeys} {
+		statements = append(statements, group...)
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+	statements = append([]string{"SET FOREIGN_KEY_CHECKS=0"}, statements...)
+	return append(statements, "SET FOREIGN_KEY_CHECKS=1")
+}
+
+// alterClauses 返回一个表的列和索引变化对应的 ALTER TABLE 子句, 外键单独处理
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func alterClauses(from, to *tableDef) []string {
+	var clauses []string
+
+	// 先删除索引, 修改过的索引删除后重新添加
+	toIndexes := make(map[string]indexDef)
+	for _, idx := range to.indexes {
+		toIndexes[idx.name] = idx
+	}
+	fromIndexes := make(map[string]indexDef)
+	for _, idx := range from.indexes {
+		fromIndexes[idx.name] = idx
+		if newIdx, ok := toIndexes[idx.name]; !ok || newIdx.definition != idx.definition {
+			clauses = append(clauses, "DROP INDEX "+QuoteIdentifier(idx.name))
+		}
+	}
+	if from.primaryKeyDef != "" && from.primaryKeyDef != to.primaryKeyDef {
+		clauses = append(clauses, "DROP PRIMARY KEY")
+	}
+
+	toColumns := make(map[string]bool)
+	for _, col := range to.columns {
+		toColumns[col.name] = true
+	}
+	for _, col := range from.columns {
+		if !toColumns[col.name] {
+			clauses = append(clauses, "DROP COLUMN "+QuoteIdentifier(col.name))
+		}
+	}
+	fromColumns := make(map[string]columnDef)
+	for _, col := range from.columns {
+		fromColumns[col.name] = col
+	}
+	for i, col := range to.columns {
+		old, ok := fromColumns[col.name]
+		switch {
+		case !ok:
+			position := " FIRST"
+			if i > 0 {
+				position = " AFTER " + QuoteIdentifier(to.columns[i-1].name)
+			}
+			clauses = append(clauses, "ADD COLUMN "+QuoteIdentifier(col.name)+" "+col.definition+position)
+		case old.definition != col.definition:
+			clauses = append(clauses, "MODIFY COLUMN "+QuoteIdentifier(col.name)+" "+col.definition)
+		}
+	}
+
+	if to.primaryKeyDef != "" && from.primaryKeyDef != to.primaryKeyDef {
+		clauses = append(clauses, "ADD "+to.primaryKeyDef)
+	}
+	for _, idx := range to.indexes {
+		if oldIdx, ok := fromIndexes[idx.name]; !ok || oldIdx.definition != idx.definition {
+			clauses = append(clauses, "ADD "+idx.definition)
+		}
+	}
+	return clauses
+}
@@ -0,0 +1,51 @@
+package mysqldump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMigration(t *testing.T) {
+	from := "" +
+		"--\n-- Table structure for table `users`\n--\n\n" +
+		"CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(32) NOT NULL,\n  `age` int,\n" +
+		"  PRIMARY KEY (`id`),\n  KEY `idx_name` (`name`)\n) ENGINE=InnoDB;\n" +
+		"CREATE TABLE `orders` (\n  `id` int NOT NULL,\n  `user_id` int,\n  PRIMARY KEY (`id`),\n  KEY `fk_user` (`user_id`),\n" +
+		"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n) ENGINE=InnoDB;\n" +
+		"CREATE TABLE `logs` (`id` int);\n"
+	to := "" +
+		"CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `email` varchar(255) DEFAULT NULL,\n  `name` varchar(64) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n  KEY `idx_name` (`name`(10)),\n  UNIQUE KEY `uk_email` (`email`)\n) ENGINE=InnoDB;\n" +
+		"CREATE TABLE `orders` (\n  `id` int NOT NULL,\n  `user_id` int,\n  PRIMARY KEY (`id`),\n  KEY `fk_user` (`user_id`),\n" +
+		"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE\n) ENGINE=InnoDB;\n" +
+		"--\n-- Table structure for table `tags`\n--\n\n" +
+		"CREATE TABLE `tags` (`id` int);\n"
+	got, err := GenerateMigration(strings.NewReader(from), strings.NewReader(to))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"SET FOREIGN_KEY_CHECKS=0",
+		"ALTER TABLE `orders` DROP FOREIGN KEY `fk_user`",
+		"CREATE TABLE `tags` (`id` int)",
+		"ALTER TABLE `users`\n" +
+			"  DROP INDEX `idx_name`,\n" +
+			"  DROP COLUMN `age`,\n" +
+			"  ADD COLUMN `email` varchar(255) DEFAULT NULL AFTER `id`,\n" +
+			"  MODIFY COLUMN `name` varchar(64) NOT NULL,\n" +
+			"  ADD KEY `idx_name`(`name`(10)),\n" +
+			"  ADD UNIQUE KEY `uk_email`(`email`)",
+		"DROP TABLE IF EXISTS `logs`",
+		"ALTER TABLE `orders` ADD CONSTRAINT `fk_user` FOREIGN KEY(`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE",
+		"SET FOREIGN_KEY_CHECKS=1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateMigration() =\n%s\nwant\n%s", strings.Join(got, ";\n"), strings.Join(want, ";\n"))
+	}
+
+	got, err = GenerateMigration(strings.NewReader(to), strings.NewReader(to))
+	if err != nil || got != nil {
+		t.Errorf("GenerateMigration() same schema = %v, %v", got, err)
+	}
+}
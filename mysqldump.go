@@ -2,13 +2,20 @@ package mysqldump
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 )
 
@@ -20,6 +27,8 @@ func init() {
 type dumpOption struct {
 	// 导出表数据
 	isData bool
+	// 不导出表结构
+	noSchema bool
 	// 导出指定表, 与 isAllTables 互斥, isAllTables 优先级高
 	tables []string
 	// 排除指定表, 与 tables 互斥, tables 优先级高
@@ -28,10 +37,171 @@ type dumpOption struct {
 	isAllTable bool
 	// 是否删除表
 	isDropTable bool
+	// 导出数据前清空表
+	isTruncateTable bool
+	// 表数据前后加上 DISABLE KEYS 和 ENABLE KEYS
+	isDisableKeys bool
+	// 表数据前后加上 LOCK TABLES 和 UNLOCK TABLES
+	isAddLocks bool
+	// 删除并重新创建数据库
+	isDropDatabase bool
+	// 输出官方 mysqldump 格式
+	mysqldumpCompat bool
+	// 不输出注释和空行
+	compact bool
+	// 二进制列输出为 _binary '...' 而不是十六进制
+	noHexBlob bool
+	// JSON 列输出为 CAST('...' AS JSON)
+	jsonCast bool
+	// JSON 列去掉空白
+	compactJSON bool
+	// 服务端版本, 导出开始时查询
+	serverVersion ServerVersion
+	// TiDB 模式
+	tidb bool
+	// 去掉分区定义
+	noPartitions bool
+	// 同时导出的分区数
+	partitionConcurrency int
+	// 导出账号和权限
+	grants bool
+	// 在文件头记录的全局变量
+	serverVariables []string
+	// 清单文件路径
+	manifest string
+	// 警告回调
+	warningHandler func(Warning)
+	// 串行调用 warningHandler, 不在 mu 下调用, handler 中可以使用导出的其他状态
+	warningMu sync.Mutex
+	// 连接池设置
+	maxOpenConns    int
+	connMaxLifetime time.Duration
+	// 每个连接设置的会话变量
+	sessionVars map[string]string
+	// 会话时区, 为 nil 时使用服务端的默认时区
+	timeZone *time.Location
+	// 数据查询的优化器提示和修饰符
+	selectHints []string
+	// 在一致性快照事务中导出
+	singleTransaction bool
+	// 开启快照事务前加全局读锁
+	lockAllTables bool
+	// 导出非事务引擎表的数据时加表锁
+	lockNonTransactional bool
+	// 导出的数据库, worker 连接建立后切换到该库
+	database string
+	// 导出时使用的连接池, worker 连接和表锁的连接从中获取, 以及需要加表锁的非事务引擎表
+	pool *sql.DB
+	// 并发导出时与快照事务同一时间点的 worker 连接
+	snapshotConns    *snapshotConns
+	nonTransactional map[string]bool
+	// SHOW CREATE DATABASE 的结果, isDropDatabase 时使用
+	createDatabaseSQL string
 	// 是否如果插入的记录违反了唯一性约束，INSERT IGNORE 会忽略该错误，继续执行后续的插入操作
 	isIgnoreInsert bool
+	// 只导出表结构不导出数据的表
+	noDataTables []string
+	// 排除指定存储引擎的表
+	ignoreEngines []string
+	// 排除超过指定大小的表(字节), 0 表示不限制
+	maxTableSize int64
+	// 每个表需要排除的列, key 为表名
+	omitColumns map[string][]string
+	// 列值转换, 用于数据脱敏, key 为表名和小写列名
+	transforms map[string]map[string]ColumnTransform
+	// 脱敏规则
+	maskRules []MaskRule
+	// 按比例抽样导出的表, key 为表名, value 为 (0, 1) 之间的比例
+	sampleRates map[string]float64
+	// 每 n 行导出一行, 0 表示不抽样
+	sampleEvery int
+	// 子集导出的根表和条件
+	subsetTable string
+	subsetWhere string
+	// 每个表导出数据的 WHERE 条件, 由子集等选项计算
+	wheres map[string]string
+	// WithTableOptions 单独设置的表
+	tableOptions map[string]*tableOption
+	// 增量导出
+	incremental *incrementalOption
+	// 按主键分块导出的行数, 0 表示不分块
+	chunkSize int
+	// 断点续传
+	checkpoint *checkpoint
+	// 导出记录目录
+	catalog         *Catalog
+	catalogLocation string
+	// 导出结束后的通知
+	notifiers []Notifier
+	// 导出指标
+	metrics *Metrics
+	// 链路追踪
+	tracer Tracer
+	// 进度回调
+	progress      func(Progress)
+	writeProgress func(int64) error
+	// 每个表的输出
+	tableWriter func(table string) io.WriteCloser
+	// 并发导出的表数
+	concurrency int
+	// Clone 时每条 INSERT 插入的行数
+	cloneBatchSize int
+	// 输出格式
+	formatter Formatter
+	// 导出过程中的回调
+	hooks Hooks
+	// 表导出失败时跳过并继续
+	skipFailedTables bool
+	// 无效的视图输出为注释
+	commentBrokenViews bool
+	// 去掉 DEFINER 子句
+	skipDefiner bool
+	// 去掉 AUTO_INCREMENT=N 表选项
+	noAutoIncrementValue bool
+	// CREATE TABLE 不加 IF NOT EXISTS
+	noIfNotExists bool
+	// 要导出的表中的视图, 只导出定义
+	views map[string]bool
+	// MariaDB 的序列
+	sequences map[string]bool
+	// 文件头尾注释模板, custom 为 true 且模板为 nil 时不输出
+	headerTemplate *template.Template
+	footerTemplate *template.Template
+	customHeader   bool
+	customFooter   bool
+	// 并发导出时保护 result
+	mu sync.Mutex
+	// 已警告过的不支持类型的列, 表名.列名, 由 mu 保护
+	unsupportedColumns map[string]bool
+	// 导出结果
+	result *DumpResult
 	// writer 默认为 os.Stdout
 	writer io.Writer
+	// 同时写入的 writer
+	tees []io.Writer
+	// 不给 DSN 加默认参数
+	noDSNDefaults bool
+	// 使用压缩协议
+	compress bool
+	// 输出中的对象名加上数据库名
+	qualifiedNames bool
+	// 先输出所有表结构再输出数据, schemaWritten 为已输出结构的表
+	schemaFirst   bool
+	schemaWritten map[string]bool
+	// 文件尾输出 JSON 摘要
+	summaryFooter bool
+	// 文件头不输出来源信息
+	noProvenance bool
+	// Dumper 打开的连接池, 导出结束后不关闭
+	sharedPool *sql.DB
+	// 只读会话, readOnlyVariable 为设置的会话变量
+	readOnlyGuard    bool
+	readOnlyVariable string
+	// 输出缓冲区大小和定时写出的间隔
+	bufferSize    int
+	flushInterval time.Duration
+	// 主输出的定时写出, 只在写主输出的 goroutine 中使用
+	flush flushTimer
 }
 
 type DumpOption func(*dumpOption)
@@ -43,6 +213,35 @@ func WithDropTable() DumpOption {
 	}
 }
 
+// WithDisableKeys 表数据前后加上 ALTER TABLE ... DISABLE KEYS 和 ENABLE KEYS
+// 恢复时插入完成后再统一建立非唯一索引, 与官方 mysqldump 默认行为相同
+func WithDisableKeys() DumpOption {
+	return func(option *dumpOption) {
+		option.isDisableKeys = true
+	}
+}
+
+// WithAddLocks 表数据前后加上 LOCK TABLES ... WRITE 和 UNLOCK TABLES, 与官方 mysqldump 默认行为相同
+func WithAddLocks() DumpOption {
+	return func(option *dumpOption) {
+		option.isAddLocks = true
+	}
+}
+
+// WithAddDropDatabase 文件开头删除并重新创建数据库, 用于每次恢复都重建的临时环境
+func WithAddDropDatabase() DumpOption {
+	return func(option *dumpOption) {
+		option.isDropDatabase = true
+	}
+}
+
+// WithTruncateTable 导出数据前先清空表, 恢复时只刷新数据, 保留已有表的权限, 触发器和外键
+func WithTruncateTable() DumpOption {
+	return func(option *dumpOption) {
+		option.isTruncateTable = true
+	}
+}
+
 // WithData 导出表数据
 func WithData() DumpOption {
 	return func(option *dumpOption) {
@@ -50,6 +249,26 @@ func WithData() DumpOption {
 	}
 }
 
+// WithNoSchema 不导出表结构, 只导出数据
+func WithNoSchema() DumpOption {
+	return func(option *dumpOption) {
+		option.noSchema = true
+	}
+}
+
+// WithSkipFailedTables 表导出失败时记录到 DumpResult.FailedTables 并继续导出其他表
+// 每个表先写到临时文件, 失败的表不会在输出中留下不完整的内容
+func WithSkipFailedTables() DumpOption {
+	return func(option *dumpOption) {
+		option.skipFailedTables = true
+	}
+}
+
+// isolated 每个表是否先写到临时文件或单独的 writer, 此时不记录分块断点
+func (o *dumpOption) isolated() bool {
+	return o.concurrency > 1 || o.skipFailedTables || o.tableWriter != nil
+}
+
 // WithIgnoreInsertTable 如果插入的记录违反了唯一性约束，INSERT IGNORE 会忽略该错误，继续执行后续的插入操作
 func WithIgnoreInsertTable() DumpOption {
 	return func(option *dumpOption) {
@@ -71,6 +290,74 @@ func WithTables(tables ...string) DumpOption {
 	}
 }
 
+// WithNoDataTables 指定表只导出表结构, 不导出数据, 常用于跳过日志等大表
+func WithNoDataTables(tables ...string) DumpOption {
+	return func(option *dumpOption) {
+		option.noDataTables = tables
+	}
+}
+
+// WithIgnoreEngines 排除指定存储引擎的表, 如 FEDERATED, MEMORY, BLACKHOLE
+func WithIgnoreEngines(engines ...string) DumpOption {
+	return func(option *dumpOption) {
+		option.ignoreEngines = engines
+	}
+}
+
+// WithMaxTableSize 排除数据加索引大小超过 size 字节的表
+func WithMaxTableSize(size int64) DumpOption {
+	return func(option *dumpOption) {
+		option.maxTableSize = size
+	}
+}
+
+// WithOmitColumns 排除指定表的指定列, key 为表名, value 为列名
+// 被排除列的表会以带列名的 INSERT INTO `t` (`a`,`b`) VALUES 形式导出
+func WithOmitColumns(columns map[string][]string) DumpOption {
+	return func(option *dumpOption) {
+		option.omitColumns = columns
+	}
+}
+
+// WithSampleRate 按比例随机抽样导出指定表的数据, fraction 为 (0, 1) 之间的比例
+func WithSampleRate(table string, fraction float64) DumpOption {
+	return func(option *dumpOption) {
+		if option.sampleRates == nil {
+			option.sampleRates = make(map[string]float64)
+		}
+		option.sampleRates[table] = fraction
+	}
+}
+
+// WithSampleEvery 所有表每 n 行导出一行
+func WithSampleEvery(n int) DumpOption {
+	return func(option *dumpOption) {
+		option.sampleEvery = n
+	}
+}
+
+// WithChunkSize 按主键顺序每次查询 rows 行导出数据, 只对单列主键的表生效
+func WithChunkSize(rows int) DumpOption {
+	return func(option *dumpOption) {
+		option.chunkSize = rows
+	}
+}
+
+// WithConcurrency 同时导出 n 个表, 每个表先写到临时文件, 再按顺序写到 writer
+func WithConcurrency(n int) DumpOption {
+	return func(option *dumpOption) {
+		option.concurrency = n
+	}
+}
+
+// WithTableWriter 每个表的结构和数据写到 fn 返回的 writer, 写完后关闭
+// fn 返回 nil 时写到 WithWriter 指定的 writer, 文件头尾总是写到 WithWriter 指定的 writer
+func WithTableWriter(fn func(table string) io.WriteCloser) DumpOption {
+	return func(option *dumpOption) {
+		option.tableWriter = fn
+	}
+}
+
 // WithAllTable 导出全部表
 func WithAllTable() DumpOption {
 	return func(option *dumpOption) {
@@ -85,18 +372,16 @@ func WithWriter(writer io.Writer) DumpOption {
 	}
 }
 
-func Dump(dsn string, opts ...DumpOption) error {
-	// 打印开始
-	start := time.Now()
-	log.Printf("[info] [dump] start at %s\n", start.Format("2006-01-02 15:04:05"))
-	// 打印结束
-	defer func() {
-		end := time.Now()
-		log.Printf("[info] [dump] end at %s, cost %s\n", end.Format("2006-01-02 15:04:05"), end.Sub(start))
-	}()
-
-	var err error
+// WithTee 导出内容同时写到 writers, 只读取一次数据库即可同时写到本地文件, 上传流和 sha256 等哈希
+// 任意一个 writer 写入失败时导出失败, 慢的 writer 会拖慢导出, 断点续传时只写入继续导出的部分
+// WithTableWriter 等按表输出的内容不会写到 writers
+func WithTee(writers ...io.Writer) DumpOption {
+	return func(option *dumpOption) {
+		option.tees = append(option.tees, writers...)
+	}
+}
 
+func newDumpOption(opts []DumpOption) *dumpOption {
 	var o dumpOption
 
 	for _, opt := range opts {
@@ -108,288 +393,1235 @@ func Dump(dsn string, opts ...DumpOption) error {
 		o.isAllTable = true
 	}
 
+	if o.formatter == nil {
+		if o.mysqldumpCompat {
+			o.formatter = &compatFormatter{sqlFormatter{o: &o}}
+		} else {
+			o.formatter = &sqlFormatter{o: &o}
+		}
+	}
+	return &o
+}
+
+func Dump(dsn string, opts ...DumpOption) error {
+	return runDump(context.Background(), dsn, newDumpOption(opts))
+}
+
+// runDump 执行一次导出, 记录结果并通知
+func runDump(ctx context.Context, dsn string, o *dumpOption) error {
+	// 打印开始
+	start := time.Now()
+	log.Printf("[info] [dump] start at %s\n", start.Format("2006-01-02 15:04:05"))
+	// 打印结束
+	defer func() {
+		end := time.Now()
+		log.Printf("[info] [dump] end at %s, cost %s\n", end.Format("2006-01-02 15:04:05"), end.Sub(start))
+	}()
+
 	if o.writer == nil {
 		// 默认输出到 os.Stdout
 		o.writer = os.Stdout
 	}
 
-	buf := bufio.NewWriter(o.writer)
-	defer buf.Flush()
+	if o.result != nil {
+		*o.result = DumpResult{}
+	} else {
+		o.result = &DumpResult{}
+	}
+	o.result.StartTime = start
 
-	// 打印 Header
-	_, _ = buf.WriteString("-- ----------------------------\n")
-	_, _ = buf.WriteString("-- MySQL Database Dump\n")
-	_, _ = buf.WriteString("-- Start Time: " + start.Format("2006-01-02 15:04:05") + "\n")
-	_, _ = buf.WriteString("-- ----------------------------\n")
-	_, _ = buf.WriteString("\n\n")
-
-	// 连接数据库
-	db, err := sql.Open("mysql", dsn)
+	ctx, span := startSpan(ctx, o.tracer, "mysqldump.dump")
+	err := dump(ctx, dsn, o, start)
+	o.result.EndTime = time.Now()
+	span.SetAttribute("db.name", o.result.Database)
+	span.SetAttribute("mysqldump.tables", int64(len(o.result.Tables)))
+	span.SetAttribute("mysqldump.rows", o.result.Rows())
+	span.SetAttribute("mysqldump.bytes", o.result.Bytes)
+	endSpan(span, err)
+	o.metrics.observe(o.result, err)
+
+	// 通知导出结果
+	for _, notifier := range o.notifiers {
+		nerr := notifier.Notify(o.result, err)
+		if nerr != nil {
+			log.Printf("[error] [notify] %v \n", nerr)
+		}
+	}
+	return err
+}
+
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func dump(ctx context.Context, dsn string, o *dumpOption, start time.Time) (err error) {
+	// 连接前检查 DSN, 此时还没有输出
+	dsn = o.prepareDSN(dsn)
+	cfg, err := checkDSN(dsn, o)
 	if err != nil {
+		// 缺少 parseTime 等参数时数据库名已知, 指标和通知按数据库记录失败
+		if parsed, perr := mysql.ParseDSN(dsn); perr == nil {
+			o.result.Database = parsed.DBName
+		}
 		log.Printf("[error] %v \n", err)
 		return err
 	}
-	defer db.Close()
+	o.result.Database = cfg.DBName
+
+	// 断点续传, 从上次的输出位置继续写
+	if o.checkpoint != nil {
+		err = o.checkpoint.load(o.writer)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
+
+	hash := sha256.New()
+	// 先写 tees, tee 写入失败时这部分内容不会写到主输出, 主输出不会有文件尾
+	counter := &countWriter{w: io.MultiWriter(append(append([]io.Writer{hash}, o.tees...), o.writer)...), onWrite: o.writeProgress}
+	defer func() {
+		o.result.Bytes = counter.n
+	}()
+	if o.checkpoint != nil {
+		counter.n = o.checkpoint.state.Offset
+		o.checkpoint.counter = counter
+	}
+	buf := o.newBuffer(counter)
+	defer buf.Flush()
+	o.flush.interval = o.flushInterval
+	// 导出失败时在输出末尾写上标记, 文件尾写出后的错误不影响输出
+	complete := false
+	defer func() {
+		if err != nil && !complete {
+			o.writeIncomplete(buf, err)
+			// 写入失败后 buf 不再可用, 直接在主输出写上标记
+			if buf.Flush() != nil {
+				o.writeIncomplete(o.writer, err)
+			}
+		}
+	}()
+
+	// 连接数据库, Dumper 的连接池在多次导出间复用, 不关闭
+	db := o.sharedPool
+	if db == nil {
+		db, err = openReadOnlyDB(dsn, o)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+		defer db.Close()
+	}
+	o.pool = db
 
 	// 1. 获取数据库
-	dbName, err := GetDBNameFromDSN(dsn)
+	dbName := cfg.DBName
+	_, err = db.Exec("USE " + QuoteIdentifier(dbName))
 	if err != nil {
 		log.Printf("[error] %v \n", err)
 		return err
 	}
-	_, err = db.Exec(fmt.Sprintf("USE `%s`", dbName))
+	o.result.Database = dbName
+	o.database = dbName
+	o.serverVersion = getServerVersion(db)
+	o.applyTiDB(o.serverVersion)
+	serverVersion := o.serverVersion.Raw
+	o.result.ServerVersion = o.serverVersion
+	o.result.BinlogFile, o.result.BinlogPosition = getBinlogPosition(db, o.serverVersion)
+	o.result.GTIDExecuted = getGTIDExecuted(db, o.serverVersion)
+
+	// 一致性快照, 元数据查询在快照连接上执行, 并发导出的 worker 使用同一时间点的快照连接
+	var q queryer = db
+	if o.singleTransaction {
+		snap, err := openSnapshot(ctx, db, o)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+		defer snap.Close()
+		defer o.snapshotConns.close()
+		q = snap
+	}
+
+	if len(o.serverVariables) > 0 {
+		o.result.ServerVariables, err = getServerVariables(q, o.serverVariables)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
+	if o.isDropDatabase {
+		o.createDatabaseSQL, err = getCreateDatabaseSQL(q, dbName)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
+
+	// 打印 Header
+	if !o.checkpoint.resumed() {
+		meta := &DumpMeta{Database: dbName, Host: getHostFromDSN(dsn), User: cfg.User, ServerVersion: serverVersion, ServerVariables: o.result.ServerVariables,
+			Options: o.optionNames(), ToolVersion: toolVersion(), Invoker: invoker(), StartTime: start}
+		err = o.formatter.Header(buf, meta)
+		if err == nil && o.hooks.BeforeDump != nil {
+			err = o.hooks.BeforeDump(ctx, buf, meta)
+		}
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
+
+	// 2. 获取表
+	tables, noDataMap, err := selectTables(q, o)
 	if err != nil {
 		log.Printf("[error] %v \n", err)
 		return err
 	}
+	if o.lockNonTransactional {
+		o.nonTransactional, err = getNonTransactionalTables(q)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
 
-	// 2. 获取表
-	var tables []string
-	if o.isAllTable {
-		tmp, err := getAllTables(db)
+	// 增量导出, 没有水位列的表只导出表结构
+	if o.incremental != nil {
+		o.incremental.state, err = LoadIncrementalState(o.incremental.stateFile)
 		if err != nil {
 			log.Printf("[error] %v \n", err)
 			return err
 		}
-		// 排除指定表
-		if len(o.ignoreTables) > 0 {
-			bMap := make(map[string]bool)
-			for _, elementB := range o.ignoreTables {
-				bMap[elementB] = true
+		if o.wheres == nil {
+			o.wheres = make(map[string]string)
+		}
+		for _, table := range tables {
+			if _, ok := o.incremental.columns[table]; !ok {
+				noDataMap[table] = true
+				continue
 			}
-			var result []string
-			for _, elementA := range tmp {
-				if !bMap[elementA] {
-					result = append(result, elementA)
-				}
+			where, err := incrementalWhere(q, o.incremental, table)
+			if err != nil {
+				log.Printf("[error] %v \n", err)
+				return err
 			}
-			tables = result
-		} else {
-			tables = tmp
+			if o.wheres[table] != "" {
+				where = "(" + o.wheres[table] + ") AND " + where
+			}
+			o.wheres[table] = where
 		}
-	} else {
-		tables = o.tables
 	}
 
 	// 3. 导出表
+	var pending []string
 	for _, table := range tables {
-		// 删除表
-		if o.isDropTable {
-			_, _ = buf.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS `%s`;\n", table))
+		// 断点续传, 跳过已完成的表
+		if o.checkpoint.tableDone(table) {
+			log.Printf("[info] [dump] skip table %s, done in checkpoint\n", table)
+			continue
 		}
+		pending = append(pending, table)
+	}
 
-		// 导出表结构
-		err = writeTableStruct(db, table, buf)
+	if o.schemaFirst && !o.noSchema && o.tableWriter == nil {
+		err = writeSchemas(ctx, q, pending, buf, o)
 		if err != nil {
-			log.Printf("[error] %v \n", err)
 			return err
 		}
+	}
 
-		// 导出表数据
-		if o.isData {
-			err = writeTableData(db, table, buf, o.isIgnoreInsert)
+	if o.concurrency > 1 || o.skipFailedTables {
+		err = dumpTablesParallel(ctx, q, pending, buf, counter, noDataMap, o)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, table := range pending {
+			// 定时任务停止等取消时, 不再导出之后的表
+			err = ctx.Err()
 			if err != nil {
 				log.Printf("[error] %v \n", err)
 				return err
 			}
-		}
-	}
-
-	// 导出每个表的结构和数据
-	_, _ = buf.WriteString("-- ----------------------------\n")
-	_, _ = buf.WriteString("-- Dumped by mysqldump\n")
-	_, _ = buf.WriteString("-- Cost Time: " + time.Since(start).String() + "\n")
-	_, _ = buf.WriteString("-- ----------------------------\n")
-	buf.Flush()
-	return nil
-}
+			var result TableResult
+			if w := o.routeTable(table); w != nil {
+				result, err = dumpTableToWriter(ctx, q, table, w, noDataMap[table], o)
+			} else {
+				result, err = dumpTable(ctx, q, table, buf, counter, noDataMap[table], o)
+			}
+			if err != nil {
+				return err
+			}
+			o.result.Tables = append(o.result.Tables, result)
+			o.reportProgress(len(pending), counter.n+int64(buf.Buffered()))
 
-func getCreateTableSQL(db *sql.DB, table string) (string, error) {
-	var createTableSQL string
-	err := db.QueryRow(fmt.Sprintf("SHOW CREATE TABLE `%s`", table)).Scan(&table, &createTableSQL)
-	if err != nil {
-		return "", err
+			err = o.checkpoint.tableFinished(table, buf)
+			if err == nil {
+				err = o.flush.check(buf)
+			}
+			if err != nil {
+				log.Printf("[error] %v \n", err)
+				return err
+			}
+		}
 	}
-	// IF NOT EXISTS
-	createTableSQL = strings.Replace(createTableSQL, "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1)
-	return createTableSQL, nil
-}
 
-func getAllTables(db *sql.DB) ([]string, error) {
-	var tables []string
-	rows, err := db.Query("SHOW TABLES")
-	if err != nil {
-		return nil, err
+	// 导出账号和权限, 只有 MySQL 格式可以恢复
+	if o.grants {
+		switch o.formatter.(type) {
+		case *sqlFormatter, *compatFormatter:
+			err = writeGrants(ctx, db, buf, o)
+			if err != nil {
+				log.Printf("[error] %v \n", err)
+				return err
+			}
+		}
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var table string
-		err = rows.Scan(&table)
+	// 导出每个表的结构和数据
+	meta := &DumpMeta{Database: dbName, Host: getHostFromDSN(dsn), User: cfg.User, ServerVersion: serverVersion, ServerVariables: o.result.ServerVariables,
+		Options: o.optionNames(), ToolVersion: toolVersion(), Invoker: invoker(), StartTime: start, EndTime: time.Now(), Result: o.result}
+	if o.hooks.AfterDump != nil {
+		err = o.hooks.AfterDump(ctx, buf, meta)
 		if err != nil {
-			return nil, err
+			log.Printf("[error] %v \n", err)
+			return err
 		}
-		tables = append(tables, table)
 	}
-	return tables, nil
-}
-
-func writeTableStruct(db *sql.DB, table string, buf *bufio.Writer) error {
-	// 导出表结构
-	_, _ = buf.WriteString("-- ----------------------------\n")
-	_, _ = buf.WriteString(fmt.Sprintf("-- Table structure for %s\n", table))
-	_, _ = buf.WriteString("-- ----------------------------\n")
-
-	createTableSQL, err := getCreateTableSQL(db, table)
+	err = o.formatter.Footer(buf, meta)
 	if err != nil {
 		log.Printf("[error] %v \n", err)
 		return err
 	}
-	_, _ = buf.WriteString(createTableSQL)
-	_, _ = buf.WriteString(";")
-
-	_, _ = buf.WriteString("\n\n")
-	_, _ = buf.WriteString("\n\n")
-	return nil
-}
-
-// 禁止 golangci-lint 检查
-// nolint: gocyclo
-func writeTableData(db *sql.DB, table string, buf *bufio.Writer, ignoreInsert bool) error {
-
-	// 导出表数据
-	_, _ = buf.WriteString("-- ----------------------------\n")
-	_, _ = buf.WriteString(fmt.Sprintf("-- Records of %s\n", table))
-	_, _ = buf.WriteString("-- ----------------------------\n")
-
-	lineRows, err := db.Query(fmt.Sprintf("SELECT * FROM `%s`", table))
+	err = buf.Flush()
 	if err != nil {
 		log.Printf("[error] %v \n", err)
 		return err
 	}
-	defer lineRows.Close()
+	complete = true
 
-	var columns []string
-	columns, err = lineRows.Columns()
-	if err != nil {
-		log.Printf("[error] %v \n", err)
-		return err
-	}
-	columnTypes, err := lineRows.ColumnTypes()
-	if err != nil {
-		log.Printf("[error] %v \n", err)
-		return err
+	if !o.checkpoint.resumed() {
+		o.result.Checksum = hex.EncodeToString(hash.Sum(nil))
 	}
 
-	var values [][]interface{}
-	for lineRows.Next() {
-		row := make([]interface{}, len(columns))
-		rowPointers := make([]interface{}, len(columns))
-		for i := range columns {
-			rowPointers[i] = &row[i]
-		}
-		err = lineRows.Scan(rowPointers...)
+	// 全部写出后才更新增量状态, 只更新本次导出成功的表
+	if o.incremental != nil {
+		o.incremental.finish(o.result.Tables)
+		o.incremental.state.UpdatedAt = start
+		err = saveIncrementalState(o.incremental.stateFile, o.incremental.state)
 		if err != nil {
 			log.Printf("[error] %v \n", err)
 			return err
 		}
-		values = append(values, row)
 	}
 
-	for _, row := range values {
-		ssql := "INSERT INTO `" + table + "` VALUES ("
+	// 导出完成, 删除断点文件
+	err = o.checkpoint.remove()
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
 
-		if ignoreInsert {
-			ssql = "INSERT IGNORE INTO `" + table + "` VALUES ("
+	if o.manifest != "" {
+		o.result.EndTime = time.Now()
+		err = writeManifest(o.manifest, newManifest(o.result, o.optionNames()))
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
 		}
+	}
 
-		for i, col := range row {
-			if col == nil {
-				ssql += "NULL"
-			} else {
-				Type := columnTypes[i].DatabaseTypeName()
-				// 去除 UNSIGNED 和空格
-				Type = strings.Replace(Type, "UNSIGNED", "", -1)
-				Type = strings.Replace(Type, " ", "", -1)
-				switch Type {
-				case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
-					if bs, ok := col.([]byte); ok {
-						ssql += string(bs)
-					} else {
-						ssql += fmt.Sprintf("%d", col)
-					}
-				case "FLOAT", "DOUBLE":
-					if bs, ok := col.([]byte); ok {
-						ssql += string(bs)
-					} else {
-						ssql += fmt.Sprintf("%f", col)
-					}
-				case "DECIMAL", "DEC":
-					ssql += fmt.Sprintf("%s", col)
-
-				case "DATE":
-					t, ok := col.(time.Time)
-					if !ok {
-						log.Println("DATE 类型转换错误")
-						return err
-					}
-					ssql += fmt.Sprintf("'%s'", t.Format("2006-01-02"))
-				case "DATETIME":
-					t, ok := col.(time.Time)
-					if !ok {
-						log.Println("DATETIME 类型转换错误")
-						return err
-					}
-					ssql += fmt.Sprintf("'%s'", t.Format("2006-01-02 15:04:05"))
-				case "TIMESTAMP":
-					t, ok := col.(time.Time)
-					if !ok {
-						log.Println("TIMESTAMP 类型转换错误")
-						return err
-					}
-					ssql += fmt.Sprintf("'%s'", t.Format("2006-01-02 15:04:05"))
-				case "TIME":
-					t, ok := col.([]byte)
-					if !ok {
-						log.Println("TIME 类型转换错误")
-						return err
-					}
-					ssql += fmt.Sprintf("'%s'", string(t))
-				case "YEAR":
-					t, ok := col.([]byte)
-					if !ok {
-						log.Println("YEAR 类型转换错误")
-						return err
-					}
-					ssql += string(t)
-				case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
-					ssql += fmt.Sprintf("'%s'", strings.Replace(fmt.Sprintf("%s", col), "'", "''", -1))
-				case "BIT", "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
-					ssql += fmt.Sprintf("0x%X", col)
-				case "ENUM", "SET":
-					ssql += fmt.Sprintf("'%s'", col)
-				case "BOOL", "BOOLEAN":
-					if col.(bool) {
-						ssql += "true"
-					} else {
-						ssql += "false"
-					}
-				case "JSON":
-					ssql += fmt.Sprintf("'%s'", col)
-				default:
-					// unsupported type
-					log.Printf("unsupported type: %s", Type)
-					return fmt.Errorf("unsupported type: %s", Type)
+	// 记录到目录
+	if o.catalog != nil {
+		o.result.EndTime = time.Now()
+		err = o.catalog.Add(CatalogEntry{
+			Location:       o.catalogLocation,
+			Database:       o.result.Database,
+			Size:           o.result.Bytes,
+			Start:          o.result.StartTime,
+			End:            o.result.EndTime,
+			Duration:       o.result.EndTime.Sub(o.result.StartTime),
+			Checksum:       o.result.Checksum,
+			BinlogFile:     o.result.BinlogFile,
+			BinlogPosition: o.result.BinlogPosition,
+		})
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// selectTables 根据选项获取要导出的表, 以及只导出表结构的表
+func selectTables(db queryer, o *dumpOption) ([]string, map[string]bool, error) {
+	var tables []string
+	if o.isAllTable {
+		tmp, err := getAllTables(db)
+		if err != nil {
+			return nil, nil, err
+		}
+		// 排除指定表
+		if len(o.ignoreTables) > 0 {
+			bMap := make(map[string]bool)
+			for _, elementB := range o.ignoreTables {
+				bMap[elementB] = true
+			}
+			var result []string
+			for _, elementA := range tmp {
+				if !bMap[elementA] {
+					result = append(result, elementA)
 				}
 			}
-			if i < len(row)-1 {
-				ssql += ","
+			tables = result
+		} else {
+			tables = tmp
+		}
+	} else {
+		tables = o.tables
+	}
+
+	// 按存储引擎和大小排除表
+	if len(o.ignoreEngines) > 0 || o.maxTableSize > 0 {
+		var err error
+		tables, err = filterTablesByStatus(db, tables, o.ignoreEngines, o.maxTableSize)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// 只导出结构的表
+	noDataMap := make(map[string]bool)
+	for _, table := range o.noDataTables {
+		noDataMap[table] = true
+	}
+	for table, to := range o.tableOptions {
+		if to.noData {
+			noDataMap[table] = true
+		}
+	}
+
+	// 视图在所有表之后导出, 只导出定义
+	views, err := getViews(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	o.views = views
+	tables = sortViewsLast(tables, views)
+	for table := range views {
+		noDataMap[table] = true
+	}
+
+	// 序列在所有表之前导出, 只导出定义和当前值
+	if o.serverVersion.Raw == "" {
+		o.serverVersion = getServerVersion(db)
+	}
+	o.sequences, err = getSequences(db, o.serverVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	tables = sortSequencesFirst(tables, o.sequences)
+	for table := range o.sequences {
+		noDataMap[table] = true
+	}
+
+	// 子集导出, 不在子集中的表只导出表结构
+	if o.subsetTable != "" {
+		var fks []foreignKey
+		fks, err = getForeignKeys(db)
+		if err != nil {
+			return nil, nil, err
+		}
+		o.wheres = buildSubsetWheres(o.subsetTable, o.subsetWhere, fks)
+		for _, table := range tables {
+			if _, ok := o.wheres[table]; !ok {
+				noDataMap[table] = true
 			}
 		}
-		ssql += ");\n"
-		_, _ = buf.WriteString(ssql)
 	}
 
-	_, _ = buf.WriteString("\n\n")
-	return nil
+	return tables, noDataMap, nil
+}
+
+// dumpTable 导出一个表的结构和数据到 buf, counter 为 buf 底层的计数 writer
+func dumpTable(ctx context.Context, db queryer, table string, buf *bufio.Writer, counter *countWriter, noData bool, o *dumpOption) (TableResult, error) {
+	var err error
+	_, resumed := o.checkpoint.resumeFrom(table)
+	result := TableResult{Name: table}
+	tableStart := time.Now()
+	tableBytes := counter.n + int64(buf.Buffered())
+	meta := &TableMeta{Name: table}
+
+	// 计算表输出的哈希, 需要先写出之前的内容
+	if o.tableChecksums() && !resumed {
+		err = buf.Flush()
+		if err != nil {
+			return result, err
+		}
+		counter.hash = sha256.New()
+		defer func() { counter.hash = nil }()
+	}
+
+	if !resumed && o.hooks.BeforeTable != nil {
+		err = o.hooks.BeforeTable(ctx, buf, meta)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return result, err
+		}
+	}
+
+	// 导出表结构, WithSchemaFirst 时已在所有表的数据之前输出
+	if !resumed && !o.noSchema && !o.schemaWritten[table] {
+		err = writeSchema(ctx, db, table, buf, o)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return result, err
+		}
+	}
+
+	// 导出表数据
+	if o.isData && !noData && !o.views[table] && !o.sequences[table] {
+		_, span := startSpan(ctx, o.tracer, "mysqldump.table_data")
+		span.SetAttribute("db.sql.table", table)
+		dataBytes := counter.n + int64(buf.Buffered())
+		if o.nonTransactional[table] {
+			var conn *dumpConn
+			conn, err = lockTableRead(ctx, o.pool, table, o)
+			if err == nil {
+				result.Rows, err = writeTableData(ctx, conn, table, buf, o)
+				_ = conn.Close()
+			}
+		} else {
+			result.Rows, err = writeTableData(ctx, db, table, buf, o)
+		}
+		span.SetAttribute("mysqldump.rows", result.Rows)
+		span.SetAttribute("mysqldump.bytes", counter.n+int64(buf.Buffered())-dataBytes)
+		endSpan(span, err)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return result, err
+		}
+	}
+
+	result.Duration = time.Since(tableStart)
+	if o.hooks.AfterTable != nil {
+		result.Bytes = counter.n + int64(buf.Buffered()) - tableBytes
+		err = o.hooks.AfterTable(ctx, buf, meta, result)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return result, err
+		}
+	}
+	result.Bytes = counter.n + int64(buf.Buffered()) - tableBytes
+	if counter.hash != nil {
+		err = buf.Flush()
+		if err != nil {
+			return result, err
+		}
+		result.Checksum = hex.EncodeToString(counter.hash.Sum(nil))
+	}
+	return result, nil
+}
+
+// routeTable 返回表的输出, 没有指定时返回 nil
+func (o *dumpOption) routeTable(table string) io.WriteCloser {
+	if o.tableWriter == nil {
+		return nil
+	}
+	return o.tableWriter(table)
+}
+
+// dumpTableToWriter 导出一个表到单独的 writer, 结束后关闭
+func dumpTableToWriter(ctx context.Context, db queryer, table string, w io.WriteCloser, noData bool, o *dumpOption) (TableResult, error) {
+	counter := &countWriter{w: w}
+	tableBuf := o.newBuffer(counter)
+	result, err := dumpTable(ctx, db, table, tableBuf, counter, noData, o)
+	if err == nil {
+		err = tableBuf.Flush()
+	}
+	cerr := w.Close()
+	if err == nil {
+		err = cerr
+	}
+	return result, err
+}
+
+// getCreateDatabaseSQL 返回 SHOW CREATE DATABASE 的结果, 包含字符集
+func getCreateDatabaseSQL(db queryer, database string) (string, error) {
+	var createDatabaseSQL string
+	err := db.QueryRow("SHOW CREATE DATABASE "+QuoteIdentifier(database)).Scan(&database, &createDatabaseSQL)
+	if err != nil {
+		return "", err
+	}
+	return createDatabaseSQL, nil
+}
+
+func getCreateTableSQL(db queryer, table string, o *dumpOption) (string, error) {
+	var createTableSQL string
+	err := db.QueryRow("SHOW CREATE TABLE "+QuoteIdentifier(table)).Scan(&table, &createTableSQL)
+	if err != nil {
+		return "", err
+	}
+	if !o.noIfNotExists {
+		createTableSQL = addIfNotExists(createTableSQL)
+	}
+	if o.noAutoIncrementValue {
+		createTableSQL = stripAutoIncrement(createTableSQL)
+	}
+	if o.noPartitions {
+		createTableSQL = stripPartitions(createTableSQL)
+	}
+	return createTableSQL, nil
+}
+
+func getAllTables(db queryer) ([]string, error) {
+	var tables []string
+	rows, err := db.Query("SHOW TABLES")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table string
+		err = rows.Scan(&table)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// getColumns 按定义顺序获取表的列名
+func getColumns(db queryer, table string) ([]string, error) {
+	rows, err := db.Query("SELECT COLUMN_NAME FROM information_schema.COLUMNS "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		err = rows.Scan(&column)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// getPrimaryKey 按顺序获取表的主键列
+func getPrimaryKey(db queryer, table string) ([]string, error) {
+	rows, err := db.Query("SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY' ORDER BY ORDINAL_POSITION", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		err = rows.Scan(&column)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// excludeColumns 从 columns 中排除 omit 中的列, 列名不区分大小写
+func excludeColumns(columns []string, omit []string) []string {
+	omitMap := make(map[string]bool)
+	for _, column := range omit {
+		omitMap[strings.ToLower(column)] = true
+	}
+	var result []string
+	for _, column := range columns {
+		if !omitMap[strings.ToLower(column)] {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+type tableStatus struct {
+	engine string
+	size   int64
+}
+
+func getTableStatus(db queryer) (map[string]tableStatus, error) {
+	rows, err := db.Query("SELECT TABLE_NAME, IFNULL(ENGINE, ''), IFNULL(DATA_LENGTH, 0) + IFNULL(INDEX_LENGTH, 0) " +
+		"FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	status := make(map[string]tableStatus)
+	for rows.Next() {
+		var name string
+		var st tableStatus
+		err = rows.Scan(&name, &st.engine, &st.size)
+		if err != nil {
+			return nil, err
+		}
+		status[name] = st
+	}
+	return status, rows.Err()
+}
+
+// filterTablesByStatus 根据 information_schema.TABLES 排除指定引擎和超过大小的表
+func filterTablesByStatus(db queryer, tables []string, ignoreEngines []string, maxSize int64) ([]string, error) {
+	status, err := getTableStatus(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, table := range tables {
+		st, ok := status[table]
+		if !ok {
+			result = append(result, table)
+			continue
+		}
+		ignored := false
+		for _, engine := range ignoreEngines {
+			if strings.EqualFold(engine, st.engine) {
+				ignored = true
+				break
+			}
+		}
+		if ignored {
+			log.Printf("[info] [dump] skip table %s, engine %s\n", table, st.engine)
+			continue
+		}
+		if maxSize > 0 && st.size > maxSize {
+			log.Printf("[info] [dump] skip table %s, size %d > %d\n", table, st.size, maxSize)
+			continue
+		}
+		result = append(result, table)
+	}
+	return result, nil
+}
+
+func writeTableStruct(db queryer, table string, buf *bufio.Writer, o *dumpOption) error {
+	createTableSQL, err := getCreateTableSQL(db, table, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	return o.formatter.TableSchema(buf, &TableMeta{Name: table, CreateSQL: createTableSQL})
+}
+
+func writeTableData(ctx context.Context, db queryer, table string, buf *bufio.Writer, o *dumpOption) (int64, error) {
+
+	// 断点续传时, 未完成的表从上次的位置继续导出
+	last, resumed := o.checkpoint.resumeFrom(table)
+
+	selectList, partial, conds, err := buildTableSelect(db, table, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return 0, err
+	}
+
+	pks, err := getPrimaryKey(db, table)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return 0, err
+	}
+
+	// 分块导出, 只支持单列主键的表, TiDB 没有聚簇主键的表使用 _tidb_rowid
+	var pk string
+	var hiddenPK bool
+	chunkSize := o.tableChunkSize(table)
+	if chunkSize > 0 {
+		if len(pks) == 1 {
+			pk = pks[0]
+		}
+		if o.tidb {
+			hiddenPK, err = hasTiDBRowID(db, table)
+			if err != nil {
+				log.Printf("[error] %v \n", err)
+				return 0, err
+			}
+			if hiddenPK {
+				pk = tidbRowID
+				selectList += ", " + QuoteIdentifier(tidbRowID)
+			}
+		}
+	}
+	o.warnPrimaryKey(table, pks, chunkSize > 0 && pk == "")
+
+	w := &tableDataWriter{
+		meta:      &TableMeta{Name: table, PartialColumns: partial},
+		pk:        pk,
+		hiddenPK:  hiddenPK,
+		chunkSize: chunkSize,
+		buf:       buf,
+		o:         o,
+		resumed:   resumed,
+		flush:     flushTimer{interval: o.flushInterval},
+	}
+
+	// 分区表按分区并发导出, 断点续传时按整表继续
+	var partitions []string
+	// 加了表锁的表只能在一个连接上读取
+	if o.partitionConcurrency > 1 && !resumed && !o.nonTransactional[table] {
+		partitions, err = getPartitions(db, table)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return 0, err
+		}
+	}
+	if len(partitions) > 1 {
+		err = writePartitionsData(ctx, db, partitions, selectList, conds, w)
+	} else {
+		err = w.writeChunks(db, selectList, conds, last)
+	}
+	if err != nil {
+		return w.rows, err
+	}
+
+	err = o.formatter.TableDataEnd(buf, w.meta)
+	if err != nil {
+		return w.rows, err
+	}
+	return w.rows, nil
+}
+
+// writeChunks 查询表或分区的数据, 有分块列时按分块查询, last 为断点续传时上次导出的位置的 SQL 字面量
+func (w *tableDataWriter) writeChunks(db queryer, selectList string, conds []string, last string) error {
+	resumed := w.resumed
+	from := QuoteIdentifier(w.meta.Name)
+	if w.partition != "" {
+		from += " PARTITION (" + QuoteIdentifier(w.partition) + ")"
+	}
+	for {
+		chunkConds := conds
+		if w.pk != "" && resumed && last != "" {
+			chunkConds = append(chunkConds[:len(chunkConds):len(chunkConds)], fmt.Sprintf("%s > %s", QuoteIdentifier(w.pk), last))
+		}
+		query := fmt.Sprintf("%s %s FROM %s", w.o.selectKeyword(), selectList, from)
+		if len(chunkConds) > 0 {
+			query += " WHERE " + strings.Join(chunkConds, " AND ")
+		}
+		if w.pk != "" {
+			query += fmt.Sprintf(" ORDER BY %s LIMIT %d", QuoteIdentifier(w.pk), w.chunkSize)
+		}
+
+		n, err := w.writeRows(db, query)
+		if err != nil {
+			return err
+		}
+		if w.pk == "" || n < w.chunkSize {
+			return nil
+		}
+
+		// 记录分块位置, 并发导出, 按表输出或按分区导出时只记录完成的表
+		last, resumed = w.last, true
+		if w.o.isolated() || w.partition != "" {
+			continue
+		}
+		err = w.o.checkpoint.chunkDone(w.meta.Name, last, w.buf)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
+}
+
+// buildTableSelect 返回导出表数据的查询列, 是否只查询部分列和查询条件
+// 按 information_schema 中的定义顺序列出查询的列, 生成列的值不能写入, 不导出, 不可见列与其他列一起导出
+func buildTableSelect(db queryer, table string, o *dumpOption) (string, bool, []string, error) {
+	columns, err := getTableColumns(db, table)
+	if err != nil {
+		return "", false, nil, err
+	}
+	o.warnGeneratedColumns(table, columns)
+	selectColumns, partial := dataColumns(columns, o.omitColumns[table])
+	if len(selectColumns) == 0 && len(columns) > 0 {
+		return "", false, nil, fmt.Errorf("table %s: all columns are omitted", table)
+	}
+
+	// 查不到列信息时使用 SELECT *
+	selectList := "*"
+	if len(selectColumns) > 0 {
+		selectList = quoteColumns(selectColumns)
+	}
+	return selectList, partial, tableConds(table, o), nil
+}
+
+// tableConds 返回导出表数据的查询条件
+func tableConds(table string, o *dumpOption) []string {
+	var conds []string
+	if where := o.wheres[table]; where != "" {
+		conds = append(conds, "("+where+")")
+	}
+	if where := o.tableWhere(table); where != "" {
+		conds = append(conds, "("+where+")")
+	}
+	// 按比例抽样
+	if rate, ok := o.sampleRates[table]; ok && rate > 0 && rate < 1 {
+		conds = append(conds, fmt.Sprintf("RAND() < %g", rate))
+	}
+	return conds
+}
+
+// tableDataWriter 将查询结果写为 INSERT 语句
+type tableDataWriter struct {
+	meta *TableMeta
+	// 断点续传时已写出 TableDataBegin
+	resumed bool
+	// 已写出 TableDataBegin
+	begun bool
+	// 分块的主键列, 为空时不分块
+	pk        string
+	chunkSize int
+	// 分块列是额外查询的 _tidb_rowid, 在结果的最后一列, 不输出
+	hiddenPK bool
+	// 按分区导出时查询的分区
+	partition string
+	buf       *bufio.Writer
+	o         *dumpOption
+
+	// 已扫描的行数, 用于抽样
+	rowIndex int
+	// 已写出的行数
+	rows int64
+	// 最后一行的主键值的 SQL 字面量, 见 pkLiteral
+	last string
+
+	transforms []ColumnTransform
+	// 写出行之后的定时写出
+	flush flushTimer
+}
+
+// writeRows 执行查询并写出所有行, 返回扫描的行数
+func (w *tableDataWriter) writeRows(db queryer, query string) (int, error) {
+	lineRows, err := db.Query(query)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return 0, err
+	}
+	defer lineRows.Close()
+
+	columns, err := lineRows.Columns()
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return 0, err
+	}
+	columnTypes, err := lineRows.ColumnTypes()
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return 0, err
+	}
+
+	pkIndex := -1
+	dataTypes := make([]string, len(columnTypes))
+	for i, columnType := range columnTypes {
+		dataTypes[i] = columnType.DatabaseTypeName()
+		if w.pk != "" && strings.EqualFold(columns[i], w.pk) {
+			pkIndex = i
+		}
+	}
+	// 扫描全部列, 输出时去掉最后的 _tidb_rowid
+	scanColumns := len(columns)
+	if w.hiddenPK {
+		pkIndex = len(columns) - 1
+		columns, dataTypes = columns[:pkIndex], dataTypes[:pkIndex]
+	}
+	// 每个分块的列相同, 只计算一次
+	if !w.begun {
+		w.transforms = getColumnTransforms(w.o, w.meta.Name, columns, dataTypes)
+		w.meta.Columns = columns
+		w.meta.DataTypes = dataTypes
+		w.begun = true
+		if !w.resumed {
+			err = w.o.formatter.TableDataBegin(w.buf, w.meta)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	transforms := w.transforms
+
+	// 驱动以 []byte 返回的列扫描到 sql.RawBytes, 直接使用驱动的缓冲, 不为每个值复制内容
+	scanRow := make([]interface{}, scanColumns)
+	rawRow := make([]sql.RawBytes, scanColumns)
+	var rawColumns []int
+	rowPointers := make([]interface{}, scanColumns)
+	for i := range scanRow {
+		if scanRawBytes(columnTypes[i].DatabaseTypeName()) {
+			rawColumns = append(rawColumns, i)
+			rowPointers[i] = &rawRow[i]
+		} else {
+			rowPointers[i] = &scanRow[i]
+		}
+	}
+	row := scanRow[:len(columns)]
+
+	n := 0
+	for lineRows.Next() {
+		err = lineRows.Scan(rowPointers...)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return n, err
+		}
+		for _, i := range rawColumns {
+			if rawRow[i] == nil {
+				scanRow[i] = nil
+			} else {
+				scanRow[i] = []byte(rawRow[i])
+			}
+		}
+		n++
+		if pkIndex >= 0 {
+			w.last = pkLiteral(scanRow[pkIndex], columnTypes[pkIndex].DatabaseTypeName())
+		}
+
+		// 每 n 行抽样一行
+		w.rowIndex++
+		if w.o.sampleEvery > 1 && (w.rowIndex-1)%w.o.sampleEvery != 0 {
+			continue
+		}
+		for i, transform := range transforms {
+			if transform != nil {
+				row[i] = transform(row[i])
+			}
+		}
+
+		err = w.o.formatter.Row(w.buf, w.meta, row)
+		if err == nil {
+			err = w.flush.check(w.buf)
+		}
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return n, err
+		}
+		w.rows++
+	}
+	return n, lineRows.Err()
+}
+
+// scanRawBytes 驱动总是以 []byte 返回的类型, 可以扫描到 sql.RawBytes
+// 整数, 浮点数和 parseTime 时的日期由驱动转换为其他类型, 扫描到 sql.RawBytes 会改变格式
+func scanRawBytes(dataType string) bool {
+	switch dataType {
+	case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET", "JSON",
+		"BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BIT",
+		"DECIMAL", "TIME", "GEOMETRY", "VECTOR":
+		return true
+	}
+	return false
+}
+
+// pkLiteral 分块位置的 SQL 字面量
+// 整数不加引号, 与字符串比较时 MySQL 按 double 比较, 超过 2^53 的 BIGINT 会跳过或重复; 时间保留微秒, DATETIME(6) 的主键不丢失精度
+func pkLiteral(value interface{}, dataType string) string {
+	unsigned := strings.Contains(dataType, "UNSIGNED")
+	switch v := value.(type) {
+	case int64, uint64:
+		if s, err := formatInteger(v, unsigned); err == nil {
+			return s
+		}
+	case []byte:
+		switch strings.TrimSpace(strings.Replace(dataType, "UNSIGNED", "", -1)) {
+		case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+			return string(v)
+		}
+	case time.Time:
+		return string(appendQuotedTime(nil, v, "2006-01-02 15:04:05.999999"))
+	}
+	return "'" + EscapeString(valueToString(value)) + "'"
+}
+
+// formatInteger 格式化整数列的值, 不经过 %d 以保证 UNSIGNED BIGINT 的值不变
+// 驱动以 int64 返回超过 int64 范围的 UNSIGNED BIGINT 时为负数, unsigned 为 true 时按 uint64 解释
+func formatInteger(col interface{}, unsigned bool) (string, error) {
+	b, err := appendInteger(nil, col, unsigned)
+	return string(b), err
+}
+
+// appendInteger 将 formatInteger 的结果追加到 dst
+func appendInteger(dst []byte, col interface{}, unsigned bool) ([]byte, error) {
+	switch v := col.(type) {
+	case []byte:
+		return append(dst, v...), nil
+	case string:
+		return append(dst, v...), nil
+	case int64:
+		if unsigned && v < 0 {
+			return strconv.AppendUint(dst, uint64(v), 10), nil
+		}
+		return strconv.AppendInt(dst, v, 10), nil
+	case uint64:
+		return strconv.AppendUint(dst, v, 10), nil
+	case int, int8, int16, int32, uint, uint8, uint16, uint32:
+		return fmt.Appendf(dst, "%d", v), nil
+	default:
+		return dst, fmt.Errorf("INT 类型转换错误")
+	}
+}
+
+// formatFloat 以最短的能精确还原的形式格式化浮点数列的值, 可能为科学计数法, 如 1e-07
+// float32 按 32 位格式化, 避免 FLOAT 的值转换为 float64 后多出的尾数
+func formatFloat(col interface{}) (string, error) {
+	b, err := appendFloat(nil, col)
+	return string(b), err
+}
+
+// appendFloat 将 formatFloat 的结果追加到 dst
+func appendFloat(dst []byte, col interface{}) ([]byte, error) {
+	switch v := col.(type) {
+	case []byte:
+		return append(dst, v...), nil
+	case string:
+		return append(dst, v...), nil
+	case float32:
+		return strconv.AppendFloat(dst, float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.AppendFloat(dst, v, 'g', -1, 64), nil
+	default:
+		return dst, fmt.Errorf("FLOAT 类型转换错误")
+	}
+}
+
+// ErrUnsupportedType FormatValue 不支持的列类型, 导出时使用 appendFallbackValue 并产生警告
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// FormatValue 将驱动返回的值格式化为 SQL 字面量, Type 为 DatabaseTypeName 返回的类型名
+func FormatValue(col interface{}, Type string) (string, error) {
+	b, err := appendValue(nil, col, Type)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// appendValue 将 FormatValue 的结果追加到 dst, 导出时复用 dst 减少每个值的内存分配
+// 出错时返回的 dst 可能包含部分内容
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func appendValue(dst []byte, col interface{}, Type string) ([]byte, error) {
+	if col == nil {
+		return append(dst, "NULL"...), nil
+	}
+	// 驱动不返回 string, string 来自 ColumnTransform, 如 MaskHash 用于 INT 或 DATE 列, 不论列类型都作为字符串输出
+	if s, ok := col.(string); ok {
+		return appendQuotedText(dst, s), nil
+	}
+	// 去除 UNSIGNED 和空格, 不包含时不分配内存
+	unsigned := strings.Contains(Type, "UNSIGNED")
+	Type = strings.Replace(Type, "UNSIGNED", "", -1)
+	Type = strings.Replace(Type, " ", "", -1)
+	switch Type {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+		return appendInteger(dst, col, unsigned)
+	case "FLOAT", "DOUBLE":
+		return appendFloat(dst, col)
+	case "DECIMAL", "DEC":
+		return appendText(dst, col), nil
+	case "DATE":
+		t, ok := col.(time.Time)
+		if !ok {
+			return dst, fmt.Errorf("DATE 类型转换错误")
+		}
+		return appendQuotedTime(dst, t, "2006-01-02"), nil
+	case "DATETIME":
+		t, ok := col.(time.Time)
+		if !ok {
+			return dst, fmt.Errorf("DATETIME 类型转换错误")
+		}
+		return appendQuotedTime(dst, t, "2006-01-02 15:04:05"), nil
+	case "TIMESTAMP":
+		t, ok := col.(time.Time)
+		if !ok {
+			return dst, fmt.Errorf("TIMESTAMP 类型转换错误")
+		}
+		return appendQuotedTime(dst, t, "2006-01-02 15:04:05"), nil
+	case "TIME":
+		t, ok := col.([]byte)
+		if !ok {
+			return dst, fmt.Errorf("TIME 类型转换错误")
+		}
+		dst = append(dst, '\'')
+		dst = append(dst, t...)
+		return append(dst, '\''), nil
+	case "YEAR":
+		switch t := col.(type) {
+		case []byte:
+			return append(dst, t...), nil
+		case int64:
+			return strconv.AppendInt(dst, t, 10), nil
+		}
+		return dst, fmt.Errorf("YEAR 类型转换错误")
+	case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET", "JSON":
+		// SET 的多个值以逗号分隔, 原样保留
+		return appendQuotedText(dst, col), nil
+	case "BIT", "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "VECTOR":
+		bs, ok := col.([]byte)
+		if !ok {
+			return fmt.Appendf(dst, "0x%X", col), nil
+		}
+		// 空值不能写为 0x
+		if len(bs) == 0 {
+			return append(dst, "''"...), nil
+		}
+		return appendHex(append(dst, "0x"...), bs), nil
+	case "BOOL", "BOOLEAN":
+		b, ok := col.(bool)
+		if !ok {
+			return dst, fmt.Errorf("BOOL 类型转换错误")
+		}
+		return strconv.AppendBool(dst, b), nil
+	default:
+		// unsupported type
+		return dst, fmt.Errorf("%w: %s", ErrUnsupportedType, Type)
+	}
+}
+
+// appendText 按 %s 追加值, []byte 和 string 不经过 fmt
+func appendText(dst []byte, col interface{}) []byte {
+	switch v := col.(type) {
+	case []byte:
+		return append(dst, v...)
+	case string:
+		return append(dst, v...)
+	default:
+		return fmt.Appendf(dst, "%s", col)
+	}
+}
+
+// appendQuotedText 追加转义后以单引号包裹的值
+func appendQuotedText(dst []byte, col interface{}) []byte {
+	dst = append(dst, '\'')
+	switch v := col.(type) {
+	case []byte:
+		dst = appendEscaped(dst, v)
+	case string:
+		dst = appendEscaped(dst, v)
+	default:
+		dst = appendEscaped(dst, fmt.Sprintf("%s", col))
+	}
+	return append(dst, '\'')
+}
+
+func appendQuotedTime(dst []byte, t time.Time, layout string) []byte {
+	dst = append(dst, '\'')
+	dst = t.AppendFormat(dst, layout)
+	return append(dst, '\'')
+}
+
+// appendHex 追加大写的十六进制, 与 %X 相同
+func appendHex(dst []byte, b []byte) []byte {
+	const digits = "0123456789ABCDEF"
+	for _, c := range b {
+		dst = append(dst, digits[c>>4], digits[c&0x0f])
+	}
+	return dst
 }
@@ -0,0 +1,318 @@
+package mysqldump
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithNoDataTables(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	out, err := fakeDump(db, WithAllTable(), WithData(), WithNoDataTables("b"))
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	for _, want := range []string{"CREATE TABLE IF NOT EXISTS `a`", "INSERT INTO `a` VALUES (1);", "CREATE TABLE IF NOT EXISTS `b`"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dump() output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "INSERT INTO `b`") {
+		t.Errorf("Dump() dumped data of no-data table b:\n%s", out)
+	}
+}
+
+func Test_filterTablesByStatus(t *testing.T) {
+	db, f := newFakeDB(t)
+	f.on("information_schema.TABLES", []string{"name VARCHAR", "engine VARCHAR", "size BIGINT"},
+		[]driver.Value{[]byte("orders"), []byte("InnoDB"), int64(100)},
+		[]driver.Value{[]byte("cache"), []byte("MEMORY"), int64(10)},
+		[]driver.Value{[]byte("logs"), []byte("InnoDB"), int64(5000)})
+	tests := []struct {
+		name    string
+		engines []string
+		maxSize int64
+		want    []string
+	}{
+		{name: "engine", engines: []string{"memory"}, want: []string{"orders", "logs", "new"}},
+		{name: "size", maxSize: 1000, want: []string{"orders", "cache", "new"}},
+		{name: "both", engines: []string{"MEMORY"}, maxSize: 1000, want: []string{"orders", "new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 查不到状态的表保留
+			got, err := filterTablesByStatus(db, []string{"orders", "cache", "logs", "new"}, tt.engines, tt.maxSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("filterTablesByStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithOmitColumns(t *testing.T) {
+	db, f := newFakeDB(t)
+	f.on("SELECT VERSION()", []string{"version VARCHAR"}, []driver.Value{[]byte("8.0.36")})
+	f.on("TABLE_TYPE = 'VIEW'", []string{"table VARCHAR"})
+	f.on("SHOW CREATE TABLE `users`", []string{"table VARCHAR", "create VARCHAR"},
+		[]driver.Value{[]byte("users"), []byte("CREATE TABLE `users` (`id` int, `secret` varchar(10))")})
+	f.on("information_schema.COLUMNS", []string{"name VARCHAR", "extra VARCHAR"},
+		[]driver.Value{[]byte("id"), []byte("")}, []driver.Value{[]byte("secret"), []byte("")})
+	f.on("CONSTRAINT_NAME = 'PRIMARY'", []string{"name VARCHAR"}, []driver.Value{[]byte("id")})
+	f.on("SELECT `id` FROM `users`", []string{"id INT"}, []driver.Value{int64(1)})
+
+	out, err := fakeDump(db, WithTables("users"), WithData(), WithOmitColumns(map[string][]string{"users": {"SECRET"}}))
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if want := "INSERT INTO `users` (`id`) VALUES (1);"; !strings.Contains(out, want) {
+		t.Errorf("Dump() output does not contain %q:\n%s", want, out)
+	}
+
+	// 排除全部列时报错
+	o := newDumpOption([]DumpOption{WithOmitColumns(map[string][]string{"users": {"id", "secret"}})})
+	if _, _, _, err = buildTableSelect(db, "users", o); err == nil {
+		t.Error("buildTableSelect() error = nil, want all columns omitted")
+	}
+}
+
+func TestWithSample(t *testing.T) {
+	db, f := newFakeDB(t)
+	var rows [][]driver.Value
+	for i := int64(1); i <= 5; i++ {
+		rows = append(rows, []driver.Value{i})
+	}
+	f.on("SELECT `id` FROM `a`", []string{"id INT"}, rows...)
+
+	o := newDumpOption([]DumpOption{WithSampleEvery(2), WithCompact()})
+	var sb strings.Builder
+	w := &tableDataWriter{meta: &TableMeta{Name: "a"}, buf: bufio.NewWriter(&sb), o: o}
+	n, err := w.writeRows(db, "SELECT `id` FROM `a`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.buf.Flush()
+	want := "INSERT INTO `a` VALUES (1);\nINSERT INTO `a` VALUES (3);\nINSERT INTO `a` VALUES (5);\n"
+	if n != 5 || !strings.HasSuffix(sb.String(), want) {
+		t.Errorf("writeRows() = %d, output %q, want 5 rows scanned and %q", n, sb.String(), want)
+	}
+
+	// 比例在 (0, 1) 之外时不抽样
+	o = newDumpOption([]DumpOption{WithSampleRate("a", 0.25), WithSampleRate("b", 1)})
+	if got := tableConds("a", o); strings.Join(got, " AND ") != "RAND() < 0.25" {
+		t.Errorf("tableConds(a) = %v", got)
+	}
+	if got := tableConds("b", o); len(got) != 0 {
+		t.Errorf("tableConds(b) = %v, want none", got)
+	}
+}
+
+func Test_pkLiteral(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		dataType string
+		want     string
+	}{
+		{value: int64(9007199254740993), dataType: "BIGINT", want: "9007199254740993"},
+		{value: int64(-2), dataType: "UNSIGNED BIGINT", want: "18446744073709551614"},
+		{value: []byte("9007199254740993"), dataType: "BIGINT", want: "9007199254740993"},
+		{value: time.Date(2024, 1, 2, 3, 4, 5, 1000, time.UTC), dataType: "DATETIME", want: "'2024-01-02 03:04:05.000001'"},
+		{value: []byte("2024-01-02 03:04:05.000001"), dataType: "DATETIME", want: "'2024-01-02 03:04:05.000001'"},
+		{value: []byte("it's"), dataType: "VARCHAR", want: `'it\'s'`},
+	}
+	for _, tt := range tests {
+		if got := pkLiteral(tt.value, tt.dataType); got != tt.want {
+			t.Errorf("pkLiteral(%v, %s) = %s, want %s", tt.value, tt.dataType, got, tt.want)
+		}
+	}
+}
+
+func Test_tableDataWriter_chunkCursor(t *testing.T) {
+	db, f := newFakeDB(t)
+	// 第一个分块满, 第二个分块不满时结束
+	f.on("`id` > 9007199254740993", []string{"id BIGINT"}, []driver.Value{int64(9007199254740995)})
+	f.on("SELECT `id` FROM `a`", []string{"id BIGINT"},
+		[]driver.Value{int64(9007199254740992)}, []driver.Value{int64(9007199254740993)})
+
+	o := newDumpOption([]DumpOption{WithCompact()})
+	var sb strings.Builder
+	w := &tableDataWriter{meta: &TableMeta{Name: "a"}, pk: "id", chunkSize: 2, buf: bufio.NewWriter(&sb), o: o}
+	if err := w.writeChunks(db, "`id`", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	queries := f.executed()
+	if want := "SELECT `id` FROM `a` WHERE `id` > 9007199254740993 ORDER BY `id` LIMIT 2"; len(queries) != 2 || queries[1] != want {
+		t.Errorf("queries = %q, want second %q", queries, want)
+	}
+}
+
+// closeRecorder 记录内容和是否关闭的 io.WriteCloser
+type closeRecorder struct {
+	strings.Builder
+	closed bool
+}
+
+func (w *closeRecorder) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestWithTableWriter(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	a := &closeRecorder{}
+	out, err := fakeDump(db, WithData(), WithTableWriter(func(table string) io.WriteCloser {
+		// b 写到 WithWriter 指定的 writer
+		if table == "a" {
+			return a
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if !strings.Contains(a.String(), "CREATE TABLE IF NOT EXISTS `a`") || !strings.Contains(a.String(), "INSERT INTO `a` VALUES (1);") || !a.closed {
+		t.Errorf("table writer of a = %q, closed = %v", a.String(), a.closed)
+	}
+	if strings.Contains(out, "`a`") {
+		t.Errorf("Dump() output contains table a:\n%s", out)
+	}
+	for _, want := range []string{"INSERT INTO `b` VALUES (1);", dumpCompletedMarker} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dump() output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWithSkipFailedTables(t *testing.T) {
+	db, f := newFakeDumpDB(t)
+	f.fail("SELECT `id` FROM `b`", errors.New("table b is corrupted"))
+	f.on("SELECT MAX(`id`)", []string{"max INT"}, []driver.Value{int64(2)})
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	var result DumpResult
+	out, err := fakeDump(db, WithAllTable(), WithData(), WithSkipFailedTables(), WithResult(&result),
+		WithIncremental(stateFile, map[string]string{"a": "id", "b": "id"}))
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	for _, want := range []string{"REPLACE INTO `a` VALUES (2);", "-- Table b skipped: table b is corrupted", dumpCompletedMarker} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dump() output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "INTO `b`") {
+		t.Errorf("Dump() output contains data of the failed table:\n%s", out)
+	}
+	if len(result.FailedTables) != 1 || result.FailedTables[0].Name != "b" {
+		t.Errorf("FailedTables = %+v, want b", result.FailedTables)
+	}
+
+	// 失败的表不更新水位, 下次增量导出仍从上次的位置开始
+	state, err := LoadIncrementalState(stateFile)
+	if err != nil {
+		t.Fatalf("LoadIncrementalState() error = %v", err)
+	}
+	if _, ok := state.Tables["a"]; !ok {
+		t.Errorf("state.Tables = %v, want a watermark for a", state.Tables)
+	}
+	if _, ok := state.Tables["b"]; ok {
+		t.Errorf("state.Tables = %v, want no watermark for the failed table b", state.Tables)
+	}
+}
+
+func TestWithSkipFailedTables_checkpoint(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "dump.sql")
+	cpFile := filepath.Join(dir, "checkpoint.json")
+	dump := func(db *sql.DB, opts ...DumpOption) error {
+		file, err := os.OpenFile(output, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		o := newDumpOption(append([]DumpOption{WithAllTable(), WithData(), WithSkipFailedTables(),
+			WithCheckpoint(cpFile), WithWriter(file)}, opts...))
+		o.sharedPool = db
+		return runDump(context.Background(), "root@tcp(127.0.0.1:1)/test", o)
+	}
+
+	// b 失败后导出中断, 断点只记录完成的 a
+	db, f := newFakeDumpDB(t)
+	f.fail("SELECT `id` FROM `b`", errors.New("table b is corrupted"))
+	err := dump(db, WithHooks(Hooks{AfterDump: func(context.Context, io.Writer, *DumpMeta) error {
+		return errors.New("interrupted")
+	}}))
+	if err == nil {
+		t.Fatal("first dump error = nil, want the hook error")
+	}
+	data, err := os.ReadFile(cpFile)
+	if err != nil {
+		t.Fatalf("checkpoint not kept after a failed dump: %v", err)
+	}
+	var state checkpointState
+	if err = json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if !state.Done["a"] || state.Done["b"] {
+		t.Errorf("checkpoint Done = %v, want only a", state.Done)
+	}
+
+	// 重新执行只导出 b, a 不重复
+	db, _ = newFakeDumpDB(t)
+	if err = dump(db); err != nil {
+		t.Fatalf("resumed dump error = %v", err)
+	}
+	data, err = os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if n := strings.Count(out, "INSERT INTO `a`"); n != 2 {
+		t.Errorf("output contains %d inserts into a, want 2:\n%s", n, out)
+	}
+	for _, want := range []string{"INSERT INTO `b` VALUES", dumpCompletedMarker} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if _, err = os.Stat(cpFile); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after a complete dump, stat error = %v", err)
+	}
+}
+
+func TestWithTee(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	var tee strings.Builder
+	out, err := fakeDump(db, WithAllTable(), WithData(), WithTee(&tee))
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if tee.String() != out {
+		t.Errorf("tee got:\n%s\nwant the dump output:\n%s", tee.String(), out)
+	}
+}
+
+func TestWithTee_writeFailure(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	out, err := fakeDump(db, WithAllTable(), WithData(), WithTee(errWriter{}))
+	if err == nil {
+		t.Fatal("Dump() error = nil, want the tee write error")
+	}
+	// tee 失败时主输出不能看起来是完整的
+	if strings.Contains(out, dumpCompletedMarker) {
+		t.Errorf("output of a failed dump is marked complete:\n%s", out)
+	}
+	if !strings.Contains(out, incompleteMarker+": disk full") {
+		t.Errorf("output of a failed dump has no incomplete marker:\n%s", out)
+	}
+}
@@ -0,0 +1,146 @@
+package mysqldump
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Notifier 导出结束后的通知, err 为 nil 表示导出成功
+type Notifier interface {
+	Notify(result *DumpResult, err error) error
+}
+
+// NotifierFunc 函数形式的 Notifier
+type NotifierFunc func(result *DumpResult, err error) error
+
+func (f NotifierFunc) Notify(result *DumpResult, err error) error {
+	return f(result, err)
+}
+
+// WithNotifier 导出成功或失败后发送通知, 通知失败只打印日志
+func WithNotifier(notifiers ...Notifier) DumpOption {
+	return func(option *dumpOption) {
+		option.notifiers = append(option.notifiers, notifiers...)
+	}
+}
+
+// NotifyPayload webhook 通知的内容
+type NotifyPayload struct {
+	// success 或 failure
+	Status string      `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	Result *DumpResult `json:"result"`
+}
+
+func newNotifyPayload(result *DumpResult, err error) NotifyPayload {
+	payload := NotifyPayload{Status: "success", Result: result}
+	if err != nil {
+		payload.Status = "failure"
+		payload.Error = err.Error()
+	}
+	return payload
+}
+
+// notifySummary 通知的文本内容
+func notifySummary(result *DumpResult, err error) string {
+	if err != nil {
+		return fmt.Sprintf("mysqldump %s failed after %s: %v", result.Database, result.EndTime.Sub(result.StartTime), err)
+	}
+	return fmt.Sprintf("mysqldump %s succeeded in %s, %d bytes", result.Database, result.EndTime.Sub(result.StartTime), result.Bytes)
+}
+
+// WebhookNotifier 以 JSON (NotifyPayload) POST 到 URL
+type WebhookNotifier struct {
+	URL    string
+	Header http.Header
+	// 为 nil 时使用 10 秒超时的 http.Client
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(result *DumpResult, err error) error {
+	body, merr := json.Marshal(newNotifyPayload(result, err))
+	if merr != nil {
+		return merr
+	}
+	return postJSON(n.Client, n.URL, n.Header, body)
+}
+
+// SlackNotifier 通过 Slack incoming webhook 发送通知
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (n *SlackNotifier) Notify(result *DumpResult, err error) error {
+	text := notifySummary(result, err)
+	if err != nil {
+		text = ":x: " + text
+	} else {
+		text = ":white_check_mark: " + text
+	}
+	body, merr := json.Marshal(map[string]string{"text": text})
+	if merr != nil {
+		return merr
+	}
+	return postJSON(n.Client, n.WebhookURL, nil, body)
+}
+
+func postJSON(client *http.Client, url string, header http.Header, body []byte) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notify %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// EmailNotifier 通过 SMTP 发送邮件通知
+type EmailNotifier struct {
+	// SMTP 服务器地址, 如 smtp.example.com:587
+	Addr string
+	// 为 nil 时不认证
+	Auth smtp.Auth
+	From string
+	To   []string
+	// 只在失败时发送
+	OnlyFailure bool
+}
+
+func (n *EmailNotifier) Notify(result *DumpResult, err error) error {
+	if err == nil && n.OnlyFailure {
+		return nil
+	}
+	subject := notifySummary(result, err)
+	body, merr := json.MarshalIndent(newNotifyPayload(result, err), "", "  ")
+	if merr != nil {
+		return merr
+	}
+	var msg strings.Builder
+	msg.WriteString("From: " + n.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(n.To, ", ") + "\r\n")
+	msg.WriteString("Subject: " + subject + "\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.Write(body)
+	return smtp.SendMail(n.Addr, n.Auth, n.From, n.To, []byte(msg.String()))
+}
@@ -0,0 +1,35 @@
+package mysqldump
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got NotifyPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	n := &WebhookNotifier{URL: server.URL, Header: http.Header{"X-Token": {"secret"}}}
+	err := n.Notify(&DumpResult{Database: "test", Bytes: 10}, errors.New("boom"))
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got.Status != "failure" || got.Error != "boom" || got.Result.Database != "test" {
+		t.Errorf("Notify() payload = %+v", got)
+	}
+
+	n.Header = nil
+	if err = n.Notify(&DumpResult{}, nil); err == nil {
+		t.Errorf("Notify() want error on non-2xx status")
+	}
+}
@@ -0,0 +1,152 @@
+package mysqldump
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// tableOutput 并发导出时一个表的临时输出
+type tableOutput struct {
+	file   *os.File
+	result TableResult
+	err    error
+	done   chan struct{}
+}
+
+func (t *tableOutput) cleanup() {
+	if t.file != nil {
+		_ = t.file.Close()
+		_ = os.Remove(t.file.Name())
+	}
+}
+
+// dumpTablesParallel 并发导出表到临时文件, 再按表的顺序写到 buf
+// WithSkipFailedTables 时也使用此方式, 失败的表丢弃临时文件
+// 并发时每个 worker 独占一个连接, 串行时所有表使用 db
+func dumpTablesParallel(ctx context.Context, db queryer, tables []string, buf *bufio.Writer, counter *countWriter, noDataMap map[string]bool, o *dumpOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outputs := make([]*tableOutput, len(tables))
+	for i := range outputs {
+		outputs[i] = &tableOutput{done: make(chan struct{})}
+	}
+
+	// 空闲的 worker 连接, 同时限制并发数
+	concurrency := max(min(o.concurrency, len(tables)), 1)
+	// 一个连接留给快照或元数据查询
+	if o.maxOpenConns > 0 && concurrency >= o.maxOpenConns {
+		log.Printf("[warn] [dump] max open conns %d, concurrency reduced to %d\n", o.maxOpenConns, max(o.maxOpenConns-1, 1))
+		concurrency = max(o.maxOpenConns-1, 1)
+	}
+	conns := make(chan queryer, concurrency)
+	if concurrency > 1 {
+		workers, err := openWorkerConns(ctx, o.pool, concurrency, o)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+		defer closeWorkerConns(workers)
+		for _, conn := range workers {
+			conns <- conn
+		}
+	} else {
+		conns <- db
+	}
+	go func() {
+		for i, table := range tables {
+			var conn queryer
+			select {
+			case conn = <-conns:
+			case <-ctx.Done():
+				for _, out := range outputs[i:] {
+					out.err = ctx.Err()
+					close(out.done)
+				}
+				return
+			}
+			go func(out *tableOutput, table string, db queryer) {
+				defer func() { conns <- db }()
+				defer close(out.done)
+				if w := o.routeTable(table); w != nil {
+					out.result, out.err = dumpTableToWriter(ctx, db, table, w, noDataMap[table], o)
+					return
+				}
+				out.file, out.err = os.CreateTemp("", "mysqldump-*.sql")
+				if out.err != nil {
+					return
+				}
+				counter := &countWriter{w: out.file}
+				tableBuf := bufio.NewWriter(counter)
+				out.result, out.err = dumpTable(ctx, db, table, tableBuf, counter, noDataMap[table], o)
+				if out.err == nil {
+					out.err = tableBuf.Flush()
+				}
+			}(outputs[i], table, conn)
+		}
+	}()
+
+	var err error
+	for i, out := range outputs {
+		<-out.done
+		if err == nil {
+			err = out.err
+			if err != nil && o.skipFailedTables && ctx.Err() == nil {
+				err = skipFailedTable(buf, tables[i], out.err, o)
+				if err == nil {
+					out.cleanup()
+					continue
+				}
+			}
+			if err == nil {
+				err = appendTableOutput(buf, out)
+			}
+			if err == nil {
+				o.result.Tables = append(o.result.Tables, out.result)
+				o.reportProgress(len(tables), counter.n+int64(buf.Buffered()))
+				err = o.checkpoint.tableFinished(tables[i], buf)
+			}
+			if err == nil {
+				err = o.flush.check(buf)
+			}
+			if err != nil {
+				log.Printf("[error] %v \n", err)
+				// 停止启动新的表, 等待正在导出的表结束后清理临时文件
+				cancel()
+			}
+		}
+		out.cleanup()
+	}
+	return err
+}
+
+func appendTableOutput(buf *bufio.Writer, out *tableOutput) error {
+	if out.file == nil {
+		// 已写到 WithTableWriter 指定的 writer
+		return nil
+	}
+	_, err := out.file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(buf, out.file)
+	return err
+}
+
+// skipFailedTable 记录失败的表, 并在输出中写一行注释
+func skipFailedTable(buf *bufio.Writer, table string, err error, o *dumpOption) error {
+	o.warn(Warning{Kind: WarningSkippedTable, Table: table, Message: err.Error()})
+	o.mu.Lock()
+	o.result.FailedTables = append(o.result.FailedTables, FailedTable{Name: table, Error: err.Error()})
+	o.mu.Unlock()
+	if o.compact {
+		return nil
+	}
+	_, werr := fmt.Fprintf(buf, "-- Table %s skipped: %s\n\n", commentName(table), strings.ReplaceAll(err.Error(), "\n", " "))
+	return werr
+}
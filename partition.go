@@ -0,0 +1,175 @@
+package mysqldump
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// WithoutPartitions 去掉 CREATE TABLE 中的分区定义, 恢复为普通表, 类似 --skip-partition
+func WithoutPartitions() DumpOption {
+	return func(option *dumpOption) {
+		option.noPartitions = true
+	}
+}
+
+// WithPartitionConcurrency 分区表同时查询 n 个分区的数据 (SELECT ... PARTITION (p0)),
+// 每个分区先写到临时文件, 再按分区顺序写到输出, n 小于 2 时不按分区导出
+// 断点续传时未完成的分区表按整表继续导出
+func WithPartitionConcurrency(n int) DumpOption {
+	return func(option *dumpOption) {
+		option.partitionConcurrency = n
+	}
+}
+
+// stripPartitions 去掉 CREATE TABLE 中的 PARTITION BY 定义
+// MySQL 的分区定义在 /*!50100 PARTITION BY ... */ 注释中, MariaDB 没有注释
+func stripPartitions(createSQL string) string {
+	tokens := tokenizeSQL(createSQL)
+	depth := 0
+	for i, t := range tokens {
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		if depth != 0 {
+			continue
+		}
+		partition := t.isKeyword("PARTITION")
+		if t.kind == tokenComment && strings.HasPrefix(t.text, "/*!") {
+			body := strings.TrimLeft(t.text[3:], "0123456789")
+			partition = strings.HasPrefix(strings.ToUpper(strings.TrimSpace(body)), "PARTITION BY")
+		}
+		if !partition {
+			continue
+		}
+		var sb strings.Builder
+		for _, t := range tokens[:i] {
+			sb.WriteString(t.text)
+		}
+		return strings.TrimRight(sb.String(), " \t\r\n")
+	}
+	return createSQL
+}
+
+// getPartitions 按定义顺序返回表的分区, 不是分区表时返回空
+func getPartitions(db queryer, table string) ([]string, error) {
+	rows, err := db.Query("SELECT DISTINCT PARTITION_NAME, PARTITION_ORDINAL_POSITION FROM information_schema.PARTITIONS "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL "+
+		"ORDER BY PARTITION_ORDINAL_POSITION", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []string
+	for rows.Next() {
+		var name string
+		var position int
+		err = rows.Scan(&name, &position)
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, name)
+	}
+	return partitions, rows.Err()
+}
+
+// partitionOutput 一个分区的临时输出
+type partitionOutput struct {
+	file *os.File
+	w    *tableDataWriter
+	err  error
+}
+
+// writePartitionsData 并发导出每个分区的数据到临时文件, 再按分区顺序写到 w.buf
+// 每个分区使用单独的 worker 连接, 没有连接池时 (DumpTable) 使用 db
+func writePartitionsData(ctx context.Context, db queryer, partitions []string, selectList string, conds []string, w *tableDataWriter) error {
+	outputs := make([]*partitionOutput, len(partitions))
+	defer func() {
+		for _, out := range outputs {
+			if out != nil && out.file != nil {
+				_ = out.file.Close()
+				_ = os.Remove(out.file.Name())
+			}
+		}
+	}()
+
+	for i := range partitions {
+		file, err := os.CreateTemp("", "mysqldump-partition-*.sql")
+		if err != nil {
+			return err
+		}
+		outputs[i] = &partitionOutput{file: file}
+	}
+
+	sem := make(chan struct{}, w.o.partitionConcurrency)
+	var wg sync.WaitGroup
+	for i, partition := range partitions {
+		out := outputs[i]
+		wg.Add(1)
+		go func(partition string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			buf := bufio.NewWriter(out.file)
+			// 不输出 TableDataBegin, 由 w 在所有分区之前输出
+			out.w = &tableDataWriter{
+				meta:      &TableMeta{Name: w.meta.Name, PartialColumns: w.meta.PartialColumns},
+				pk:        w.pk,
+				hiddenPK:  w.hiddenPK,
+				chunkSize: w.chunkSize,
+				partition: partition,
+				buf:       buf,
+				o:         w.o,
+				resumed:   true,
+			}
+			q := db
+			if w.o.pool != nil {
+				conn, err := openWorkerConn(ctx, w.o.pool, w.o)
+				if err != nil {
+					out.err = err
+					return
+				}
+				defer conn.Close()
+				q = conn
+			}
+			out.err = out.w.writeChunks(q, selectList, conds, "")
+			if out.err == nil {
+				out.err = buf.Flush()
+			}
+		}(partition)
+	}
+	wg.Wait()
+
+	for _, out := range outputs {
+		if out.err != nil {
+			return out.err
+		}
+	}
+
+	// 每个分区的列相同, 使用第一个分区的列信息
+	first := outputs[0].w
+	w.meta.Columns, w.meta.DataTypes = first.meta.Columns, first.meta.DataTypes
+	err := w.o.formatter.TableDataBegin(w.buf, w.meta)
+	if err != nil {
+		return err
+	}
+	for _, out := range outputs {
+		_, err = out.file.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w.buf, out.file)
+		if err != nil {
+			return err
+		}
+		w.rows += out.w.rows
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package mysqldump
+
+import "testing"
+
+func Test_stripPartitions(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "mysql",
+			in: "CREATE TABLE `t` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4\n" +
+				"/*!50100 PARTITION BY RANGE (`id`)\n(PARTITION p0 VALUES LESS THAN (10) ENGINE = InnoDB,\n PARTITION p1 VALUES LESS THAN MAXVALUE ENGINE = InnoDB) */",
+			want: "CREATE TABLE `t` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		{
+			name: "mariadb",
+			in:   "CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB\n PARTITION BY HASH (`id`)\nPARTITIONS 4",
+			want: "CREATE TABLE `t` (\n  `id` int NOT NULL\n) ENGINE=InnoDB",
+		},
+		{
+			name: "not partitioned",
+			in:   "CREATE TABLE `t` (\n  `partition` int COMMENT 'PARTITION BY'\n) ENGINE=InnoDB /*!50100 TABLESPACE `ts` */",
+			want: "CREATE TABLE `t` (\n  `partition` int COMMENT 'PARTITION BY'\n) ENGINE=InnoDB /*!50100 TABLESPACE `ts` */",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripPartitions(tt.in); got != tt.want {
+				t.Errorf("stripPartitions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package mysqldump
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// readDumpStartTime 读取导出文件头中的 Start Time
+func readDumpStartTime(reader io.Reader) (time.Time, error) {
+	scanner := bufio.NewScanner(reader)
+	for i := 0; i < 20 && scanner.Scan(); i++ {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "-- Start Time: ") {
+			return time.ParseInLocation("2006-01-02 15:04:05", strings.TrimPrefix(line, "-- Start Time: "), time.Local)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, fmt.Errorf("start time not found in dump header, dumps made with WithCompact or a header template without it can not be restored by time")
+}
+
+type dumpFile struct {
+	name  string
+	start time.Time
+}
+
+// RestoreIncrements 先恢复全量导出 baseFile, 再按开始时间的顺序恢复 incrementFiles 中
+// 开始时间不晚于 until 的增量导出 (见 WithIncremental)
+// 增量导出只有每行的最新值, 没有变更时间, 不能在文件内截止, 恢复结果为 until 之前最后一个增量导出开始时的数据, 不是 until 时刻的数据
+// 文件的时间以文件头中的 Start Time 为准, 任何文件没有 Start Time 时不恢复并返回错误
+func RestoreIncrements(dsn string, baseFile string, incrementFiles []string, until time.Time, opts ...SourceOption) error {
+	return restoreIncrements(baseFile, incrementFiles, until, func(name string) error {
+		return sourceFile(dsn, name, opts...)
+	})
+}
+
+// restoreIncrements 检查所有文件的开始时间后, 按顺序调用 restore 恢复
+func restoreIncrements(baseFile string, incrementFiles []string, until time.Time, restore func(name string) error) error {
+	base, err := readDumpFileStartTime(baseFile)
+	if err != nil {
+		log.Printf("[error] %v\n", err)
+		return err
+	}
+	if base.start.After(until) {
+		err = fmt.Errorf("base dump %s starts at %s, after %s", baseFile,
+			base.start.Format("2006-01-02 15:04:05"), until.Format("2006-01-02 15:04:05"))
+		log.Printf("[error] %v\n", err)
+		return err
+	}
+
+	var increments []dumpFile
+	for _, name := range incrementFiles {
+		f, err := readDumpFileStartTime(name)
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+		// 早于全量导出的增量已包含在全量中, 晚于 until 的增量不恢复
+		if f.start.Before(base.start) || f.start.After(until) {
+			log.Printf("[info] [restore] skip %s, start at %s\n", name, f.start.Format("2006-01-02 15:04:05"))
+			continue
+		}
+		increments = append(increments, f)
+	}
+	sort.SliceStable(increments, func(i, j int) bool {
+		return increments[i].start.Before(increments[j].start)
+	})
+
+	for _, f := range append([]dumpFile{base}, increments...) {
+		log.Printf("[info] [restore] restore %s, start at %s\n", f.name, f.start.Format("2006-01-02 15:04:05"))
+		err = restore(f.name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readDumpFileStartTime(name string) (dumpFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return dumpFile{}, err
+	}
+	defer f.Close()
+	start, err := readDumpStartTime(f)
+	if err != nil {
+		return dumpFile{}, fmt.Errorf("%s: %v", name, err)
+	}
+	return dumpFile{name: name, start: start}, nil
+}
+
+func sourceFile(dsn string, name string, opts ...SourceOption) error {
+	f, err := os.Open(name)
+	if err != nil {
+		log.Printf("[error] %v\n", err)
+		return err
+	}
+	defer f.Close()
+	return Source(dsn, f, opts...)
+}
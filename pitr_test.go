@@ -0,0 +1,93 @@
+package mysqldump
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_readDumpStartTime(t *testing.T) {
+	dump := "-- ----------------------------\n" +
+		"-- MySQL Database Dump\n" +
+		"-- Start Time: 2024-03-17 10:00:00\n" +
+		"-- ----------------------------\n"
+	got, err := readDumpStartTime(strings.NewReader(dump))
+	if err != nil {
+		t.Fatalf("readDumpStartTime() error = %v", err)
+	}
+	want := time.Date(2024, 3, 17, 10, 0, 0, 0, time.Local)
+	if !got.Equal(want) {
+		t.Errorf("readDumpStartTime() = %v, want %v", got, want)
+	}
+
+	_, err = readDumpStartTime(strings.NewReader("SELECT 1;"))
+	if err == nil {
+		t.Errorf("readDumpStartTime() want error")
+	}
+}
+
+func Test_restoreIncrements(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, start, stmt string) string {
+		path := filepath.Join(dir, name)
+		data := "-- ----------------------------\n-- MySQL Database Dump\n-- Start Time: " + start + "\n-- ----------------------------\n" +
+			stmt + "\n-- Dump completed on " + start + "\n"
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	base := write("base.sql", "2024-03-17 00:00:00", "INSERT INTO `a` VALUES (0);")
+	increments := []string{
+		write("inc3.sql", "2024-03-17 03:00:00", "REPLACE INTO `a` VALUES (3);"),
+		write("inc1.sql", "2024-03-17 01:00:00", "REPLACE INTO `a` VALUES (1);"),
+		write("old.sql", "2024-03-16 23:00:00", "REPLACE INTO `a` VALUES (-1);"),
+		write("inc2.sql", "2024-03-17 02:00:00", "REPLACE INTO `a` VALUES (2);"),
+	}
+	until := time.Date(2024, 3, 17, 2, 30, 0, 0, time.Local)
+
+	db, f := newFakeDB(t)
+	restore := func(name string) error {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		var o sourceOption
+		return source(context.Background(), db, "test", file, &o, newRestoreTracker(&o, time.Now()))
+	}
+	if err := restoreIncrements(base, increments, until, restore); err != nil {
+		t.Fatalf("restoreIncrements() error = %v", err)
+	}
+
+	// 全量在前, 增量按开始时间, 早于全量和晚于 until 的不恢复
+	var got []string
+	for _, query := range f.executed() {
+		// 语句前的注释与语句一起执行
+		if i := strings.LastIndex(query, "\n"); strings.Contains(query, "INTO `a`") {
+			got = append(got, query[i+1:])
+		}
+	}
+	want := []string{"INSERT INTO `a` VALUES (0);", "REPLACE INTO `a` VALUES (1);", "REPLACE INTO `a` VALUES (2);"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restored = %q, want %q", got, want)
+	}
+
+	// 任何文件没有开始时间时, 不恢复任何文件
+	compact := filepath.Join(dir, "compact.sql")
+	if err := os.WriteFile(compact, []byte("REPLACE INTO `a` VALUES (4);\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	err := restoreIncrements(base, append(increments, compact), until, func(string) error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Errorf("restoreIncrements() error = %v, restored = %v, want error before restoring", err, called)
+	}
+}
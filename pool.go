@@ -0,0 +1,54 @@
+package mysqldump
+
+import (
+	"database/sql"
+	"time"
+)
+
+// WithMaxOpenConns 连接池的最大连接数, 默认为 poolSize 计算的 worker 连接数加一, 一个连接用于查询表结构等元数据
+// 并发导出时每个 worker 独占一个连接, n 应大于并发数, 否则 worker 会一直等待空闲连接
+func WithMaxOpenConns(n int) DumpOption {
+	return func(option *dumpOption) {
+		option.maxOpenConns = n
+	}
+}
+
+// WithConnMaxLifetime 连接的最长使用时间, 默认不限制
+// 连接经过有空闲超时的代理或负载均衡时设置为小于其超时时间
+func WithConnMaxLifetime(d time.Duration) DumpOption {
+	return func(option *dumpOption) {
+		option.connMaxLifetime = d
+	}
+}
+
+// poolSize 返回连接池的最大连接数
+func (o *dumpOption) poolSize() int {
+	if o.maxOpenConns > 0 {
+		return o.maxOpenConns
+	}
+	return o.requiredConns()
+}
+
+// requiredConns 返回导出需要的连接数
+// 每个 worker 独占一个连接, 导出分区表时每个分区和加表锁时另外使用一个连接
+func (o *dumpOption) requiredConns() int {
+	workers := max(o.concurrency, 1)
+	perWorker := 1
+	if o.partitionConcurrency > 1 {
+		perWorker += o.partitionConcurrency
+	}
+	if o.lockNonTransactional {
+		perWorker++
+	}
+	return workers*perWorker + 1
+}
+
+// configurePool 设置连接池, 空闲连接数与最大连接数相同, 避免并发导出时反复建立连接
+func (o *dumpOption) configurePool(db *sql.DB) {
+	n := o.poolSize()
+	db.SetMaxOpenConns(n)
+	db.SetMaxIdleConns(n)
+	if o.connMaxLifetime > 0 {
+		db.SetConnMaxLifetime(o.connMaxLifetime)
+	}
+}
@@ -0,0 +1,24 @@
+package mysqldump
+
+import "testing"
+
+func Test_dumpOption_poolSize(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []DumpOption
+		want int
+	}{
+		{name: "default", want: 2},
+		{name: "concurrency", opts: []DumpOption{WithConcurrency(8)}, want: 9},
+		{name: "partitions", opts: []DumpOption{WithConcurrency(2), WithPartitionConcurrency(3)}, want: 9},
+		{name: "lock tables", opts: []DumpOption{WithConcurrency(4), WithLockNonTransactionalTables()}, want: 9},
+		{name: "explicit", opts: []DumpOption{WithConcurrency(8), WithMaxOpenConns(4)}, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newDumpOption(tt.opts).poolSize(); got != tt.want {
+				t.Errorf("poolSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
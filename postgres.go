@@ -0,0 +1,422 @@
+package mysqldump
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Dialect 输出的 SQL 方言
+type Dialect string
+
+const (
+	// DialectMySQL 默认, MySQL 语法
+	DialectMySQL Dialect = "mysql"
+	// DialectPostgres PostgreSQL 语法
+	DialectPostgres Dialect = "postgres"
+	// DialectSQLite SQLite 语法
+	DialectSQLite Dialect = "sqlite"
+)
+
+// WithDialect 输出指定数据库的 SQL, 表结构和数据会转换为对应的语法
+// 只支持常用的类型和语法, 触发器, 分区, 全文索引等不会转换
+func WithDialect(d Dialect) DumpOption {
+	return func(option *dumpOption) {
+		switch d {
+		case DialectPostgres:
+			option.formatter = newPostgresFormatter(option)
+		case DialectSQLite:
+			option.formatter = &sqliteFormatter{o: option}
+		default:
+			option.formatter = &sqlFormatter{o: option}
+		}
+	}
+}
+
+// postgresFormatter 输出 PostgreSQL 的 SQL
+// 外键在所有表的数据导入后添加, 自增列转换为 IDENTITY 并在数据导入后更新序列
+type postgresFormatter struct {
+	o *dumpOption
+
+	mu sync.Mutex
+	// 外键约束, 在 Footer 中输出
+	foreignKeys []string
+	// 每个表的自增列
+	identity map[string]string
+	// WithIncremental 时每个表的 ON CONFLICT 子句, 在 TableDataBegin 中生成
+	upsert map[string]string
+}
+
+func newPostgresFormatter(o *dumpOption) *postgresFormatter {
+	return &postgresFormatter{o: o, identity: make(map[string]string), upsert: make(map[string]string)}
+}
+
+// pgQuote 使用双引号包裹标识符
+func pgQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func pgQuoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = pgQuote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// pgString 单引号字符串, standard_conforming_strings 开启时反斜杠不转义
+func pgString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (f *postgresFormatter) Header(w io.Writer, meta *DumpMeta) error {
+	err := f.o.writeHeaderComment(w, meta, "MySQL Database Dump (PostgreSQL dialect)")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "SET client_encoding = 'UTF8';\n"+
+		"SET standard_conforming_strings = on;\n"+
+		f.o.spacing("\n\n"))
+	return err
+}
+
+// pgColumnType 将 MySQL 列类型转换为 PostgreSQL 类型
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func pgColumnType(col columnDef) string {
+	switch col.typ {
+	case "tinyint", "smallint":
+		if col.unsigned && col.typ == "smallint" {
+			return "integer"
+		}
+		return "smallint"
+	case "mediumint":
+		return "integer"
+	case "int", "integer":
+		if col.unsigned {
+			return "bigint"
+		}
+		return "integer"
+	case "bigint":
+		if col.unsigned {
+			return "numeric(20)"
+		}
+		return "bigint"
+	case "float":
+		return "real"
+	case "double", "real":
+		return "double precision"
+	case "decimal", "numeric", "dec":
+		if col.args != "" {
+			return "numeric(" + col.args + ")"
+		}
+		return "numeric"
+	case "char", "varchar":
+		if col.args != "" {
+			return col.typ + "(" + col.args + ")"
+		}
+		return col.typ
+	case "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		return "text"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit":
+		return "bytea"
+	case "date":
+		return "date"
+	case "datetime", "timestamp":
+		if col.args != "" {
+			return "timestamp(" + col.args + ")"
+		}
+		return "timestamp"
+	case "time":
+		// MySQL 的 TIME 是时长, 范围为 -838:59:59 到 838:59:59, 超出 PostgreSQL time 的 00:00:00 到 24:00:00
+		return "interval"
+	case "year":
+		return "smallint"
+	case "json":
+		return "jsonb"
+	case "bool", "boolean":
+		return "boolean"
+	default:
+		return "text"
+	}
+}
+
+// pgDefault 转换默认值, 无法转换时返回空
+func pgDefault(col columnDef) string {
+	v := col.defaultValue
+	upper := strings.ToUpper(v)
+	switch {
+	case v == "" || upper == "NULL":
+		return ""
+	case strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || strings.HasPrefix(upper, "NOW("):
+		return "CURRENT_TIMESTAMP"
+	case strings.HasPrefix(v, "'"):
+		s := unquoteString(v)
+		if strings.HasPrefix(s, "0000-00-00") {
+			// PostgreSQL 不支持零日期
+			return ""
+		}
+		if pgColumnType(col) == "bytea" {
+			return pgString(`\x` + hex.EncodeToString([]byte(s)))
+		}
+		return pgString(s)
+	case strings.HasPrefix(v, "b'") || strings.HasPrefix(v, "_") || strings.HasPrefix(v, "("):
+		// bit 字面量, 带字符集的字符串和表达式默认值不转换
+		return ""
+	default:
+		return v
+	}
+}
+
+// pgCreateTable 将 MySQL 的表定义转换为 PostgreSQL 语句, 外键单独返回
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func pgCreateTable(def *tableDef) (string, []string, string) {
+	var sb strings.Builder
+	table := pgQuote(def.name)
+	identity := ""
+	var lines []string
+	var comments []string
+	for _, col := range def.columns {
+		typ := pgColumnType(col)
+		if col.autoInc && typ == "numeric(20)" {
+			// IDENTITY 只支持整数类型
+			typ = "bigint"
+		}
+		line := "  " + pgQuote(col.name) + " " + typ
+		if col.autoInc {
+			line += " GENERATED BY DEFAULT AS IDENTITY"
+			identity = col.name
+		} else if d := pgDefault(col); d != "" {
+			line += " DEFAULT " + d
+		}
+		if col.notNull {
+			line += " NOT NULL"
+		}
+		if col.typ == "enum" && col.args != "" {
+			line += " CHECK (" + pgQuote(col.name) + " IN (" + col.args + "))"
+		}
+		lines = append(lines, line)
+		if col.comment != "" {
+			comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;\n", table, pgQuote(col.name), pgString(col.comment)))
+		}
+	}
+	if len(def.primaryKey) > 0 {
+		lines = append(lines, "  PRIMARY KEY ("+pgQuoteList(def.primaryKey)+")")
+	}
+	sb.WriteString("CREATE TABLE IF NOT EXISTS " + table + " (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n);\n")
+
+	for _, idx := range def.indexes {
+		if len(idx.columns) == 0 || (idx.kind != "" && idx.kind != "UNIQUE") {
+			// 全文索引, 空间索引和函数索引不转换
+			continue
+		}
+		unique := ""
+		if idx.kind == "UNIQUE" {
+			unique = "UNIQUE "
+		}
+		// PostgreSQL 的索引名在 schema 内唯一, 加上表名前缀
+		name := pgQuote(def.name + "_" + idx.name)
+		sb.WriteString(fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s);\n", unique, name, table, pgQuoteList(idx.columns)))
+	}
+	if def.comment != "" {
+		sb.WriteString(fmt.Sprintf("COMMENT ON TABLE %s IS %s;\n", table, pgString(def.comment)))
+	}
+	for _, c := range comments {
+		sb.WriteString(c)
+	}
+
+	var fks []string
+	for _, fk := range def.foreignKeys {
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+			table, pgQuote(fk.name), pgQuoteList(fk.columns), pgQuote(fk.refTable), pgQuoteList(fk.refColumns))
+		if fk.onDelete != "" {
+			stmt += " ON DELETE " + fk.onDelete
+		}
+		if fk.onUpdate != "" {
+			stmt += " ON UPDATE " + fk.onUpdate
+		}
+		fks = append(fks, stmt+";\n")
+	}
+	return sb.String(), fks, identity
+}
+
+func (f *postgresFormatter) TableSchema(w io.Writer, table *TableMeta) error {
+	if table.View || table.Sequence {
+		// 视图的查询语句是 MySQL 语法, 不转换
+		return writeUnconvertedView(w, table, f.o)
+	}
+	def, err := parseCreateTable(table.CreateSQL)
+	if err != nil {
+		return err
+	}
+	createSQL, fks, identity := pgCreateTable(def)
+
+	f.mu.Lock()
+	f.foreignKeys = append(f.foreignKeys, fks...)
+	if identity != "" {
+		f.identity[table.Name] = identity
+	}
+	f.mu.Unlock()
+
+	if f.o.isDropTable {
+		_, _ = fmt.Fprintf(w, "DROP TABLE IF EXISTS %s CASCADE;\n", pgQuote(table.Name))
+	}
+	_, _ = io.WriteString(w, f.o.banner("Table structure for "+commentName(table.Name)))
+	_, err = io.WriteString(w, createSQL+"\n"+f.o.spacing("\n\n"))
+	return err
+}
+
+func (f *postgresFormatter) TableDataBegin(w io.Writer, table *TableMeta) error {
+	if f.o.incremental != nil {
+		upsert, err := pgUpsert(table)
+		if err != nil {
+			return err
+		}
+		f.mu.Lock()
+		f.upsert[table.Name] = upsert
+		f.mu.Unlock()
+	}
+	_, err := io.WriteString(w, f.o.banner("Records of "+commentName(table.Name)))
+	if err == nil && f.o.isTruncateTable {
+		_, err = fmt.Fprintf(w, "TRUNCATE TABLE %s;\n", pgQuote(table.Name))
+	}
+	return err
+}
+
+// pgUpsert 返回增量数据使用的 ON CONFLICT 子句, 与 MySQL 的 REPLACE 一样用新行覆盖主键相同的行
+func pgUpsert(table *TableMeta) (string, error) {
+	def, err := parseCreateTable(table.CreateSQL)
+	if err != nil {
+		return "", err
+	}
+	if len(def.primaryKey) == 0 {
+		return "", fmt.Errorf("incremental dump of table %s to PostgreSQL requires a primary key", table.Name)
+	}
+	pk := make(map[string]bool, len(def.primaryKey))
+	for _, name := range def.primaryKey {
+		pk[name] = true
+	}
+	var sets []string
+	for _, column := range table.Columns {
+		if !pk[column] {
+			sets = append(sets, pgQuote(column)+" = EXCLUDED."+pgQuote(column))
+		}
+	}
+	if len(sets) == 0 {
+		return " ON CONFLICT (" + pgQuoteList(def.primaryKey) + ") DO NOTHING", nil
+	}
+	return " ON CONFLICT (" + pgQuoteList(def.primaryKey) + ") DO UPDATE SET " + strings.Join(sets, ", "), nil
+}
+
+// pgValue 将列值转换为 PostgreSQL 字面量
+func pgValue(col interface{}, dataType string) (string, error) {
+	value, null, err := textValue(col, dataType)
+	if err != nil || null {
+		return "NULL", err
+	}
+	if _, ok := col.(string); ok {
+		// ColumnTransform 返回的 string 不论列类型都作为字符串输出
+		return pgString(value), nil
+	}
+	switch strings.TrimSpace(strings.Replace(dataType, "UNSIGNED", "", -1)) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR", "FLOAT", "DOUBLE", "DECIMAL", "DEC":
+		return value, nil
+	case "BIT", "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		// textValue 已转换为十六进制
+		return `'\x` + value + `'`, nil
+	case "BOOL", "BOOLEAN":
+		if value == "1" {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	if strings.HasPrefix(value, "0000-00-00") {
+		return "NULL", nil
+	}
+	return pgString(value), nil
+}
+
+func (f *postgresFormatter) Row(w io.Writer, table *TableMeta, row []interface{}) error {
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO " + pgQuote(table.Name))
+	if table.PartialColumns {
+		sb.WriteString(" (" + pgQuoteList(table.Columns) + ")")
+	}
+	sb.WriteString(" VALUES (")
+	for i, col := range row {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		value, err := pgValue(col, table.DataTypes[i])
+		if err != nil {
+			return err
+		}
+		sb.WriteString(value)
+	}
+	switch {
+	case f.o.incremental != nil:
+		f.mu.Lock()
+		upsert := f.upsert[table.Name]
+		f.mu.Unlock()
+		sb.WriteString(")" + upsert + ";\n")
+	case f.o.ignoreInsert(table.Name):
+		sb.WriteString(") ON CONFLICT DO NOTHING;\n")
+	default:
+		sb.WriteString(");\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (f *postgresFormatter) TableDataEnd(w io.Writer, table *TableMeta) error {
+	f.mu.Lock()
+	identity := f.identity[table.Name]
+	f.mu.Unlock()
+	if identity != "" {
+		// 显式插入的自增值不会推进序列
+		_, _ = fmt.Fprintf(w, "SELECT setval(pg_get_serial_sequence(%s, %s), COALESCE(MAX(%s), 0) + 1, false) FROM %s;\n",
+			pgString(pgQuote(table.Name)), pgString(identity), pgQuote(identity), pgQuote(table.Name))
+	}
+	_, err := io.WriteString(w, f.o.spacing("\n\n"))
+	return err
+}
+
+func (f *postgresFormatter) Footer(w io.Writer, meta *DumpMeta) error {
+	f.mu.Lock()
+	fks := f.foreignKeys
+	f.mu.Unlock()
+	if len(fks) > 0 {
+		sort.Strings(fks)
+		_, _ = io.WriteString(w, f.o.banner("Foreign keys"))
+		for _, fk := range fks {
+			_, _ = io.WriteString(w, fk)
+		}
+		_, _ = io.WriteString(w, f.o.spacing("\n\n"))
+	}
+	return f.o.writeFooterComment(w, meta)
+}
+
+// writeUnconvertedView 将视图和序列的定义作为注释输出, WithCompact 时不输出
+func writeUnconvertedView(w io.Writer, table *TableMeta, o *dumpOption) error {
+	if o.compact {
+		return nil
+	}
+	kind := "View"
+	if table.Sequence {
+		kind = "Sequence"
+	}
+	_, _ = io.WriteString(w, "-- ----------------------------\n")
+	_, _ = fmt.Fprintf(w, "-- %s structure for %s (not converted)\n", kind, commentName(table.Name))
+	_, _ = io.WriteString(w, "-- ----------------------------\n")
+	for _, line := range strings.Split(table.CreateSQL+";", "\n") {
+		_, _ = io.WriteString(w, "-- "+line+"\n")
+	}
+	_, err := io.WriteString(w, "\n\n\n")
+	return err
+}
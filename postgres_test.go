@@ -0,0 +1,94 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_pgCreateTable(t *testing.T) {
+	def, err := parseCreateTable(testCreateTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, fks, identity := pgCreateTable(def)
+	want := "CREATE TABLE IF NOT EXISTS \"orders\" (\n" +
+		"  \"id\" bigint GENERATED BY DEFAULT AS IDENTITY NOT NULL,\n" +
+		"  \"user_id\" integer NOT NULL,\n" +
+		"  \"status\" text DEFAULT 'new' NOT NULL CHECK (\"status\" IN ('new','paid')),\n" +
+		"  \"amount\" numeric(10,2) DEFAULT '0.00',\n" +
+		"  \"balance\" integer DEFAULT -1,\n" +
+		"  \"created_at\" timestamp(3) DEFAULT CURRENT_TIMESTAMP NOT NULL,\n" +
+		"  \"total\" numeric(12,2),\n" +
+		"  PRIMARY KEY (\"id\")\n" +
+		");\n" +
+		"CREATE UNIQUE INDEX IF NOT EXISTS \"orders_uk_user\" ON \"orders\" (\"user_id\", \"status\");\n" +
+		"CREATE INDEX IF NOT EXISTS \"orders_idx_name\" ON \"orders\" (\"status\");\n" +
+		"COMMENT ON TABLE \"orders\" IS 'orders';\n" +
+		"COMMENT ON COLUMN \"orders\".\"status\" IS 'order''s status';\n"
+	if got != want {
+		t.Errorf("pgCreateTable() =\n%s\nwant\n%s", got, want)
+	}
+	if len(fks) != 1 || fks[0] != "ALTER TABLE \"orders\" ADD CONSTRAINT \"fk_user\" FOREIGN KEY (\"user_id\") REFERENCES \"users\" (\"id\") ON DELETE CASCADE ON UPDATE SET NULL;\n" {
+		t.Errorf("foreign keys = %q", fks)
+	}
+	if identity != "id" {
+		t.Errorf("identity = %q, want id", identity)
+	}
+}
+
+func Test_postgresFormatter_Row(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"id", "name", "data", "created"}, DataTypes: []string{"INT", "VARCHAR", "BLOB", "DATETIME"}}
+	tests := []struct {
+		name string
+		opts []DumpOption
+		row  []interface{}
+		want string
+	}{
+		{name: "values", row: []interface{}{int64(1), []byte(`it's \n`), []byte{0xab}, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, want: "INSERT INTO \"t\" VALUES (1,'it''s \\n','\\xab','2024-01-02 03:04:05');\n"},
+		{name: "null", row: []interface{}{int64(2), nil, nil, []byte("0000-00-00 00:00:00")}, want: "INSERT INTO \"t\" VALUES (2,NULL,NULL,NULL);\n"},
+		{name: "ignore", opts: []DumpOption{WithIgnoreInsertTable()}, row: []interface{}{int64(3), nil, nil, nil}, want: "INSERT INTO \"t\" VALUES (3,NULL,NULL,NULL) ON CONFLICT DO NOTHING;\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(append(tt.opts, WithDialect(DialectPostgres)))
+			var sb strings.Builder
+			if err := o.formatter.Row(&sb, table, tt.row); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("Row() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_postgresFormatter_incremental(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithCompact(), WithIncremental("state.json", nil), WithDialect(DialectPostgres)})
+	table := &TableMeta{Name: "t", CreateSQL: "CREATE TABLE `t` (\n  `id` int NOT NULL,\n  `name` varchar(10),\n  `spent` time,\n  PRIMARY KEY (`id`)\n)",
+		Columns: []string{"id", "name", "spent"}, DataTypes: []string{"INT", "VARCHAR", "TIME"}}
+	var sb strings.Builder
+	if err := o.formatter.TableDataBegin(&sb, table); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.formatter.Row(&sb, table, []interface{}{int64(1), []byte("a"), []byte("-838:59:59")}); err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT INTO \"t\" VALUES (1,'a','-838:59:59') ON CONFLICT (\"id\") DO UPDATE SET \"name\" = EXCLUDED.\"name\", \"spent\" = EXCLUDED.\"spent\";\n"
+	if sb.String() != want {
+		t.Errorf("Row() = %q, want %q", sb.String(), want)
+	}
+
+	// 没有主键时无法覆盖, 返回错误
+	table = &TableMeta{Name: "log", CreateSQL: "CREATE TABLE `log` (\n  `msg` text\n)", Columns: []string{"msg"}, DataTypes: []string{"TEXT"}}
+	if err := o.formatter.TableDataBegin(&sb, table); err == nil {
+		t.Error("TableDataBegin() want error for table without primary key")
+	}
+}
+
+func Test_pgColumnType_time(t *testing.T) {
+	// TIME 超出 PostgreSQL time 的范围, 转换为 interval
+	if got := pgColumnType(columnDef{typ: "time"}); got != "interval" {
+		t.Errorf("pgColumnType(time) = %s, want interval", got)
+	}
+}
@@ -0,0 +1,41 @@
+package mysqldump
+
+// Progress 导出进度, 每个表导出完成后回调
+type Progress struct {
+	// 刚完成的表
+	Table       string
+	TablesDone  int
+	TablesTotal int
+	// 已导出的行数和写出的字节数
+	Rows  int64
+	Bytes int64
+}
+
+// WithProgress 每个表导出完成后回调 fn, 并发导出时按表的顺序回调
+func WithProgress(fn func(Progress)) DumpOption {
+	return func(option *dumpOption) {
+		option.progress = fn
+	}
+}
+
+// reportProgress 在 result 中追加表后调用
+func (o *dumpOption) reportProgress(total int, bytes int64) {
+	if o.progress == nil || len(o.result.Tables) == 0 {
+		return
+	}
+	o.progress(Progress{
+		Table:       o.result.Tables[len(o.result.Tables)-1].Name,
+		TablesDone:  len(o.result.Tables),
+		TablesTotal: total,
+		Rows:        o.result.Rows(),
+		Bytes:       bytes,
+	})
+}
+
+// WithWriteProgress 每次写出到 writer 后以已写出的总字节数调用 fn, 返回错误时导出停止并返回该错误, 可用于限制导出的大小
+// 写出经过缓冲, 调用的间隔约为 4KB, 断点续传时包括之前写出的字节数
+func WithWriteProgress(fn func(bytes int64) error) DumpOption {
+	return func(option *dumpOption) {
+		option.writeProgress = fn
+	}
+}
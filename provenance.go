@@ -0,0 +1,77 @@
+package mysqldump
+
+import (
+	"os"
+	"os/user"
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath 本工具的模块路径, 用于从构建信息中读取版本
+const modulePath = "github.com/ai-mmo/mysqldump"
+
+// WithoutProvenance 文件头不输出来源信息, 默认输出来源库, 服务端版本, 工具版本, 使用的选项和执行导出的用户
+// 只影响默认的文件头注释, WithHeaderTemplate 可以通过 DumpMeta 自行输出, WithMySQLDumpCompat 的文件头与官方相同, 不输出来源信息
+func WithoutProvenance() DumpOption {
+	return func(option *dumpOption) {
+		option.noProvenance = true
+	}
+}
+
+// toolVersion 返回构建信息中本工具的版本, 作为依赖时为依赖的版本, 没有构建信息时返回空
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// invoker 返回执行导出的系统用户和主机名, 如 alice@build01
+func invoker() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		name += "@" + hostname
+	}
+	return name
+}
+
+// provenanceComment 文件头中的来源信息, 每行一项, 没有值的项不输出
+func (o *dumpOption) provenanceComment(meta *DumpMeta) string {
+	if o.noProvenance {
+		return ""
+	}
+	var sb strings.Builder
+	line := func(name, value string) {
+		if value != "" {
+			sb.WriteString("-- " + name + ": " + commentName(value) + "\n")
+		}
+	}
+	source := meta.Host
+	if source != "" && meta.User != "" {
+		source = meta.User + "@" + source
+	}
+	if source != "" {
+		source += "/" + meta.Database
+	}
+	line("Source", source)
+	line("Server Version", meta.ServerVersion)
+	line("Tool Version", meta.ToolVersion)
+	line("Options", strings.Join(meta.Options, " "))
+	line("Dumped By", meta.Invoker)
+	return sb.String()
+}
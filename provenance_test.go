@@ -0,0 +1,29 @@
+package mysqldump
+
+import "testing"
+
+func Test_dumpOption_provenanceComment(t *testing.T) {
+	meta := &DumpMeta{
+		Database:      "shop",
+		Host:          "db1",
+		User:          "backup",
+		ServerVersion: "8.0.36",
+		ToolVersion:   "v1.2.0",
+		Options:       []string{"data", "tables=a,b"},
+		Invoker:       "alice@build01\n-- forged",
+	}
+	want := "-- Source: backup@db1/shop\n" +
+		"-- Server Version: 8.0.36\n" +
+		"-- Tool Version: v1.2.0\n" +
+		"-- Options: data tables=a,b\n" +
+		"-- Dumped By: alice@build01 -- forged\n"
+	if got := newDumpOption(nil).provenanceComment(meta); got != want {
+		t.Errorf("provenanceComment() = %q, want %q", got, want)
+	}
+	if got := newDumpOption([]DumpOption{WithoutProvenance()}).provenanceComment(meta); got != "" {
+		t.Errorf("provenanceComment() with WithoutProvenance = %q", got)
+	}
+	if got := newDumpOption(nil).provenanceComment(&DumpMeta{Database: "shop"}); got != "" {
+		t.Errorf("provenanceComment() without values = %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+package mysqldump
+
+import "strings"
+
+// WithQualifiedNames 输出中的表, 视图和序列都使用 `db`.`table` 的形式, 不输出 USE
+// 恢复时不依赖当前数据库, 多个数据库的导出可以合并到一个文件中执行
+// 包括 DROP, CREATE, 外键的 REFERENCES, TRUNCATE, LOCK TABLES, INSERT 和序列的 SETVAL
+func WithQualifiedNames() DumpOption {
+	return func(option *dumpOption) {
+		option.qualifiedNames = true
+	}
+}
+
+// objectName 返回输出中使用的对象名, WithQualifiedNames 时加上数据库名
+func (o *dumpOption) objectName(name string) string {
+	return string(o.appendObjectName(nil, name))
+}
+
+// appendObjectName 将 objectName 的结果追加到 dst
+func (o *dumpOption) appendObjectName(dst []byte, name string) []byte {
+	if o.qualifiedNames && o.database != "" {
+		dst = appendIdentifier(dst, o.database)
+		dst = append(dst, '.')
+	}
+	return appendIdentifier(dst, name)
+}
+
+// qualifyCreateSQL 在 CREATE 语句的对象名和外键 REFERENCES 的表名前加上数据库名, 已有数据库名的不变
+// 没有 WithQualifiedNames 时原样返回
+func (o *dumpOption) qualifyCreateSQL(stmt string) string {
+	if !o.qualifiedNames || o.database == "" {
+		return stmt
+	}
+	tokens := tokenizeSQL(stmt)
+	var idx []int
+	for i, t := range tokens {
+		if t.kind != tokenSpace && t.kind != tokenComment {
+			idx = append(idx, i)
+		}
+	}
+
+	// named 对象名已处理, 之后只处理 REFERENCES
+	named, expect := false, false
+	for k, i := range idx {
+		t := tokens[i]
+		switch {
+		case !named && t.isKeyword("TABLE", "VIEW", "SEQUENCE"), t.isKeyword("REFERENCES"):
+			expect = true
+			continue
+		case expect && t.isKeyword("IF", "NOT", "EXISTS"):
+			continue
+		}
+		if !expect {
+			continue
+		}
+		expect = false
+		name, ok := t.identName()
+		if !ok {
+			continue
+		}
+		named = true
+		if k+1 < len(idx) && tokens[idx[k+1]].text == "." {
+			continue
+		}
+		tokens[i].text = o.objectName(name)
+	}
+
+	var b strings.Builder
+	b.Grow(len(stmt) + len(o.database) + 3)
+	for _, t := range tokens {
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
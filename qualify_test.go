@@ -0,0 +1,85 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_dumpOption_qualifyCreateSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "table",
+			sql:  "CREATE TABLE IF NOT EXISTS `t` (\n  `id` int NOT NULL,\n  `table` int\n)",
+			want: "CREATE TABLE IF NOT EXISTS `shop`.`t` (\n  `id` int NOT NULL,\n  `table` int\n)",
+		},
+		{
+			name: "foreign keys",
+			sql:  "CREATE TABLE `c` (\n  CONSTRAINT `fk1` FOREIGN KEY (`pid`) REFERENCES `p` (`id`),\n  CONSTRAINT `fk2` FOREIGN KEY (`oid`) REFERENCES `other`.`o` (`id`)\n)",
+			want: "CREATE TABLE `shop`.`c` (\n  CONSTRAINT `fk1` FOREIGN KEY (`pid`) REFERENCES `shop`.`p` (`id`),\n  CONSTRAINT `fk2` FOREIGN KEY (`oid`) REFERENCES `other`.`o` (`id`)\n)",
+		},
+		{
+			name: "view",
+			sql:  "CREATE OR REPLACE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v` AS select `shop`.`t`.`id` AS `id` from `shop`.`t`",
+			want: "CREATE OR REPLACE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `shop`.`v` AS select `shop`.`t`.`id` AS `id` from `shop`.`t`",
+		},
+		{
+			name: "sequence",
+			sql:  "CREATE SEQUENCE `s` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB",
+			want: "CREATE SEQUENCE `shop`.`s` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB",
+		},
+		{name: "qualified", sql: "CREATE TABLE `shop`.`t` (`id` int)", want: "CREATE TABLE `shop`.`t` (`id` int)"},
+	}
+	o := newDumpOption([]DumpOption{WithQualifiedNames()})
+	o.database = "shop"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := o.qualifyCreateSQL(tt.sql); got != tt.want {
+				t.Errorf("qualifyCreateSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	unqualified := newDumpOption(nil)
+	unqualified.database = "shop"
+	if got := unqualified.qualifyCreateSQL(tests[0].sql); got != tests[0].sql {
+		t.Errorf("qualifyCreateSQL() without option = %q", got)
+	}
+}
+
+func Test_sqlFormatter_QualifiedNames(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithQualifiedNames(), WithAddDropDatabase(), WithDropTable(), WithTruncateTable(),
+		WithAddLocks(), WithHeaderTemplate(nil), WithCompact()})
+	o.database = "shop"
+	o.createDatabaseSQL = "CREATE DATABASE `shop`"
+	table := &TableMeta{Name: "t", CreateSQL: "CREATE TABLE `t` (`id` int)", Columns: []string{"id"}, DataTypes: []string{"INT"}}
+
+	var sb strings.Builder
+	f := o.formatter
+	err := f.Header(&sb, &DumpMeta{Database: "shop"})
+	if err == nil {
+		err = f.TableSchema(&sb, table)
+	}
+	if err == nil {
+		err = f.TableDataBegin(&sb, table)
+	}
+	if err == nil {
+		err = f.Row(&sb, table, []interface{}{int64(1)})
+	}
+	if err == nil {
+		err = f.TableDataEnd(&sb, table)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DROP DATABASE IF EXISTS `shop`;\nCREATE DATABASE `shop`;\n" +
+		"DROP TABLE IF EXISTS `shop`.`t`;\nCREATE TABLE `shop`.`t` (`id` int);\n" +
+		"TRUNCATE TABLE `shop`.`t`;\nLOCK TABLES `shop`.`t` WRITE;\n" +
+		"INSERT INTO `shop`.`t` VALUES (1);\nUNLOCK TABLES;\n"
+	if sb.String() != want {
+		t.Errorf("output = %q, want %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,51 @@
+package mysqldump
+
+import (
+	"database/sql"
+	"io"
+	"log"
+	"time"
+)
+
+// DumpQuery 将任意 SELECT 的结果导出为 table 表的数据, 默认为指定列名的 INSERT 语句
+// 可以通过 WithFormatter 导出为 CSV 等格式, 只输出数据, 不输出文件头尾和表结构
+// 支持 WithColumnTransform, WithMaskRules, WithSampleEvery 和 WithIgnoreInsertTable 等选项
+func DumpQuery(db *sql.DB, query string, table string, w io.Writer, opts ...DumpOption) error {
+	o := newDumpOption(opts)
+	start := time.Now()
+	counter := &countWriter{w: w}
+	buf := o.newBuffer(counter)
+
+	tw := &tableDataWriter{
+		// 查询的列不一定与表的列相同, INSERT 总是指定列名
+		meta:  &TableMeta{Name: table, PartialColumns: true},
+		buf:   buf,
+		o:     o,
+		flush: flushTimer{interval: o.flushInterval},
+	}
+	_, err := tw.writeRows(db, query)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	err = o.formatter.TableDataEnd(buf, tw.meta)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	err = buf.Flush()
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	if o.result != nil {
+		end := time.Now()
+		*o.result = DumpResult{
+			StartTime: start,
+			EndTime:   end,
+			Bytes:     counter.n,
+			Tables:    []TableResult{{Name: table, Rows: tw.rows, Bytes: counter.n, Duration: end.Sub(start)}},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package mysqldump
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestDumpQuery(t *testing.T) {
+	db, f := newFakeDB(t)
+	f.on("SELECT id, email FROM users", []string{"id INT", "email VARCHAR"},
+		[]driver.Value{int64(1), []byte("alice@example.com")},
+		[]driver.Value{int64(2), nil})
+
+	var sb strings.Builder
+	result := &DumpResult{}
+	err := DumpQuery(db, "SELECT id, email FROM users WHERE id < 3", "users_copy", &sb,
+		WithCompact(), WithResult(result), WithColumnTransform("users_copy", "email", MaskEmail))
+	if err != nil {
+		t.Fatalf("DumpQuery() error = %v", err)
+	}
+	// 查询的列总是指定列名, 只输出数据
+	want := "INSERT INTO `users_copy` (`id`,`email`) VALUES (1,'a****@example.com');\n" +
+		"INSERT INTO `users_copy` (`id`,`email`) VALUES (2,NULL);\n"
+	if sb.String() != want {
+		t.Errorf("DumpQuery() = %q, want %q", sb.String(), want)
+	}
+	if len(result.Tables) != 1 || result.Tables[0].Rows != 2 || result.Bytes != int64(len(want)) {
+		t.Errorf("result = %+v", result)
+	}
+}
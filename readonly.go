@@ -0,0 +1,66 @@
+package mysqldump
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrNotReadOnly WithReadOnlyGuard 时会话没有成功设置为只读
+var ErrNotReadOnly = errors.New("session is not read-only")
+
+// WithReadOnlyGuard 导出的每个连接建立后都设置为只读会话 (transaction_read_only=1), 并在导出前确认已生效,
+// 配置错误的备份任务也不会修改数据. DSN 中设置了 multiStatements=true 时拒绝导出
+// 只对 Dump 的源库生效, TiDB 需要开启 tidb_enable_noop_functions
+func WithReadOnlyGuard() DumpOption {
+	return func(option *dumpOption) {
+		option.readOnlyGuard = true
+	}
+}
+
+// readOnlyVariables 会话只读变量, MySQL 5.7.20 和 MariaDB 11.1 之前只有 tx_read_only
+var readOnlyVariables = []string{"transaction_read_only", "tx_read_only"}
+
+// errUnknownSystemVariable 服务端没有这个变量
+const errUnknownSystemVariable = 1193
+
+// openReadOnlyDB 打开数据库并确认连接为只读会话, 没有 WithReadOnlyGuard 时与 openDB 相同
+func openReadOnlyDB(dsn string, o *dumpOption) (*sql.DB, error) {
+	if !o.readOnlyGuard {
+		return openDB(dsn, o)
+	}
+	var err error
+	for _, name := range readOnlyVariables {
+		o.readOnlyVariable = name
+		var db *sql.DB
+		db, err = openDB(dsn, o)
+		if err != nil {
+			return nil, err
+		}
+		err = checkReadOnly(db, name)
+		if err == nil {
+			return db, nil
+		}
+		_ = db.Close()
+		var mysqlErr *mysql.MySQLError
+		if !errors.As(err, &mysqlErr) || mysqlErr.Number != errUnknownSystemVariable {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// checkReadOnly 确认会话变量 name 为 1
+func checkReadOnly(db queryer, name string) error {
+	var readOnly sql.NullString
+	err := db.QueryRow("SELECT @@SESSION." + name).Scan(&readOnly)
+	if err != nil {
+		return err
+	}
+	if readOnly.String != "1" {
+		return fmt.Errorf("%w: @@SESSION.%s = %s", ErrNotReadOnly, name, readOnly.String)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package mysqldump
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_openReadOnlyDB(t *testing.T) {
+	// 连接失败不是未知变量的错误, 不再尝试 tx_read_only
+	o := newDumpOption([]DumpOption{WithReadOnlyGuard()})
+	db, err := openReadOnlyDB("root:pass@tcp(127.0.0.1:1)/test?timeout=1s", o)
+	if err == nil {
+		db.Close()
+		t.Fatal("openReadOnlyDB() want connection error")
+	}
+	if errors.Is(err, ErrNotReadOnly) || o.readOnlyVariable != readOnlyVariables[0] {
+		t.Errorf("openReadOnlyDB() error = %v, variable = %s", err, o.readOnlyVariable)
+	}
+
+	// 没有 WithReadOnlyGuard 时不连接
+	o = newDumpOption(nil)
+	db, err = openReadOnlyDB("root:pass@tcp(127.0.0.1:1)/test", o)
+	if err != nil {
+		t.Fatalf("openReadOnlyDB() error = %v", err)
+	}
+	db.Close()
+}
@@ -0,0 +1,114 @@
+package mysqldump
+
+import "strings"
+
+// nameRewriter 按 token 重写语句中的数据库名和表名
+type nameRewriter struct {
+	database string
+	tables   map[string]string
+}
+
+func (n *nameRewriter) enabled() bool {
+	return n != nil && (n.database != "" || len(n.tables) > 0)
+}
+
+// table 返回重命名后的表名
+func (n *nameRewriter) table(name string) string {
+	if newName, ok := n.tables[name]; ok {
+		return newName
+	}
+	return name
+}
+
+// 后面跟表名的关键字
+var tableKeywords = []string{"TABLE", "TABLES", "INTO", "REFERENCES", "TRUNCATE"}
+
+// 后面跟数据库名的关键字
+var databaseKeywords = []string{"USE", "DATABASE", "SCHEMA"}
+
+// rewrite 重写 USE, CREATE/DROP DATABASE, CREATE/DROP/ALTER/TRUNCATE/LOCK TABLE, INSERT/REPLACE INTO,
+// REFERENCES 中的数据库名和表名, 以及 `db`.`table` 中的数据库名, 字符串和注释中的内容不变
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func (n *nameRewriter) rewrite(stmt string) string {
+	if !n.enabled() {
+		return stmt
+	}
+	tokens := tokenizeSQL(stmt)
+
+	// 有意义的 token 下标
+	var idx []int
+	for i, t := range tokens {
+		if t.kind != tokenSpace && t.kind != tokenComment {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) == 0 {
+		return stmt
+	}
+	// DROP TABLE a, b 和 LOCK TABLES a READ, b WRITE 中逗号后也是表名
+	listStmt := tokens[idx[0]].isKeyword("DROP", "LOCK")
+
+	const (
+		expectNone = iota
+		expectTable
+		expectDatabase
+	)
+	expect := expectNone
+	for k := 0; k < len(idx); k++ {
+		t := tokens[idx[k]]
+		switch {
+		case t.isKeyword(tableKeywords...):
+			expect = expectTable
+			continue
+		case t.isKeyword(databaseKeywords...):
+			expect = expectDatabase
+			continue
+		case expect != expectNone && t.isKeyword("IF", "NOT", "EXISTS", "IGNORE", "LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY", "TEMPORARY"):
+			continue
+		case listStmt && t.kind == tokenPunct && t.text == ",":
+			expect = expectTable
+			continue
+		}
+
+		name, ok := t.identName()
+		if !ok || expect == expectNone {
+			expect = expectNone
+			continue
+		}
+
+		if expect == expectDatabase {
+			if n.database != "" {
+				tokens[idx[k]].text = QuoteIdentifier(n.database)
+			}
+			expect = expectNone
+			continue
+		}
+
+		// `db`.`table`
+		if k+2 < len(idx) && tokens[idx[k+1]].text == "." {
+			if table, ok := tokens[idx[k+2]].identName(); ok {
+				if n.database != "" {
+					tokens[idx[k]].text = QuoteIdentifier(n.database)
+				}
+				if newName, ok := n.tables[table]; ok {
+					tokens[idx[k+2]].text = QuoteIdentifier(newName)
+				}
+				k += 2
+				expect = expectNone
+				continue
+			}
+		}
+		if newName, ok := n.tables[name]; ok {
+			tokens[idx[k]].text = QuoteIdentifier(newName)
+		}
+		expect = expectNone
+	}
+
+	var b strings.Builder
+	b.Grow(len(stmt))
+	for _, t := range tokens {
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
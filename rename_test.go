@@ -0,0 +1,59 @@
+package mysqldump
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_readStatement(t *testing.T) {
+	input := "INSERT INTO `t` VALUES ('a;b', \"c\\\";\", `x;y`);\n" +
+		"-- comment; here\n" +
+		"/* block; */ SELECT 1;\n" +
+		"# hash; comment\nSELECT 'it''s;';\n" +
+		"SELECT 2--1;\n" +
+		"trailing"
+	want := []string{
+		"INSERT INTO `t` VALUES ('a;b', \"c\\\";\", `x;y`);",
+		"\n-- comment; here\n/* block; */ SELECT 1;",
+		"\n# hash; comment\nSELECT 'it''s;';",
+		"\nSELECT 2--1;",
+	}
+	r := bufio.NewReader(strings.NewReader(input))
+	for _, w := range want {
+		got, err := readStatement(r)
+		if err != nil {
+			t.Fatalf("readStatement() error = %v", err)
+		}
+		if got != w {
+			t.Errorf("readStatement() = %q, want %q", got, w)
+		}
+	}
+	got, err := readStatement(r)
+	if err != io.EOF || got != "\ntrailing" {
+		t.Errorf("readStatement() = %q, %v, want trailing and EOF", got, err)
+	}
+}
+
+func Test_nameRewriter(t *testing.T) {
+	n := &nameRewriter{database: "prod_copy", tables: map[string]string{"users": "users_snapshot"}}
+	tests := []struct {
+		in, want string
+	}{
+		{"USE `prod`;", "USE `prod_copy`;"},
+		{"CREATE DATABASE IF NOT EXISTS prod;", "CREATE DATABASE IF NOT EXISTS `prod_copy`;"},
+		{"DROP TABLE IF EXISTS `users`, `orders`;", "DROP TABLE IF EXISTS `users_snapshot`, `orders`;"},
+		{"CREATE TABLE IF NOT EXISTS `users` (\n  `id` int COMMENT 'CREATE TABLE users',\n  CONSTRAINT `fk` FOREIGN KEY (`id`) REFERENCES `users` (`id`)\n);",
+			"CREATE TABLE IF NOT EXISTS `users_snapshot` (\n  `id` int COMMENT 'CREATE TABLE users',\n  CONSTRAINT `fk` FOREIGN KEY (`id`) REFERENCES `users_snapshot` (`id`)\n);"},
+		{"INSERT IGNORE INTO `prod`.`users` VALUES (1,'INSERT INTO users');", "INSERT IGNORE INTO `prod_copy`.`users_snapshot` VALUES (1,'INSERT INTO users');"},
+		{"INSERT INTO orders VALUES (1);", "INSERT INTO orders VALUES (1);"},
+		{"LOCK TABLES `users` WRITE;", "LOCK TABLES `users_snapshot` WRITE;"},
+		{"TRUNCATE TABLE users;", "TRUNCATE TABLE `users_snapshot`;"},
+	}
+	for _, tt := range tests {
+		if got := n.rewrite(tt.in); got != tt.want {
+			t.Errorf("rewrite(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
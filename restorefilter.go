@@ -0,0 +1,194 @@
+package mysqldump
+
+import "strings"
+
+// WithRestoreTables 只恢复指定表的语句, 其他表的结构和数据跳过
+// 指定表时不执行 CREATE/DROP DATABASE 和账号权限等不属于表的结构语句
+func WithRestoreTables(tables ...string) SourceOption {
+	return func(o *sourceOption) {
+		o.filter.tables = append(o.filter.tables, tables...)
+	}
+}
+
+// WithRestoreIgnoreTables 恢复时跳过指定表的语句
+func WithRestoreIgnoreTables(tables ...string) SourceOption {
+	return func(o *sourceOption) {
+		o.filter.ignoreTables = append(o.filter.ignoreTables, tables...)
+	}
+}
+
+// WithSchemaOnly 只恢复表结构, 跳过 INSERT, LOAD DATA, TRUNCATE 等数据语句
+func WithSchemaOnly() SourceOption {
+	return func(o *sourceOption) {
+		o.filter.schemaOnly = true
+	}
+}
+
+// WithDataOnly 只恢复数据, 跳过 CREATE, DROP, ALTER 等结构语句
+func WithDataOnly() SourceOption {
+	return func(o *sourceOption) {
+		o.filter.dataOnly = true
+	}
+}
+
+// stmtKind 恢复时语句的类型
+type stmtKind int
+
+const (
+	// SET, USE, COMMIT 等会话语句, 总是执行
+	stmtSession stmtKind = iota
+	// 表结构, 数据库和账号
+	stmtSchema
+	// 表数据
+	stmtData
+)
+
+// restoreFilter 恢复时按表和语句类型过滤
+type restoreFilter struct {
+	tables       []string
+	ignoreTables []string
+	schemaOnly   bool
+	dataOnly     bool
+}
+
+func (f *restoreFilter) enabled() bool {
+	return len(f.tables) > 0 || len(f.ignoreTables) > 0 || f.schemaOnly || f.dataOnly
+}
+
+// skip 返回语句是否跳过
+func (f *restoreFilter) skip(stmt string) bool {
+	if !f.enabled() {
+		return false
+	}
+	kind, table := classifyStatement(stmt)
+	switch {
+	case kind == stmtSession:
+		return false
+	case f.schemaOnly && kind == stmtData, f.dataOnly && kind == stmtSchema:
+		return true
+	case len(f.tables) == 0 && len(f.ignoreTables) == 0:
+		return false
+	case table == "":
+		// 按表过滤时不执行删除或创建整个数据库等语句
+		return kind == stmtSchema
+	}
+	return f.skipTable(table)
+}
+
+// skipTable 返回是否跳过表的所有语句
+func (f *restoreFilter) skipTable(table string) bool {
+	if containsFold(f.ignoreTables, table) {
+		return true
+	}
+	return len(f.tables) > 0 && !containsFold(f.tables, table)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// statementTokens 返回语句中有意义的 token, /*!40000 ... */ 形式的版本注释按其中的语句处理
+func statementTokens(stmt string) []sqlToken {
+	var tokens []sqlToken
+	for _, t := range tokenizeSQL(stmt) {
+		switch t.kind {
+		case tokenSpace:
+		case tokenComment:
+			if strings.HasPrefix(t.text, "/*!") && strings.HasSuffix(t.text, "*/") {
+				inner := strings.TrimLeft(t.text[3:len(t.text)-2], "0123456789")
+				tokens = append(tokens, statementTokens(inner)...)
+			}
+		default:
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// classifyStatement 返回语句的类型和涉及的表, 不属于某个表的语句 table 为空
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func classifyStatement(stmt string) (stmtKind, string) {
+	tokens := statementTokens(stmt)
+	if len(tokens) == 0 {
+		return stmtSession, ""
+	}
+	// tableAfter 返回第一个 keywords 之后的表名, 跳过 IF [NOT] EXISTS 等修饰词
+	tableAfter := func(keywords ...string) string {
+		for i, t := range tokens {
+			if !t.isKeyword(keywords...) {
+				continue
+			}
+			for j := i + 1; j < len(tokens); j++ {
+				if tokens[j].isKeyword("IF", "NOT", "EXISTS", "IGNORE", "LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY", "INTO", "TABLE") {
+					continue
+				}
+				return qualifiedTableName(tokens[j:])
+			}
+		}
+		return ""
+	}
+
+	first := tokens[0]
+	switch {
+	case first.isKeyword("INSERT", "REPLACE"):
+		return stmtData, tableAfter("INSERT", "REPLACE")
+	case first.isKeyword("LOAD"):
+		return stmtData, tableAfter("INTO")
+	case first.isKeyword("TRUNCATE"):
+		return stmtData, tableAfter("TRUNCATE")
+	case first.isKeyword("LOCK"):
+		return stmtData, tableAfter("TABLES", "TABLE")
+	case first.isKeyword("SELECT"):
+		// 序列的 SELECT SETVAL(`seq`, n, 0)
+		if len(tokens) > 3 && tokens[1].isKeyword("SETVAL") && tokens[2].text == "(" {
+			return stmtData, qualifiedTableName(tokens[3:])
+		}
+		return stmtSession, ""
+	case first.isKeyword("ALTER"):
+		if len(tokens) > 1 && tokens[1].isKeyword("TABLE") {
+			table := tableAfter("TABLE")
+			last := tokens[len(tokens)-1]
+			if last.text == ";" && len(tokens) > 1 {
+				last = tokens[len(tokens)-2]
+			}
+			if last.isKeyword("KEYS") {
+				return stmtData, table
+			}
+			return stmtSchema, table
+		}
+		return stmtSchema, ""
+	case first.isKeyword("CREATE", "DROP"):
+		for _, t := range tokens[1:] {
+			switch {
+			case t.isKeyword("TABLE", "VIEW", "SEQUENCE"):
+				return stmtSchema, tableAfter("TABLE", "VIEW", "SEQUENCE")
+			case t.isKeyword("DATABASE", "SCHEMA", "USER", "ROLE"):
+				return stmtSchema, ""
+			}
+		}
+		return stmtSchema, ""
+	case first.isKeyword("GRANT", "REVOKE"):
+		return stmtSchema, ""
+	}
+	return stmtSession, ""
+}
+
+// qualifiedTableName 返回 tokens 开头的表名, `db`.`table` 形式时返回表名
+func qualifiedTableName(tokens []sqlToken) string {
+	name, ok := tokens[0].identName()
+	if !ok {
+		return ""
+	}
+	if len(tokens) > 2 && tokens[1].text == "." {
+		if table, ok := tokens[2].identName(); ok {
+			return table
+		}
+	}
+	return name
+}
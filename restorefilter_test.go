@@ -0,0 +1,71 @@
+package mysqldump
+
+import "testing"
+
+func Test_classifyStatement(t *testing.T) {
+	tests := []struct {
+		stmt  string
+		kind  stmtKind
+		table string
+	}{
+		{"INSERT INTO `t1` VALUES (1);", stmtData, "t1"},
+		{"-- ----------------------------\n-- Records of t1\n-- ----------------------------\nINSERT IGNORE INTO t1 VALUES (1);", stmtData, "t1"},
+		{"REPLACE INTO `db`.`t2` (`a`) VALUES (1);", stmtData, "t2"},
+		{"LOAD DATA LOCAL INFILE 'x' INTO TABLE `t3`;", stmtData, "t3"},
+		{"TRUNCATE TABLE `t1`;", stmtData, "t1"},
+		{"LOCK TABLES `t1` WRITE;", stmtData, "t1"},
+		{"/*!40000 ALTER TABLE `t1` DISABLE KEYS */;", stmtData, "t1"},
+		{"SELECT SETVAL(`s1`, 100, 0);", stmtData, "s1"},
+		{"DROP TABLE IF EXISTS `a``b`;", stmtSchema, "a`b"},
+		{"CREATE TABLE IF NOT EXISTS `t1` (\n`id` int\n);", stmtSchema, "t1"},
+		{"CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `v1` AS select 1;", stmtSchema, "v1"},
+		{"ALTER TABLE `t1` ADD COLUMN `c` int;", stmtSchema, "t1"},
+		{"/*!40000 DROP DATABASE IF EXISTS `db`*/;", stmtSchema, ""},
+		{"GRANT SELECT ON `db`.* TO 'u'@'%';", stmtSchema, ""},
+		{"SET NAMES utf8mb4;", stmtSession, ""},
+		{"/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE */;", stmtSession, ""},
+		{"UNLOCK TABLES;", stmtSession, ""},
+		{"USE `db`;", stmtSession, ""},
+	}
+	for _, tt := range tests {
+		kind, table := classifyStatement(tt.stmt)
+		if kind != tt.kind || table != tt.table {
+			t.Errorf("classifyStatement(%q) = %v, %q, want %v, %q", tt.stmt, kind, table, tt.kind, tt.table)
+		}
+	}
+}
+
+func Test_restoreFilter_skip(t *testing.T) {
+	stmts := []string{
+		"DROP DATABASE IF EXISTS `db`;",
+		"CREATE TABLE `t1` (`id` int);",
+		"INSERT INTO `t1` VALUES (1);",
+		"CREATE TABLE `t2` (`id` int);",
+		"INSERT INTO `t2` VALUES (1);",
+		"SET FOREIGN_KEY_CHECKS=1;",
+	}
+	tests := []struct {
+		name string
+		opts []SourceOption
+		want []bool
+	}{
+		{name: "none", want: []bool{false, false, false, false, false, false}},
+		{name: "tables", opts: []SourceOption{WithRestoreTables("t2")}, want: []bool{true, true, true, false, false, false}},
+		{name: "ignore", opts: []SourceOption{WithRestoreIgnoreTables("t2")}, want: []bool{true, false, false, true, true, false}},
+		{name: "schema only", opts: []SourceOption{WithSchemaOnly()}, want: []bool{false, false, true, false, true, false}},
+		{name: "data only", opts: []SourceOption{WithDataOnly(), WithRestoreTables("t1")}, want: []bool{true, true, false, true, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o sourceOption
+			for _, opt := range tt.opts {
+				opt(&o)
+			}
+			for i, stmt := range stmts {
+				if got := o.filter.skip(stmt); got != tt.want[i] {
+					t.Errorf("skip(%q) = %v, want %v", stmt, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
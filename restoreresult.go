@@ -0,0 +1,170 @@
+package mysqldump
+
+import "time"
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// 执行的语句数, 合并执行的 INSERT 按原语句计算
+	Statements int64
+	// 被 WithRestoreTables 等选项跳过的语句数
+	SkippedStatements int64
+	// 读取的字节数
+	Bytes int64
+	// 按第一次出现的顺序记录每个表的恢复结果
+	Tables []RestoreTableResult
+}
+
+// RestoreTableResult 表的恢复结果
+type RestoreTableResult struct {
+	Name string
+	// INSERT 的行数, WithTab 导出的目录为 LOAD DATA 导入的行数
+	Rows     int64
+	Duration time.Duration
+}
+
+// Rows 恢复的总行数
+func (r *RestoreResult) Rows() int64 {
+	var rows int64
+	for _, table := range r.Tables {
+		rows += table.Rows
+	}
+	return rows
+}
+
+// RestoreProgress 恢复进度, 每执行一条语句回调
+type RestoreProgress struct {
+	// 当前的表, 还没有执行表的语句时为空
+	Table      string
+	Statements int64
+	Bytes      int64
+	Rows       int64
+}
+
+// WithRestoreResult 恢复结束后将结果写入 result
+func WithRestoreResult(result *RestoreResult) SourceOption {
+	return func(o *sourceOption) {
+		o.result = result
+	}
+}
+
+// WithRestoreProgress 每执行一条语句回调 fn
+func WithRestoreProgress(fn func(RestoreProgress)) SourceOption {
+	return func(o *sourceOption) {
+		o.progress = fn
+	}
+}
+
+// restoreTracker 统计恢复的语句, 字节数和每个表的行数和耗时
+type restoreTracker struct {
+	result   *RestoreResult
+	progress func(RestoreProgress)
+	rows     int64
+	// 表在 result.Tables 中的下标
+	index map[string]int
+	// 当前的表和开始执行的时间
+	current      int
+	currentStart time.Time
+}
+
+func newRestoreTracker(o *sourceOption, start time.Time) *restoreTracker {
+	result := o.result
+	if result != nil {
+		*result = RestoreResult{}
+	} else {
+		result = &RestoreResult{}
+	}
+	result.StartTime = start
+	return &restoreTracker{result: result, progress: o.progress, index: make(map[string]int), current: -1}
+}
+
+// read 读取了一条语句
+func (t *restoreTracker) read(stmt string) {
+	t.result.Bytes += int64(len(stmt))
+}
+
+// skipped 跳过了一条语句
+func (t *restoreTracker) skipped() {
+	t.result.SkippedStatements++
+}
+
+// executed 执行了一条语句
+func (t *restoreTracker) executed(stmt string) {
+	kind, table := classifyStatement(stmt)
+	var rows int64
+	if kind == stmtData {
+		rows = countInsertRows(stmt)
+	}
+	t.add(table, rows)
+}
+
+// add 记录执行了表的一条语句, table 为空时不改变当前的表
+func (t *restoreTracker) add(table string, rows int64) {
+	now := time.Now()
+	t.result.Statements++
+	if table != "" && (t.current < 0 || t.result.Tables[t.current].Name != table) {
+		t.switchTable(now)
+		i, ok := t.index[table]
+		if !ok {
+			i = len(t.result.Tables)
+			t.index[table] = i
+			t.result.Tables = append(t.result.Tables, RestoreTableResult{Name: table})
+		}
+		t.current = i
+	}
+	if t.current >= 0 {
+		t.result.Tables[t.current].Rows += rows
+	}
+	t.rows += rows
+
+	if t.progress != nil {
+		p := RestoreProgress{Statements: t.result.Statements, Bytes: t.result.Bytes, Rows: t.rows}
+		if t.current >= 0 {
+			p.Table = t.result.Tables[t.current].Name
+		}
+		t.progress(p)
+	}
+}
+
+// switchTable 结束当前表的计时
+func (t *restoreTracker) switchTable(now time.Time) {
+	if t.current >= 0 {
+		t.result.Tables[t.current].Duration += now.Sub(t.currentStart)
+	}
+	t.currentStart = now
+}
+
+// finish 恢复结束
+func (t *restoreTracker) finish() {
+	t.result.EndTime = time.Now()
+	t.switchTable(t.result.EndTime)
+	t.current = -1
+}
+
+// countInsertRows 返回 INSERT ... VALUES 语句的行数, 不是 INSERT 时返回 0
+func countInsertRows(stmt string) int64 {
+	var rows int64
+	depth := 0
+	values := false
+	for _, t := range tokenizeSQL(stmt) {
+		switch {
+		case t.kind != tokenPunct && t.kind != tokenWord:
+		case !values:
+			values = depth == 0 && t.isKeyword("VALUES", "VALUE")
+			if t.text == "(" {
+				depth++
+			} else if t.text == ")" {
+				depth--
+			}
+		case t.text == "(":
+			if depth == 0 {
+				rows++
+			}
+			depth++
+		case t.text == ")":
+			depth--
+		}
+	}
+	return rows
+}
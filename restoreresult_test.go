@@ -0,0 +1,58 @@
+package mysqldump
+
+import "testing"
+
+func Test_countInsertRows(t *testing.T) {
+	tests := []struct {
+		stmt string
+		want int64
+	}{
+		{"INSERT INTO `t` VALUES (1,'a');", 1},
+		{"INSERT INTO `t` (`id`,`name`) VALUES (1,'(x'),(2,CONCAT('a','b')),(3,NULL);", 3},
+		{"INSERT INTO `t` VALUE (1);", 1},
+		{"CREATE TABLE `t` (`id` int);", 0},
+		{"LOAD DATA LOCAL INFILE 'x' INTO TABLE `t`;", 0},
+	}
+	for _, tt := range tests {
+		if got := countInsertRows(tt.stmt); got != tt.want {
+			t.Errorf("countInsertRows(%q) = %d, want %d", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func Test_restoreTracker(t *testing.T) {
+	var result RestoreResult
+	var progress []RestoreProgress
+	o := &sourceOption{}
+	WithRestoreResult(&result)(o)
+	WithRestoreProgress(func(p RestoreProgress) { progress = append(progress, p) })(o)
+
+	tracker := newRestoreTracker(o, result.StartTime)
+	stmts := []string{
+		"SET NAMES utf8mb4;",
+		"CREATE TABLE `t1` (`id` int);",
+		"INSERT INTO `t1` VALUES (1),(2);",
+		"INSERT INTO `t2` VALUES (1);",
+		"INSERT INTO `t1` VALUES (3);",
+	}
+	for _, stmt := range stmts {
+		tracker.read(stmt)
+		tracker.executed(stmt)
+	}
+	tracker.read("INSERT INTO `t3` VALUES (1);")
+	tracker.skipped()
+	tracker.finish()
+
+	if result.Statements != 5 || result.SkippedStatements != 1 || result.Rows() != 4 {
+		t.Errorf("statements=%d skipped=%d rows=%d", result.Statements, result.SkippedStatements, result.Rows())
+	}
+	if len(result.Tables) != 2 || result.Tables[0].Name != "t1" || result.Tables[0].Rows != 3 || result.Tables[1].Rows != 1 {
+		t.Errorf("unexpected tables %+v", result.Tables)
+	}
+	if len(progress) != 5 || progress[0].Table != "" || progress[3].Table != "t2" || progress[4].Rows != 4 {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+	if result.Bytes == 0 || result.EndTime.IsZero() {
+		t.Errorf("bytes=%d end=%v", result.Bytes, result.EndTime)
+	}
+}
@@ -0,0 +1,69 @@
+package mysqldump
+
+import "time"
+
+// WithRestoreBatchSize 恢复时每执行 n 条语句提交一次事务, 默认在恢复结束时提交一次
+// 大的事务会长时间持有锁并在从库上造成延迟, 合并执行的 INSERT 按原语句计算
+func WithRestoreBatchSize(n int) SourceOption {
+	return func(o *sourceOption) {
+		o.batchSize = n
+	}
+}
+
+// WithRestoreRateLimit 限制恢复时每秒执行的语句数, 合并执行的 INSERT 按原语句计算
+func WithRestoreRateLimit(statementsPerSecond int) SourceOption {
+	return func(o *sourceOption) {
+		o.rateLimit = statementsPerSecond
+	}
+}
+
+// rateLimiter 按固定间隔放行, 不允许突发
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+	sleep    func(time.Duration)
+}
+
+// newRateLimiter 每秒放行 n 个, n <= 0 时返回 nil, 不限制
+func newRateLimiter(n int) *rateLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Second / time.Duration(n), sleep: time.Sleep}
+}
+
+// wait 等待放行 n 个
+func (l *rateLimiter) wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	if d := l.next.Sub(now); d > 0 {
+		l.sleep(d)
+	}
+	l.next = l.next.Add(l.interval * time.Duration(n))
+}
+
+// batchCommitter 每执行 size 条语句提交一次
+type batchCommitter struct {
+	db      *dbWrapper
+	size    int
+	pending int
+}
+
+// executed 记录执行了 n 条语句, 达到批量大小时提交
+func (b *batchCommitter) executed(n int) error {
+	if b.size <= 0 {
+		return nil
+	}
+	b.pending += n
+	if b.pending < b.size {
+		return nil
+	}
+	b.pending = 0
+	_, err := b.db.Exec("COMMIT;")
+	return err
+}
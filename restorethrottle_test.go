@@ -0,0 +1,37 @@
+package mysqldump
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_rateLimiter(t *testing.T) {
+	if newRateLimiter(0) != nil {
+		t.Fatal("rate limit 0 should not limit")
+	}
+	var slept time.Duration
+	l := newRateLimiter(10)
+	l.sleep = func(d time.Duration) { slept += d }
+	l.wait(1)
+	l.wait(5)
+	l.wait(1)
+	// 第一次立即放行, sleep 不推进时间, 第二次等待 1 个间隔, 第三次等待到 6 个间隔之后
+	if slept < 690*time.Millisecond || slept > 700*time.Millisecond {
+		t.Errorf("slept %s, want about 700ms", slept)
+	}
+}
+
+func Test_batchCommitter(t *testing.T) {
+	b := &batchCommitter{db: newDBWrapper(nil, true, false), size: 3}
+	for _, tt := range []struct {
+		n       int
+		pending int
+	}{{2, 2}, {2, 0}, {1, 1}, {5, 0}} {
+		if err := b.executed(tt.n); err != nil {
+			t.Fatal(err)
+		}
+		if b.pending != tt.pending {
+			t.Errorf("executed(%d): pending = %d, want %d", tt.n, b.pending, tt.pending)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package mysqldump
+
+import "time"
+
+// DumpResult 导出结果
+type DumpResult struct {
+	Database string
+	// 服务端类型和版本
+	ServerVersion ServerVersion
+	StartTime     time.Time
+	EndTime       time.Time
+	// 写出的字节数
+	Bytes int64
+	// 导出内容的 sha256, 断点续传时为空
+	Checksum string
+	// 开始导出时的 binlog 位置
+	BinlogFile     string
+	BinlogPosition uint64
+	// 开始导出时已执行的 GTID 集合, MariaDB 为 gtid_binlog_pos
+	GTIDExecuted string
+	// WithServerVariables 记录的全局变量
+	ServerVariables []ServerVariable
+	// 每个表的导出结果
+	Tables []TableResult
+	// 被脱敏的列
+	MaskedColumns []MaskedColumn
+	// WithSkipFailedTables 时导出失败并跳过的表, 以及 WithCommentBrokenViews 时无效的视图
+	FailedTables []FailedTable
+	// 不影响继续导出的问题, 如没有主键无法分块的表
+	Warnings []Warning
+}
+
+// TableResult 表的导出结果
+type TableResult struct {
+	Name string
+	// 导出的行数
+	Rows int64
+	// 表结构和数据写出的字节数
+	Bytes    int64
+	Duration time.Duration
+	// 表结构和数据输出的 sha256, WithManifest 或 WithSummaryFooter 时计算, 断点续传的表为空
+	Checksum string
+}
+
+// Rows 导出的总行数
+func (r *DumpResult) Rows() int64 {
+	var rows int64
+	for _, table := range r.Tables {
+		rows += table.Rows
+	}
+	return rows
+}
+
+// FailedTable 导出失败的表
+type FailedTable struct {
+	Name  string
+	Error string
+}
+
+// MaskedColumn 被脱敏的列
+type MaskedColumn struct {
+	Table  string
+	Column string
+	// 使用的脱敏方式, 自定义转换为 custom
+	Mask string
+}
+
+// WithResult 导出结束后将结果写入 result
+func WithResult(result *DumpResult) DumpOption {
+	return func(option *dumpOption) {
+		option.result = result
+	}
+}
@@ -0,0 +1,127 @@
+package mysqldump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// MaskRule 脱敏规则, Table Column 支持通配符, 如 *_email
+// Type 匹配列的数据类型, 如 VARCHAR, 为空时不限制
+type MaskRule struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Type   string `json:"type"`
+	// 脱敏方式: null, hash, email, phone, name
+	// 或生成假数据: fake_name, fake_email, fake_address, fake_phone
+	Mask string `json:"mask"`
+	// 生成假数据的种子, 相同种子和原值总是生成相同的假数据
+	Seed string `json:"seed"`
+}
+
+// maskers 内置脱敏方式
+var maskers = map[string]ColumnTransform{
+	"null":  MaskNull,
+	"hash":  MaskHash,
+	"email": MaskEmail,
+	"phone": MaskPhone,
+	"name":  MaskName,
+}
+
+// fakers 内置假数据生成方式
+var fakers = map[string]func(seed string) ColumnTransform{
+	"fake_name":    FakeName,
+	"fake_email":   FakeEmail,
+	"fake_address": FakeAddress,
+	"fake_phone":   FakePhone,
+}
+
+// transform 返回规则对应的转换函数, 未知脱敏方式返回 nil
+func (r MaskRule) transform() ColumnTransform {
+	if transform, ok := maskers[r.Mask]; ok {
+		return transform
+	}
+	if faker, ok := fakers[r.Mask]; ok {
+		return faker(r.Seed)
+	}
+	return nil
+}
+
+// LoadMaskRules 从 JSON 读取脱敏规则, 格式为 {"rules": [{"table": "*", "column": "*_email", "mask": "email"}]}
+func LoadMaskRules(reader io.Reader) ([]MaskRule, error) {
+	var config struct {
+		Rules []MaskRule `json:"rules"`
+	}
+	err := json.NewDecoder(reader).Decode(&config)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range config.Rules {
+		err = rule.validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return config.Rules, nil
+}
+
+// LoadMaskRulesFile 从 JSON 文件读取脱敏规则
+func LoadMaskRulesFile(name string) ([]MaskRule, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadMaskRules(f)
+}
+
+// WithMaskRules 按规则对匹配的列进行脱敏, 先匹配到的规则生效
+// WithColumnTransform 指定的列优先于规则
+func WithMaskRules(rules ...MaskRule) DumpOption {
+	return func(option *dumpOption) {
+		option.maskRules = append(option.maskRules, rules...)
+	}
+}
+
+func (r MaskRule) validate() error {
+	if r.transform() == nil {
+		return fmt.Errorf("mask rule: unknown mask %q", r.Mask)
+	}
+	for _, pattern := range []string{r.Table, r.Column} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("mask rule: bad pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// match 判断规则是否匹配列, 名称不区分大小写
+func (r MaskRule) match(table, column, dataType string) bool {
+	if !matchPattern(r.Table, table) || !matchPattern(r.Column, column) {
+		return false
+	}
+	return r.Type == "" || strings.EqualFold(r.Type, dataType)
+}
+
+func matchPattern(pattern, name string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && ok
+}
+
+// findMaskRule 返回第一个匹配列的规则的脱敏方式
+func findMaskRule(rules []MaskRule, table, column, dataType string) (string, ColumnTransform) {
+	for _, rule := range rules {
+		if rule.match(table, column, dataType) {
+			if transform := rule.transform(); transform != nil {
+				return rule.Mask, transform
+			}
+		}
+	}
+	return "", nil
+}
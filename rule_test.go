@@ -0,0 +1,39 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadMaskRules(t *testing.T) {
+	rules, err := LoadMaskRules(strings.NewReader(`{"rules": [
+		{"table": "users", "column": "phone", "mask": "phone"},
+		{"column": "*_email", "mask": "email"},
+		{"type": "BLOB", "mask": "null"}
+	]}`))
+	if err != nil {
+		t.Fatalf("LoadMaskRules() error = %v", err)
+	}
+
+	tests := []struct {
+		table, column, dataType string
+		want                    string
+	}{
+		{"users", "phone", "VARCHAR", "phone"},
+		{"orders", "phone", "VARCHAR", ""},
+		{"orders", "Contact_Email", "VARCHAR", "email"},
+		{"orders", "avatar", "BLOB", "null"},
+		{"orders", "name", "VARCHAR", ""},
+	}
+	for _, tt := range tests {
+		got, _ := findMaskRule(rules, tt.table, tt.column, tt.dataType)
+		if got != tt.want {
+			t.Errorf("findMaskRule(%s, %s, %s) = %q, want %q", tt.table, tt.column, tt.dataType, got, tt.want)
+		}
+	}
+
+	_, err = LoadMaskRules(strings.NewReader(`{"rules": [{"column": "x", "mask": "unknown"}]}`))
+	if err == nil {
+		t.Errorf("LoadMaskRules() want error for unknown mask")
+	}
+}
@@ -0,0 +1,201 @@
+package mysqldump
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type schedulerOption struct {
+	// 每次执行前随机等待 [0, jitter)
+	jitter time.Duration
+	// 失败后重试次数和间隔
+	retries      int
+	retryBackoff time.Duration
+	onSuccess    func(start time.Time, cost time.Duration)
+	onFailure    func(start time.Time, err error)
+}
+
+type SchedulerOption func(*schedulerOption)
+
+// WithScheduleJitter 每次执行前随机等待 [0, jitter), 避免多个任务同时开始
+func WithScheduleJitter(jitter time.Duration) SchedulerOption {
+	return func(o *schedulerOption) {
+		o.jitter = jitter
+	}
+}
+
+// WithScheduleRetry 失败后最多重试 retries 次, 每次间隔 backoff 并翻倍
+func WithScheduleRetry(retries int, backoff time.Duration) SchedulerOption {
+	return func(o *schedulerOption) {
+		o.retries = retries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithScheduleSuccess 执行成功的回调
+func WithScheduleSuccess(fn func(start time.Time, cost time.Duration)) SchedulerOption {
+	return func(o *schedulerOption) {
+		o.onSuccess = fn
+	}
+}
+
+// WithScheduleFailure 重试后仍失败的回调
+func WithScheduleFailure(fn func(start time.Time, err error)) SchedulerOption {
+	return func(o *schedulerOption) {
+		o.onFailure = fn
+	}
+}
+
+// Scheduler 按 cron 表达式定时执行任务, 上一次未结束时跳过本次
+type Scheduler struct {
+	schedule *cronSchedule
+	// 返回下次执行时间, 默认为 schedule.next
+	next func(time.Time) time.Time
+	job  func(ctx context.Context) error
+	o    schedulerOption
+
+	running int32
+	wg      sync.WaitGroup
+
+	// mu 保护 cancel, done 和 stopped, 任务在 mu 下确认未停止后才 wg.Add, 保证 Stop 的 wg.Wait 之后不再有新任务
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	// Start 启动的 Run 返回时关闭
+	done    chan struct{}
+	stopped bool
+}
+
+// NewScheduler 创建定时任务, spec 为 5 段 cron 表达式, 如 "0 3 * * *" 或 "@daily"
+func NewScheduler(spec string, job func(ctx context.Context) error, opts ...SchedulerOption) (*Scheduler, error) {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	s := &Scheduler{schedule: schedule, next: schedule.next, job: job}
+	for _, opt := range opts {
+		opt(&s.o)
+	}
+	return s, nil
+}
+
+// DumpJob 返回执行 Dump 的任务, opts 每次执行时调用, 以便每次使用新的 writer
+// 每次执行都会重新打开连接池, 需要复用时在任务中调用 Dumper.Run; Stop 后不再导出之后的表
+func DumpJob(dsn string, opts func() []DumpOption) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return runDump(ctx, dsn, newDumpOption(opts()))
+	}
+}
+
+// Start 在后台开始调度
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.cancel, s.done = cancel, done
+	s.mu.Unlock()
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+}
+
+// Stop 停止调度, 等待 Start 启动的 Run 返回和正在执行的任务结束, 之后不再执行任务
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if done != nil {
+		<-done
+	}
+	s.wg.Wait()
+}
+
+// Run 阻塞调度直到 ctx 结束或 Stop
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.next(time.Now())
+		if next.IsZero() {
+			log.Printf("[error] [scheduler] no next run time\n")
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// 上一次未结束时跳过
+		if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+			log.Printf("[info] [scheduler] skip run at %s, previous run not finished\n", next.Format("2006-01-02 15:04:05"))
+			continue
+		}
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			atomic.StoreInt32(&s.running, 0)
+			return
+		}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go func() {
+			defer s.wg.Done()
+			defer atomic.StoreInt32(&s.running, 0)
+			s.runOnce(ctx)
+		}()
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context) {
+	if s.o.jitter > 0 {
+		if !sleepContext(ctx, time.Duration(rand.Int63n(int64(s.o.jitter)))) {
+			return
+		}
+	}
+
+	start := time.Now()
+	backoff := s.o.retryBackoff
+	var err error
+	for attempt := 0; attempt <= s.o.retries; attempt++ {
+		if attempt > 0 {
+			log.Printf("[info] [scheduler] retry %d after %s: %v\n", attempt, backoff, err)
+			if !sleepContext(ctx, backoff) {
+				return
+			}
+			backoff *= 2
+		}
+		err = s.job(ctx)
+		if err == nil {
+			if s.o.onSuccess != nil {
+				s.o.onSuccess(start, time.Since(start))
+			}
+			return
+		}
+	}
+
+	log.Printf("[error] [scheduler] %v\n", err)
+	if s.o.onFailure != nil {
+		s.o.onFailure(start, err)
+	}
+}
+
+// sleepContext 等待 d, ctx 结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
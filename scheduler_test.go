@@ -0,0 +1,115 @@
+package mysqldump
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestScheduler 每 interval 执行一次 job
+func newTestScheduler(t *testing.T, interval time.Duration, job func(ctx context.Context) error) *Scheduler {
+	s, err := NewScheduler("* * * * *", job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.next = func(now time.Time) time.Time { return now.Add(interval) }
+	return s
+}
+
+func TestScheduler_StopWaitsForRunningJob(t *testing.T) {
+	started := make(chan struct{}, 1)
+	var finished atomic.Bool
+	s := newTestScheduler(t, time.Millisecond, func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished.Store(true)
+		return ctx.Err()
+	})
+	s.Start()
+	<-started
+	s.Stop()
+	if !finished.Load() {
+		t.Error("Stop() returned before the running job finished")
+	}
+}
+
+func TestScheduler_SkipOverlappingRuns(t *testing.T) {
+	release := make(chan struct{})
+	var runs atomic.Int32
+	s := newTestScheduler(t, time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+	s.Start()
+	// 上一次未结束, 之后的多次调度都跳过
+	time.Sleep(50 * time.Millisecond)
+	if got := runs.Load(); got != 1 {
+		t.Errorf("runs = %d while the first run is blocked, want 1", got)
+	}
+	close(release)
+	s.Stop()
+
+	// Stop 之后不再执行
+	got := runs.Load()
+	time.Sleep(10 * time.Millisecond)
+	if runs.Load() != got {
+		t.Error("job ran after Stop()")
+	}
+}
+
+func TestDumpJob_StopCancelsRunningDump(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	started := make(chan struct{}, 1)
+	var sb strings.Builder
+	job := DumpJob("root@tcp(127.0.0.1:1)/test", func() []DumpOption {
+		sb.Reset()
+		return []DumpOption{
+			WithData(),
+			WithWriter(&sb),
+			func(o *dumpOption) { o.sharedPool = db },
+			// 第一个表导出时等待 Stop
+			WithHooks(Hooks{BeforeTable: func(ctx context.Context, w io.Writer, table *TableMeta) error {
+				select {
+				case started <- struct{}{}:
+				default:
+				}
+				<-ctx.Done()
+				return nil
+			}}),
+		}
+	})
+	errs := make(chan error, 1)
+	s := newTestScheduler(t, time.Millisecond, func(ctx context.Context) error {
+		err := job(ctx)
+		errs <- err
+		return err
+	})
+	s.Start()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop() did not cancel the running dump")
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("job error = %v, want context.Canceled", err)
+	}
+	if strings.Contains(sb.String(), "`b`") {
+		t.Error("table b dumped after Stop()")
+	}
+}
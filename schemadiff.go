@@ -0,0 +1,189 @@
+package mysqldump
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"sort"
+)
+
+// SchemaDiff DiffSchema 的结果, 以导出文件为原结构, 数据库为新结构
+type SchemaDiff struct {
+	// 数据库中有, 导出文件中没有的表
+	AddedTables []string
+	// 导出文件中有, 数据库中没有的表
+	RemovedTables []string
+	// 两边都有但列不同的表
+	ChangedTables []TableDiff
+}
+
+// Empty 表结构是否相同
+func (d *SchemaDiff) Empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0
+}
+
+// TableDiff 一个表的列差异
+type TableDiff struct {
+	Name           string
+	AddedColumns   []string
+	RemovedColumns []string
+	ChangedColumns []ColumnDiff
+}
+
+// ColumnDiff 定义不同的列, From 和 To 为列名之后的定义, 如 varchar(64) NOT NULL
+type ColumnDiff struct {
+	Name string
+	From string
+	To   string
+}
+
+// schemaDef 一组表定义, names 保持出现的顺序
+type schemaDef struct {
+	names  []string
+	tables map[string]*tableDef
+	// 原始的 CREATE TABLE
+	createSQL map[string]string
+}
+
+func (s *schemaDef) add(def *tableDef, createSQL string) {
+	if s.tables == nil {
+		s.tables = make(map[string]*tableDef)
+		s.createSQL = make(map[string]string)
+	}
+	if _, ok := s.tables[def.name]; !ok {
+		s.names = append(s.names, def.name)
+	}
+	s.tables[def.name] = def
+	s.createSQL[def.name] = createSQL
+}
+
+// DiffSchema 比较导出文件中的 CREATE TABLE 与 dsn 数据库中的表结构, 报告增加, 删除和修改的表和列
+// 支持导出的表选择选项, 视图和序列不比较
+func DiffSchema(r io.Reader, dsn string, opts ...DumpOption) (*SchemaDiff, error) {
+	o := newDumpOption(opts)
+
+	from, err := readDumpSchema(r)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+
+	db, err := openDB(dsn, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	defer db.Close()
+
+	tables, _, err := selectTables(db, o)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return nil, err
+	}
+	to := &schemaDef{}
+	selected := make(map[string]bool)
+	for _, table := range tables {
+		if o.views[table] || o.sequences[table] {
+			continue
+		}
+		selected[table] = true
+		createTableSQL, err := getCreateTableSQL(db, table, o)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return nil, err
+		}
+		def, err := parseCreateTable(createTableSQL)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return nil, err
+		}
+		to.add(def, createTableSQL)
+	}
+
+	// 导出文件中只比较选中的表
+	filtered := &schemaDef{}
+	for _, name := range from.names {
+		if selected[name] || o.isAllTable && !containsFold(o.ignoreTables, name) {
+			filtered.add(from.tables[name], from.createSQL[name])
+		}
+	}
+	return diffSchemas(filtered, to), nil
+}
+
+// readDumpSchema 读取导出文件中的 CREATE TABLE
+func readDumpSchema(r io.Reader) (*schemaDef, error) {
+	schema := &schemaDef{}
+	br := bufio.NewReader(r)
+	for {
+		stmt, err := readStatement(br)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		tokens := statementTokens(stmt)
+		if len(tokens) > 0 && tokens[0].isKeyword("CREATE") && createObjectKind(tokens) == "TABLE" {
+			def, perr := parseCreateTable(stmt)
+			if perr != nil {
+				return nil, perr
+			}
+			schema.add(def, statementText(stmt))
+		}
+		if err == io.EOF {
+			return schema, nil
+		}
+	}
+}
+
+// diffSchemas 比较两组表定义
+func diffSchemas(from, to *schemaDef) *SchemaDiff {
+	diff := &SchemaDiff{}
+	for _, name := range to.names {
+		if _, ok := from.tables[name]; !ok {
+			diff.AddedTables = append(diff.AddedTables, name)
+		}
+	}
+	for _, name := range from.names {
+		def, ok := to.tables[name]
+		if !ok {
+			diff.RemovedTables = append(diff.RemovedTables, name)
+			continue
+		}
+		if td := diffTable(from.tables[name], def); td != nil {
+			diff.ChangedTables = append(diff.ChangedTables, *td)
+		}
+	}
+	sort.Strings(diff.AddedTables)
+	sort.Strings(diff.RemovedTables)
+	sort.Slice(diff.ChangedTables, func(i, j int) bool {
+		return diff.ChangedTables[i].Name < diff.ChangedTables[j].Name
+	})
+	return diff
+}
+
+// diffTable 比较两个表的列, 没有差异时返回 nil
+func diffTable(from, to *tableDef) *TableDiff {
+	td := TableDiff{Name: from.name}
+	fromColumns := make(map[string]columnDef)
+	for _, col := range from.columns {
+		fromColumns[col.name] = col
+	}
+	toColumns := make(map[string]bool)
+	for _, col := range to.columns {
+		toColumns[col.name] = true
+		old, ok := fromColumns[col.name]
+		switch {
+		case !ok:
+			td.AddedColumns = append(td.AddedColumns, col.name)
+		case old.definition != col.definition:
+			td.ChangedColumns = append(td.ChangedColumns, ColumnDiff{Name: col.name, From: old.definition, To: col.definition})
+		}
+	}
+	for _, col := range from.columns {
+		if !toColumns[col.name] {
+			td.RemovedColumns = append(td.RemovedColumns, col.name)
+		}
+	}
+	if len(td.AddedColumns) == 0 && len(td.RemovedColumns) == 0 && len(td.ChangedColumns) == 0 {
+		return nil
+	}
+	return &td
+}
@@ -0,0 +1,43 @@
+package mysqldump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	from, err := readDumpSchema(strings.NewReader("" +
+		"CREATE TABLE IF NOT EXISTS `users` (\n  `id` int NOT NULL,\n  `name` varchar(32) NOT NULL,\n  `age` int,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n" +
+		"CREATE TABLE `logs` (`id` int);\n" +
+		"CREATE TABLE `same` (`id` int);\n" +
+		"CREATE VIEW `v` AS select 1;\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := readDumpSchema(strings.NewReader("" +
+		"/*!40101 SET character_set_client = utf8mb4 */;\n" +
+		"CREATE TABLE `users` (\n  `id` int NOT NULL,\n  `name` varchar(64) NOT NULL,\n  `email` varchar(255) DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\n" +
+		"CREATE TABLE `same` (\n  `id` int\n);\n" +
+		"CREATE TABLE `orders` (`id` int);\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := diffSchemas(from, to)
+	want := &SchemaDiff{
+		AddedTables:   []string{"orders"},
+		RemovedTables: []string{"logs"},
+		ChangedTables: []TableDiff{{
+			Name:           "users",
+			AddedColumns:   []string{"email"},
+			RemovedColumns: []string{"age"},
+			ChangedColumns: []ColumnDiff{{Name: "name", From: "varchar(32) NOT NULL", To: "varchar(64) NOT NULL"}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSchemas() = %+v, want %+v", got, want)
+	}
+	if got.Empty() || !diffSchemas(to, to).Empty() {
+		t.Error("Empty() mismatch")
+	}
+}
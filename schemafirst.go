@@ -0,0 +1,53 @@
+package mysqldump
+
+import (
+	"bufio"
+	"context"
+	"log"
+)
+
+// WithSchemaFirst 先输出所有表, 视图和序列的结构, 再输出所有表的数据, 默认每个表的结构和数据相邻
+// 默认 SQL 格式会在文件头关闭 FOREIGN_KEY_CHECKS 并在文件尾恢复, 恢复时不受外键引用顺序的影响
+// 使用 WithTableWriter 输出到单独文件时不生效
+func WithSchemaFirst() DumpOption {
+	return func(option *dumpOption) {
+		option.schemaFirst = true
+	}
+}
+
+// writeSchemas 在导出数据前输出 tables 的结构, 输出过的表记录到 o.schemaWritten, dumpTable 不再输出
+// 断点续传时结构已经输出过, 只记录不输出
+func writeSchemas(ctx context.Context, db queryer, tables []string, buf *bufio.Writer, o *dumpOption) error {
+	o.schemaWritten = make(map[string]bool, len(tables))
+	for _, table := range tables {
+		o.schemaWritten[table] = true
+	}
+	if o.checkpoint.schemaDone() {
+		return nil
+	}
+	for _, table := range tables {
+		err := writeSchema(ctx, db, table, buf, o)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+	}
+	return o.checkpoint.schemaFinished(buf)
+}
+
+// writeSchema 输出表, 视图或序列的结构
+func writeSchema(ctx context.Context, db queryer, table string, w *bufio.Writer, o *dumpOption) error {
+	_, span := startSpan(ctx, o.tracer, "mysqldump.table_schema")
+	span.SetAttribute("db.sql.table", table)
+	var err error
+	switch {
+	case o.views[table]:
+		err = writeViewStruct(db, table, w, o)
+	case o.sequences[table]:
+		err = writeSequenceStruct(db, table, w, o)
+	default:
+		err = writeTableStruct(db, table, w, o)
+	}
+	endSpan(span, err)
+	return err
+}
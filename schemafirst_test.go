@@ -0,0 +1,66 @@
+package mysqldump
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_sqlFormatter_SchemaFirst(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithSchemaFirst(), WithHeaderTemplate(nil), WithFooterTemplate(nil)})
+	var sb strings.Builder
+	if err := o.formatter.Header(&sb, &DumpMeta{Database: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\n\nSET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;\n\n"; sb.String() != want {
+		t.Errorf("Header() = %q, want %q", sb.String(), want)
+	}
+	sb.Reset()
+	if err := o.formatter.Footer(&sb, &DumpMeta{Database: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sb.String(), "SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;\n") {
+		t.Errorf("Footer() = %q", sb.String())
+	}
+}
+
+func Test_writeSchemas_resumed(t *testing.T) {
+	dir := t.TempDir()
+	out, err := os.Create(filepath.Join(dir, "dump.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	c := &checkpoint{path: filepath.Join(dir, "dump.ckpt")}
+	if err = c.load(out); err != nil {
+		t.Fatal(err)
+	}
+	c.counter = &countWriter{w: out}
+	buf := bufio.NewWriter(c.counter)
+	_, _ = buf.WriteString("CREATE TABLE a;\n")
+	if err = c.schemaFinished(buf); err != nil {
+		t.Fatalf("schemaFinished() error = %v", err)
+	}
+
+	// 断点续传时结构已输出, 不查询数据库, 只记录表
+	o := newDumpOption([]DumpOption{WithSchemaFirst(), WithCheckpoint(c.path)})
+	if err = o.checkpoint.load(out); err != nil {
+		t.Fatal(err)
+	}
+	if !o.checkpoint.schemaDone() {
+		t.Fatalf("schemaDone() = false, state = %+v", o.checkpoint.state)
+	}
+	var sb strings.Builder
+	w := bufio.NewWriter(&sb)
+	if err = writeSchemas(context.Background(), nil, []string{"a", "b"}, w, o); err != nil {
+		t.Fatalf("writeSchemas() error = %v", err)
+	}
+	_ = w.Flush()
+	if sb.Len() != 0 || !o.schemaWritten["a"] || !o.schemaWritten["b"] {
+		t.Errorf("writeSchemas() output = %q, schemaWritten = %v", sb.String(), o.schemaWritten)
+	}
+}
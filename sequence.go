@@ -0,0 +1,118 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+)
+
+// getSequences 返回当前数据库中的序列, 只有 MariaDB 10.3 及以上版本有序列
+// MariaDB 的 SHOW TABLES 包含序列, 需要与普通表区分
+func getSequences(db queryer, v ServerVersion) (map[string]bool, error) {
+	sequences := make(map[string]bool)
+	if !v.supportsSequences() {
+		return sequences, nil
+	}
+	rows, err := db.Query("SELECT TABLE_NAME FROM information_schema.TABLES " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'SEQUENCE'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		sequences[name] = true
+	}
+	return sequences, rows.Err()
+}
+
+// sortSequencesFirst 将序列移到表之前, 恢复时列默认值中的 NEXTVAL 引用的序列已经存在
+func sortSequencesFirst(tables []string, sequences map[string]bool) []string {
+	result := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if sequences[table] {
+			result = append(result, table)
+		}
+	}
+	for _, table := range tables {
+		if !sequences[table] {
+			result = append(result, table)
+		}
+	}
+	return result
+}
+
+// getCreateSequenceSQL 返回 CREATE SEQUENCE 语句
+func getCreateSequenceSQL(db queryer, sequence string) (string, error) {
+	var name, createSequenceSQL string
+	err := db.QueryRow("SHOW CREATE SEQUENCE "+QuoteIdentifier(sequence)).Scan(&name, &createSequenceSQL)
+	if err != nil {
+		return "", err
+	}
+	return createSequenceSQL, nil
+}
+
+// getSequenceValue 返回序列下一个未缓存的值, 恢复时通过 SETVAL 设置
+func getSequenceValue(db queryer, sequence string) (string, error) {
+	var value string
+	err := db.QueryRow("SELECT next_not_cached_value FROM " + QuoteIdentifier(sequence)).Scan(&value)
+	return value, err
+}
+
+// sequenceSetval 恢复序列当前值的语句, 与 mariadb-dump 相同
+// name 为已加引号的序列名
+func sequenceSetval(name, value string) string {
+	return "SELECT SETVAL(" + name + ", " + value + ", 0);\n"
+}
+
+// writeSequenceStruct 导出序列定义, 导出数据时同时导出当前值
+func writeSequenceStruct(db queryer, sequence string, w io.Writer, o *dumpOption) error {
+	meta, err := getSequenceMeta(db, sequence, o)
+	if err != nil {
+		return err
+	}
+	return o.formatter.TableSchema(w, meta)
+}
+
+func getSequenceMeta(db queryer, sequence string, o *dumpOption) (*TableMeta, error) {
+	createSequenceSQL, err := getCreateSequenceSQL(db, sequence)
+	if err != nil {
+		return nil, err
+	}
+	meta := &TableMeta{Name: sequence, CreateSQL: createSequenceSQL, Sequence: true}
+	if o.isData {
+		meta.SequenceValue, err = getSequenceValue(db, sequence)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return meta, nil
+}
+
+// cloneSequence 在目标库创建序列并设置当前值
+func cloneSequence(ctx context.Context, src queryer, dst *sql.Conn, sequence string, o *dumpOption) error {
+	meta, err := getSequenceMeta(src, sequence, o)
+	if err != nil {
+		return err
+	}
+	if o.isDropTable {
+		_, err = dst.ExecContext(ctx, "DROP SEQUENCE IF EXISTS "+QuoteIdentifier(sequence))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = dst.ExecContext(ctx, meta.CreateSQL)
+	if err != nil {
+		return err
+	}
+	if meta.SequenceValue != "" {
+		_, err = dst.ExecContext(ctx, strings.TrimSuffix(sequenceSetval(QuoteIdentifier(meta.Name), meta.SequenceValue), ";\n"))
+	}
+	return err
+}
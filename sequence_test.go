@@ -0,0 +1,52 @@
+package mysqldump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_sortSequencesFirst(t *testing.T) {
+	got := sortSequencesFirst([]string{"a", "s1", "b", "s2"}, map[string]bool{"s1": true, "s2": true})
+	want := []string{"s1", "s2", "a", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortSequencesFirst() = %v, want %v", got, want)
+	}
+}
+
+func Test_sqlFormatter_sequence(t *testing.T) {
+	createSQL := "CREATE SEQUENCE `s` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB"
+	tests := []struct {
+		name  string
+		opts  []DumpOption
+		value string
+		want  string
+	}{
+		{
+			name: "schema",
+			want: "-- ----------------------------\n-- Sequence structure for s\n-- ----------------------------\n" +
+				createSQL + ";\n\n\n\n",
+		},
+		{
+			name:  "value",
+			opts:  []DumpOption{WithDropTable()},
+			value: "1001",
+			want: "DROP SEQUENCE IF EXISTS `s`;\n" +
+				"-- ----------------------------\n-- Sequence structure for s\n-- ----------------------------\n" +
+				createSQL + ";\nSELECT SETVAL(`s`, 1001, 0);\n\n\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			var sb strings.Builder
+			err := o.formatter.TableSchema(&sb, &TableMeta{Name: "s", CreateSQL: createSQL, Sequence: true, SequenceValue: tt.value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("TableSchema() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
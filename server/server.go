@@ -0,0 +1,264 @@
+// Package server 提供导出的 HTTP 接口
+//
+//	POST /dumps        后台导出到文件, 返回任务, 请求体为 DumpRequest
+//	GET  /dumps        任务列表
+//	GET  /dumps/{id}   任务状态和进度
+//	GET  /dump         直接以响应体流式返回导出内容, 参数 data=1&tables=a,b&ignore_tables=c
+//	GET  /catalog      导出记录目录
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-mmo/mysqldump"
+)
+
+// DumpRequest 导出请求
+type DumpRequest struct {
+	Data         bool     `json:"data"`
+	Tables       []string `json:"tables"`
+	IgnoreTables []string `json:"ignore_tables"`
+}
+
+func (r DumpRequest) options() []mysqldump.DumpOption {
+	var opts []mysqldump.DumpOption
+	if r.Data {
+		opts = append(opts, mysqldump.WithData())
+	}
+	if len(r.Tables) > 0 {
+		opts = append(opts, mysqldump.WithTables(r.Tables...))
+	}
+	if len(r.IgnoreTables) > 0 {
+		opts = append(opts, mysqldump.WithIgnoreTables(r.IgnoreTables...))
+	}
+	return opts
+}
+
+// Job 后台导出任务
+type Job struct {
+	ID       string             `json:"id"`
+	Request  DumpRequest        `json:"request"`
+	File     string             `json:"file"`
+	Status   string             `json:"status"`
+	Error    string             `json:"error,omitempty"`
+	Progress mysqldump.Progress `json:"progress"`
+	Start    time.Time          `json:"start"`
+	End      time.Time          `json:"end,omitempty"`
+}
+
+// Server 导出 HTTP 服务
+type Server struct {
+	dsn string
+	// 后台导出的文件目录
+	dir     string
+	catalog *mysqldump.Catalog
+	// 不为空时要求请求头 Authorization: Bearer <Token>
+	Token string
+	// 每次导出附加的选项
+	Options []mysqldump.DumpOption
+	// 保留的已结束任务数, 超过时删除最早结束的任务, 默认 100
+	MaxJobs int
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+	seq  int
+	mux  *http.ServeMux
+}
+
+// New 创建服务, 后台导出的文件写到 dir 并记录到 catalog, catalog 可以为 nil
+func New(dsn string, dir string, catalog *mysqldump.Catalog) *Server {
+	s := &Server{
+		dsn:     dsn,
+		dir:     dir,
+		catalog: catalog,
+		jobs:    make(map[string]*Job),
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /dumps", s.createJob)
+	s.mux.HandleFunc("GET /dumps", s.listJobs)
+	s.mux.HandleFunc("GET /dumps/{id}", s.getJob)
+	s.mux.HandleFunc("GET /dump", s.streamDump)
+	s.mux.HandleFunc("GET /catalog", s.listCatalog)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// 固定时间比较, 避免按响应时间逐字节猜出 Token
+	if s.Token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.Token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) createJob(w http.ResponseWriter, r *http.Request) {
+	var req DumpRequest
+	if r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.seq++
+	start := time.Now()
+	job := &Job{
+		ID:      strconv.Itoa(s.seq),
+		Request: req,
+		Status:  "running",
+		Start:   start,
+	}
+	job.File = filepath.Join(s.dir, fmt.Sprintf("dump-%s-%s.sql", start.Format("20060102-150405"), job.ID))
+	s.jobs[job.ID] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	go s.runJob(job)
+	writeJSON(w, http.StatusAccepted, snapshot)
+}
+
+func (s *Server) runJob(job *Job) {
+	err := s.dumpToFile(job)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.End = time.Now()
+	if err != nil {
+		job.Status = "failed"
+		job.Error = err.Error()
+	} else {
+		job.Status = "succeeded"
+	}
+	s.pruneJobs()
+}
+
+// pruneJobs 删除超过 MaxJobs 的最早结束的任务, 正在执行的任务不删除, 调用时需持有 mu
+func (s *Server) pruneJobs() {
+	limit := s.MaxJobs
+	if limit <= 0 {
+		limit = 100
+	}
+	var finished []*Job
+	for _, job := range s.jobs {
+		if !job.End.IsZero() {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) <= limit {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].End.Before(finished[j].End)
+	})
+	for _, job := range finished[:len(finished)-limit] {
+		delete(s.jobs, job.ID)
+	}
+}
+
+func (s *Server) dumpToFile(job *Job) error {
+	f, err := os.Create(job.File)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	opts := append(job.Request.options(), s.Options...)
+	opts = append(opts,
+		mysqldump.WithWriter(f),
+		mysqldump.WithProgress(func(p mysqldump.Progress) {
+			s.mu.Lock()
+			job.Progress = p
+			s.mu.Unlock()
+		}),
+	)
+	if s.catalog != nil {
+		opts = append(opts, mysqldump.WithCatalog(s.catalog, job.File))
+	}
+	return mysqldump.Dump(s.dsn, opts...)
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	s.mu.Unlock()
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Start.After(jobs[j].Start)
+	})
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	job, ok := s.jobs[r.PathValue("id")]
+	var snapshot Job
+	if ok {
+		snapshot = *job
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+func splitQuery(r *http.Request, key string) []string {
+	var list []string
+	for _, item := range strings.Split(r.URL.Query().Get(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func (s *Server) streamDump(w http.ResponseWriter, r *http.Request) {
+	data, _ := strconv.ParseBool(r.URL.Query().Get("data"))
+	req := DumpRequest{
+		Data:         data,
+		Tables:       splitQuery(r, "tables"),
+		IgnoreTables: splitQuery(r, "ignore_tables"),
+	}
+	w.Header().Set("Content-Type", "application/sql")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=dump-%s.sql", time.Now().Format("20060102-150405")))
+
+	// 客户端断开后 r.Context() 结束, 导出随之停止
+	d := mysqldump.NewDumper(s.dsn, append(req.options(), s.Options...)...)
+	defer d.Close()
+	err := d.Run(r.Context(), w)
+	if err != nil {
+		// 响应已开始, 只能记录日志并在末尾写出错误
+		log.Printf("[error] [server] %v\n", err)
+		_, _ = fmt.Fprintf(w, "\n-- ERROR: %v\n", err)
+	}
+}
+
+func (s *Server) listCatalog(w http.ResponseWriter, _ *http.Request) {
+	if s.catalog == nil {
+		writeJSON(w, http.StatusOK, []mysqldump.CatalogEntry{})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.catalog.List())
+}
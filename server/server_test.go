@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ai-mmo/mysqldump"
+)
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	catalog, err := mysqldump.OpenCatalog(filepath.Join(dir, "catalog.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = catalog.Add(mysqldump.CatalogEntry{Location: "a.sql", Start: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New("root@tcp(localhost:3306)/db", dir, catalog)
+	s.Token = "secret"
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/catalog", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /catalog without token = %d", rec.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/catalog", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	var entries []mysqldump.CatalogEntry
+	if err = json.NewDecoder(rec.Body).Decode(&entries); err != nil || len(entries) != 1 {
+		t.Errorf("GET /catalog = %d %v, %v", rec.Code, entries, err)
+	}
+
+	req = httptest.NewRequest("GET", "/dumps/404", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /dumps/404 = %d", rec.Code)
+	}
+}
+
+func TestServer_wrongToken(t *testing.T) {
+	s := New("root@tcp(localhost:3306)/db", t.TempDir(), nil)
+	s.Token = "secret"
+	for _, auth := range []string{"Bearer secre", "Bearer secrets", "secret", ""} {
+		req := httptest.NewRequest("GET", "/catalog", nil)
+		req.Header.Set("Authorization", auth)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("GET /catalog with %q = %d, want 401", auth, rec.Code)
+		}
+	}
+}
+
+func TestServer_pruneJobs(t *testing.T) {
+	s := New("root@tcp(localhost:3306)/db", t.TempDir(), nil)
+	s.MaxJobs = 2
+	now := time.Now()
+	s.jobs = map[string]*Job{
+		"1": {ID: "1", Status: "succeeded", End: now.Add(-3 * time.Hour)},
+		"2": {ID: "2", Status: "running"},
+		"3": {ID: "3", Status: "failed", End: now.Add(-2 * time.Hour)},
+		"4": {ID: "4", Status: "succeeded", End: now.Add(-time.Hour)},
+	}
+	s.pruneJobs()
+	// 删除最早结束的任务, 正在执行的任务保留
+	if len(s.jobs) != 3 || s.jobs["1"] != nil || s.jobs["2"] == nil {
+		t.Errorf("jobs after prune = %v", s.jobs)
+	}
+}
@@ -0,0 +1,118 @@
+package mysqldump
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var sessionVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithSessionVars 每个连接建立后设置的会话变量, 如 net_read_timeout, wait_timeout, transaction_isolation
+// 数字原样设置, 其他值作为字符串, 如 {"net_read_timeout": "3600", "transaction_isolation": "READ-COMMITTED"}
+func WithSessionVars(vars map[string]string) DumpOption {
+	return func(option *dumpOption) {
+		if option.sessionVars == nil {
+			option.sessionVars = make(map[string]string, len(vars))
+		}
+		for name, value := range vars {
+			option.sessionVars[name] = value
+		}
+	}
+}
+
+// sessionValue 返回 SET 语句中的值, 数字和已加引号的值原样返回
+func sessionValue(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value
+	}
+	return "'" + EscapeString(value) + "'"
+}
+
+// WithTimeZone 设置每个连接的 time_zone 和驱动解析时间使用的时区, TIMESTAMP 的值按 loc 输出, 如 time.UTC
+// 文件头中输出相同的 SET TIME_ZONE, 恢复时 TIMESTAMP 的值不会偏移
+// 固定偏移的时区以 '+08:00' 的形式设置, 其他时区使用名称, 如 'Asia/Shanghai', 需要服务端已加载时区表
+func WithTimeZone(loc *time.Location) DumpOption {
+	return func(option *dumpOption) {
+		option.timeZone = loc
+	}
+}
+
+// timeZoneName 返回 SET TIME_ZONE 使用的时区, 一年中偏移不变的时区返回 +hh:mm 的形式
+func timeZoneName(loc *time.Location) string {
+	year := time.Now().Year()
+	_, winter := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, summer := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	if winter != summer {
+		return loc.String()
+	}
+	sign := '+'
+	if winter < 0 {
+		sign, winter = '-', -winter
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, winter/3600, winter%3600/60)
+}
+
+// WithCompressProtocol 使用 MySQL 压缩协议传输, 通过广域网导出时减少网络流量, 需要服务端支持压缩
+// 会增加两端的 CPU 开销, 局域网中通常不需要. 也可以在 DSN 中设置 compress=true
+func WithCompressProtocol() DumpOption {
+	return func(option *dumpOption) {
+		option.compress = true
+	}
+}
+
+// openDB 打开数据库并设置连接池, 有会话变量或时区时由驱动在每个连接建立后设置
+// 连接池中的连接都会设置, 不受连接被回收重建的影响
+// DSN 可以使用 tcp 或 unix socket, 如 user:pass@unix(/var/run/mysqld/mysqld.sock)/db
+func openDB(dsn string, o *dumpOption) (*sql.DB, error) {
+	var db *sql.DB
+	if len(o.sessionVars) == 0 && o.timeZone == nil && !o.compress && o.readOnlyVariable == "" {
+		var err error
+		db, err = sql.Open("mysql", dsn)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Params == nil {
+			cfg.Params = make(map[string]string, len(o.sessionVars))
+		}
+		for name, value := range o.sessionVars {
+			if !sessionVarNameRe.MatchString(name) {
+				return nil, fmt.Errorf("invalid session variable name %q", name)
+			}
+			cfg.Params[strings.ToLower(name)] = sessionValue(value)
+		}
+		if o.timeZone != nil {
+			cfg.Loc = o.timeZone
+			cfg.Params["time_zone"] = "'" + timeZoneName(o.timeZone) + "'"
+		}
+		if o.readOnlyVariable != "" {
+			cfg.Params[o.readOnlyVariable] = "1"
+		}
+		if o.compress {
+			err = cfg.Apply(mysql.EnableCompression(true))
+			if err != nil {
+				return nil, err
+			}
+		}
+		connector, err := mysql.NewConnector(cfg)
+		if err != nil {
+			return nil, err
+		}
+		db = sql.OpenDB(connector)
+	}
+	o.configurePool(db)
+	return db, nil
+}
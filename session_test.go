@@ -0,0 +1,68 @@
+package mysqldump
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_sessionValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "3600", want: "3600"},
+		{in: "0.5", want: "0.5"},
+		{in: "READ-COMMITTED", want: "'READ-COMMITTED'"},
+		{in: "'+00:00'", want: "'+00:00'"},
+		{in: "a'b", want: "'a\\'b'"},
+	}
+	for _, tt := range tests {
+		if got := sessionValue(tt.in); got != tt.want {
+			t.Errorf("sessionValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func Test_openDB(t *testing.T) {
+	dsn := "root:pass@tcp(127.0.0.1:3306)/test"
+	db, err := openDB(dsn, newDumpOption([]DumpOption{WithSessionVars(map[string]string{"net_read_timeout": "3600"})}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	_, err = openDB(dsn, newDumpOption([]DumpOption{WithSessionVars(map[string]string{"a=1; DROP": "1"})}))
+	if err == nil {
+		t.Error("expected error for invalid session variable name")
+	}
+
+	for _, dsn := range []string{dsn, "root@unix(/var/run/mysqld/mysqld.sock)/test?parseTime=true"} {
+		db, err = openDB(dsn, newDumpOption([]DumpOption{WithCompressProtocol()}))
+		if err != nil {
+			t.Fatalf("openDB(%q) with compression error = %v", dsn, err)
+		}
+		db.Close()
+	}
+}
+
+func Test_timeZoneName(t *testing.T) {
+	tests := []struct {
+		loc  *time.Location
+		want string
+	}{
+		{loc: time.UTC, want: "+00:00"},
+		{loc: time.FixedZone("CST", 8*3600), want: "+08:00"},
+		{loc: time.FixedZone("NST", -(3*3600 + 30*60)), want: "-03:30"},
+	}
+	for _, tt := range tests {
+		if got := timeZoneName(tt.loc); got != tt.want {
+			t.Errorf("timeZoneName(%v) = %q, want %q", tt.loc, got, tt.want)
+		}
+	}
+	// 有夏令时的时区使用名称
+	if loc, err := time.LoadLocation("America/New_York"); err == nil {
+		if got := timeZoneName(loc); got != "America/New_York" {
+			t.Errorf("timeZoneName(%v) = %q", loc, got)
+		}
+	}
+}
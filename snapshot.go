@@ -0,0 +1,266 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+)
+
+// queryer *sql.DB 和快照连接共有的查询方法, 导出时的查询都通过它执行
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// WithSingleTransaction 在一个 REPEATABLE READ 的一致性快照事务中导出, InnoDB 表的数据是同一时间点的
+// 并发导出时每个 worker 连接开启同一时间点的快照, 没有 WithLockAllTables 时通过开启前后的 binlog 位置确认,
+// 期间有写入时重试, 仍不一致时产生 WarningInconsistentSnapshot 警告
+func WithSingleTransaction() DumpOption {
+	return func(option *dumpOption) {
+		option.singleTransaction = true
+	}
+}
+
+// WithLockAllTables 开启快照事务前短暂加全局读锁 FLUSH TABLES WITH READ LOCK, 快照事务开启后释放,
+// 记录的 binlog 位置和 GTID 与快照一致, 包含 WithSingleTransaction
+// 没有加全局读锁的权限时, MySQL 8.0 改用 LOCK INSTANCE FOR BACKUP 在导出期间阻止 DDL, 此时 binlog 位置不保证与快照一致
+func WithLockAllTables() DumpOption {
+	return func(option *dumpOption) {
+		option.singleTransaction = true
+		option.lockAllTables = true
+	}
+}
+
+// dumpConn 固定的一个连接, 用于快照事务和表锁等会话状态, 关闭时按顺序执行 release 中的语句
+type dumpConn struct {
+	ctx     context.Context
+	conn    *sql.Conn
+	release []string
+	// 从 snapshotConns 借出的连接, 关闭时放回
+	idle chan *sql.Conn
+}
+
+func (c *dumpConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn.QueryContext(c.ctx, query, args...)
+}
+
+func (c *dumpConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.conn.QueryRowContext(c.ctx, query, args...)
+}
+
+func (c *dumpConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.conn.ExecContext(c.ctx, query, args...)
+}
+
+// Close 释放锁或结束事务, 再将连接放回连接池
+func (c *dumpConn) Close() error {
+	if c.idle != nil {
+		c.idle <- c.conn
+		return nil
+	}
+	// 导出取消时也执行 release, 失败时不放回连接池, 避免 Dumper 之后的导出拿到未结束的事务或表锁
+	ctx := context.WithoutCancel(c.ctx)
+	released := true
+	for _, stmt := range c.release {
+		_, err := c.conn.ExecContext(ctx, stmt)
+		released = released && err == nil
+	}
+	if !released {
+		// Raw 返回 ErrBadConn 时连接池关闭这个连接
+		_ = c.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// openSnapshot 在一个连接上开启一致性快照事务
+// WithLockAllTables 时先加全局读锁, 在快照事务中读取 binlog 位置后释放
+func openSnapshot(ctx context.Context, db *sql.DB, o *dumpOption) (*dumpConn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snap := &dumpConn{ctx: ctx, conn: conn}
+
+	// TiDB 不支持全局读锁, 快照事务本身是一致的
+	globalLock := false
+	if o.lockAllTables && o.serverVersion.Flavor != FlavorTiDB {
+		_, err = conn.ExecContext(ctx, "FLUSH TABLES WITH READ LOCK")
+		if err == nil {
+			globalLock = true
+		} else if o.serverVersion.supportsBackupLock() {
+			log.Printf("[warn] [dump] %v, use LOCK INSTANCE FOR BACKUP instead\n", err)
+			_, err = conn.ExecContext(ctx, "LOCK INSTANCE FOR BACKUP")
+			if err == nil {
+				snap.release = append(snap.release, "UNLOCK INSTANCE")
+			}
+		}
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	// 并发导出时 worker 连接的快照需要与 snap 在同一时间点开启
+	// 全局读锁期间没有写入, TiDB 使用相同的 tidb_snapshot, 否则比较开启前后的 binlog 位置
+	workers := o.snapshotWorkers()
+	switch {
+	case workers == 0:
+		err = startSnapshot(snap)
+	case o.maxOpenConns > 0 && o.maxOpenConns < o.requiredConns():
+		err = fmt.Errorf("max open conns %d is less than %d needed by the parallel snapshot", o.maxOpenConns, o.requiredConns())
+	case globalLock || o.serverVersion.Flavor == FlavorTiDB:
+		err = startSnapshot(snap)
+		if err == nil {
+			o.snapshotConns, err = openSnapshotConns(ctx, db, snap, workers, o)
+		}
+	default:
+		err = startVerifiedSnapshot(ctx, db, snap, workers, o)
+	}
+	if err == nil {
+		snap.release = append([]string{"ROLLBACK"}, snap.release...)
+	}
+	if err == nil && o.lockAllTables {
+		o.result.BinlogFile, o.result.BinlogPosition = getBinlogPosition(snap, o.serverVersion)
+		o.result.GTIDExecuted = getGTIDExecuted(snap, o.serverVersion)
+	}
+	if globalLock {
+		_, uerr := conn.ExecContext(ctx, "UNLOCK TABLES")
+		if err == nil {
+			err = uerr
+		}
+	}
+	if err != nil {
+		o.snapshotConns.close()
+		o.snapshotConns = nil
+		_ = snap.Close()
+		return nil, err
+	}
+	return snap, nil
+}
+
+// startSnapshot 在连接上开启 REPEATABLE READ 的一致性快照事务
+func startSnapshot(c *dumpConn) error {
+	_, err := c.Exec("SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+	if err == nil {
+		_, err = c.Exec("START TRANSACTION /*!40100 WITH CONSISTENT SNAPSHOT */")
+	}
+	return err
+}
+
+// snapshotRetries 没有全局读锁时, 开启快照期间 binlog 位置变化后重试的次数
+const snapshotRetries = 3
+
+// startVerifiedSnapshot 没有全局读锁时开启 snap 和 worker 连接的快照, 开启前后 binlog 位置或 GTID 相同时所有快照在同一时间点
+// 有写入时重试, 多次重试后或无法读取 binlog 位置时产生警告并继续导出
+func startVerifiedSnapshot(ctx context.Context, db *sql.DB, snap *dumpConn, workers int, o *dumpOption) error {
+	for attempt := 1; ; attempt++ {
+		before := snapshotPosition(snap, o.serverVersion)
+		// START TRANSACTION 会提交上一次尝试的事务
+		err := startSnapshot(snap)
+		if err != nil {
+			return err
+		}
+		conns, err := openSnapshotConns(ctx, db, snap, workers, o)
+		if err != nil {
+			return err
+		}
+		after := snapshotPosition(snap, o.serverVersion)
+		if before != "" && before == after {
+			o.snapshotConns = conns
+			return nil
+		}
+		if before == "" || attempt == snapshotRetries {
+			o.snapshotConns = conns
+			message := "binlog position unavailable, worker snapshots may differ"
+			if before != "" {
+				message = fmt.Sprintf("binlog position changed while opening worker snapshots after %d attempts, worker snapshots may differ", attempt)
+			}
+			o.warn(Warning{Kind: WarningInconsistentSnapshot, Message: message})
+			return nil
+		}
+		conns.close()
+	}
+}
+
+// snapshotPosition 返回 binlog 位置和 GTID, 没有开启 binlog 时返回空
+func snapshotPosition(q queryer, v ServerVersion) string {
+	file, pos := getBinlogPosition(q, v)
+	gtid := getGTIDExecuted(q, v)
+	if file == "" && gtid == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d %s", file, pos, gtid)
+}
+
+// snapshotWorkers 返回需要共享快照的 worker 连接数, 串行导出且不按分区并发时为 0
+func (o *dumpOption) snapshotWorkers() int {
+	n := 0
+	if o.concurrency > 1 {
+		n = o.concurrency
+	}
+	if o.partitionConcurrency > 1 {
+		n += max(o.concurrency, 1) * o.partitionConcurrency
+	}
+	return n
+}
+
+// snapshotConns 已开启共享快照的 worker 连接, 导出结束时关闭
+type snapshotConns struct {
+	idle  chan *sql.Conn
+	conns []*dumpConn
+}
+
+// openSnapshotConns 打开 n 个 worker 连接并开启与 snap 相同时间点的快照
+// TiDB 设置 tidb_snapshot 为 snap 事务的开始时间, 其他数据库由调用方保证开启期间没有写入
+func openSnapshotConns(ctx context.Context, db *sql.DB, snap *dumpConn, n int, o *dumpOption) (*snapshotConns, error) {
+	var ts string
+	if o.serverVersion.Flavor == FlavorTiDB {
+		err := snap.QueryRow("SELECT @@tidb_current_ts").Scan(&ts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p := &snapshotConns{idle: make(chan *sql.Conn, n)}
+	for i := 0; i < n; i++ {
+		conn, err := openSessionConn(ctx, db, o)
+		if err != nil {
+			p.close()
+			return nil, err
+		}
+		p.conns = append(p.conns, conn)
+		if ts != "" {
+			_, err = conn.Exec("SET SESSION tidb_snapshot = '" + ts + "'")
+			conn.release = []string{"SET SESSION tidb_snapshot = ''"}
+		} else {
+			err = startSnapshot(conn)
+			conn.release = []string{"ROLLBACK"}
+		}
+		if err != nil {
+			p.close()
+			return nil, err
+		}
+		p.idle <- conn.conn
+	}
+	return p, nil
+}
+
+// get 取一个空闲的连接, 关闭时放回
+func (p *snapshotConns) get(ctx context.Context) (*dumpConn, error) {
+	select {
+	case conn := <-p.idle:
+		return &dumpConn{ctx: ctx, conn: conn, idle: p.idle}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *snapshotConns) close() {
+	if p == nil {
+		return
+	}
+	closeWorkerConns(p.conns)
+}
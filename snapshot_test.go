@@ -0,0 +1,56 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func Test_dumpOption_snapshotWorkers(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []DumpOption
+		want int
+	}{
+		{name: "serial", want: 0},
+		{name: "concurrency", opts: []DumpOption{WithConcurrency(4)}, want: 4},
+		{name: "partitions", opts: []DumpOption{WithPartitionConcurrency(3)}, want: 3},
+		{name: "both", opts: []DumpOption{WithConcurrency(2), WithPartitionConcurrency(3)}, want: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			if got := o.snapshotWorkers(); got != tt.want {
+				t.Errorf("snapshotWorkers() = %d, want %d", got, tt.want)
+			}
+			// 快照连接, 主连接和 worker 的其他连接都在连接池内
+			if o.snapshotWorkers()+1 > o.requiredConns() {
+				t.Errorf("requiredConns() = %d, less than snapshot conns", o.requiredConns())
+			}
+		})
+	}
+}
+
+func Test_snapshotConns_get(t *testing.T) {
+	p := &snapshotConns{idle: make(chan *sql.Conn, 1)}
+	p.idle <- nil
+	conn, err := p.get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有空闲连接时等待到取消
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = p.get(ctx); err != context.Canceled {
+		t.Errorf("get() error = %v, want %v", err, context.Canceled)
+	}
+
+	// 关闭时放回, 不关闭底层连接
+	if err = conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.idle) != 1 {
+		t.Errorf("idle conns = %d after Close, want 1", len(p.idle))
+	}
+}
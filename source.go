@@ -2,6 +2,7 @@ package mysqldump
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -15,6 +16,20 @@ type sourceOption struct {
 	dryRun      bool
 	mergeInsert int
 	debug       bool
+	// 重写数据库名和表名
+	rename nameRewriter
+	// 使用 LOAD DATA 导入 INSERT 的数据
+	loadData bool
+	// 按表和语句类型过滤
+	filter restoreFilter
+	// 恢复结果和进度
+	result   *RestoreResult
+	progress func(RestoreProgress)
+	// 每批提交的语句数和每秒执行的语句数
+	batchSize int
+	rateLimit int
+	// 没有文件尾时返回错误而不是警告
+	requireFooter bool
 }
 type SourceOption func(*sourceOption)
 
@@ -30,6 +45,17 @@ func WithMergeInsert(size int) SourceOption {
 	}
 }
 
+// WithRequireFooter 导出文件没有 "-- Dump completed" 文件尾时返回 ErrMissingFooter, 默认只记录警告
+// 文件以 DUMP INCOMPLETE 标记结尾或最后一条语句被截断时总是返回错误
+// 输入可以 Seek 时 (如 *os.File) 执行前先检查文件尾, 有问题时不执行任何语句;
+// 其他输入 (如管道) 执行完所有语句后才能发现不完整, 这时只回滚最后一次提交之后的 DML,
+// 已执行的 CREATE, DROP, TRUNCATE 等语句已隐式提交, WithRestoreBatchSize 已提交的批次也不会回滚
+func WithRequireFooter() SourceOption {
+	return func(o *sourceOption) {
+		o.requireFooter = true
+	}
+}
+
 // WithDebug 打印执行的 SQL
 func WithDebug() SourceOption {
 	return func(o *sourceOption) {
@@ -37,13 +63,33 @@ func WithDebug() SourceOption {
 	}
 }
 
+// WithTargetDatabase 恢复到指定数据库, 重写 USE, CREATE DATABASE 和 `db`.`table` 中的数据库名
+func WithTargetDatabase(name string) SourceOption {
+	return func(o *sourceOption) {
+		o.rename.database = name
+	}
+}
+
+// WithRenameTables 恢复时重命名表, key 为原表名, value 为新表名
+func WithRenameTables(tables map[string]string) SourceOption {
+	return func(o *sourceOption) {
+		o.rename.tables = tables
+	}
+}
+
+// execer *sql.DB 和 *sql.Conn 共有的执行方法
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 type dbWrapper struct {
-	DB     *sql.DB
+	DB     execer
 	debug  bool
 	dryRun bool
 }
 
-func newDBWrapper(db *sql.DB, dryRun, debug bool) *dbWrapper {
+func newDBWrapper(db execer, dryRun, debug bool) *dbWrapper {
 
 	return &dbWrapper{
 		DB:     db,
@@ -60,7 +106,7 @@ func (db *dbWrapper) Exec(query string, args ...interface{}) (sql.Result, error)
 	if db.dryRun {
 		return nil, nil
 	}
-	return db.DB.Exec(query, args...)
+	return db.DB.ExecContext(context.Background(), query, args...)
 }
 
 // Source 加载
@@ -77,39 +123,69 @@ func Source(dsn string, reader io.Reader, opts ...SourceOption) error {
 	}()
 
 	var err error
-	var db *sql.DB
 	var o sourceOption
 	for _, opt := range opts {
 		opt(&o)
 	}
+	tracker := newRestoreTracker(&o, start)
+	defer tracker.finish()
 
 	dbName, err := GetDBNameFromDSN(dsn)
 	if err != nil {
 		log.Printf("[error] %v\n", err)
 		return err
 	}
+	if o.rename.database != "" {
+		dbName = o.rename.database
+	}
+
+	// 执行前检查文件尾, DDL 会隐式提交, 执行后才发现不完整时已无法回滚
+	if rs, ok := reader.(io.ReadSeeker); ok {
+		err = checkSourceTail(rs, o.requireFooter)
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+	}
 
 	// Open database
-	db, err = sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", dsn)
 	if err != nil {
 		log.Printf("[error] %v\n", err)
 		return err
 	}
 	defer db.Close()
+	return source(context.Background(), db, dbName, reader, &o, tracker)
+}
+
+// source 在 db 的一个连接上依次执行 reader 中的语句
+// USE, SET autocommit=0, 每批的 COMMIT 和数据语句必须在同一个连接上, 否则连接池中的其他连接不在目标数据库, 也不在同一个事务中
+// nolint: gocyclo
+func source(ctx context.Context, db *sql.DB, dbName string, reader io.Reader, o *sourceOption, tracker *restoreTracker) error {
+	// 设置超时时间1小时
+	db.SetConnMaxLifetime(time.Hour)
+
+	var conn execer = db
+	if !o.dryRun {
+		c, err := db.Conn(ctx)
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+		defer c.Close()
+		conn = c
+	}
 
 	// DB Wrapper
-	dbWrapper := newDBWrapper(db, o.dryRun, o.debug)
+	dbWrapper := newDBWrapper(conn, o.dryRun, o.debug)
 
 	// Use database
-	_, err = dbWrapper.Exec(fmt.Sprintf("USE %s;", dbName))
+	_, err := dbWrapper.Exec(fmt.Sprintf("USE %s;", QuoteIdentifier(dbName)))
 	if err != nil {
 		log.Printf("[error] %v\n", err)
 		return err
 	}
 
-	// 设置超时时间1小时
-	db.SetConnMaxLifetime(3600)
-
 	// 一句一句执行
 	r := bufio.NewReader(reader)
 	// 关闭事务
@@ -119,33 +195,81 @@ func Source(dsn string, reader io.Reader, opts ...SourceOption) error {
 		return err
 	}
 
+	limiter := newRateLimiter(o.rateLimit)
+	batch := &batchCommitter{db: dbWrapper, size: o.batchSize}
+
+	var loader *loadDataLoader
+	if o.loadData && !o.dryRun {
+		loader = newLoadDataLoader(conn, dbName, o.debug)
+	}
+
+	// 最后一条语句之后的内容, 用于检查文件尾
+	var tail string
+	// 合并 INSERT 时读到的另一个表的 INSERT, 已经过滤和重写, 作为下一条语句开始新的合并
+	var carried string
 	for {
-		line, err := r.ReadString(';')
-		if err != nil {
-			if err == io.EOF {
-				break
+		var ssql string
+		if carried != "" {
+			ssql, carried = carried, ""
+		} else {
+			line, err := readStatement(r)
+			if err != nil {
+				if err == io.EOF {
+					tail += line
+					break
+				}
+				log.Printf("[error] %v\n", err)
+				return err
 			}
-			log.Printf("[error] %v\n", err)
-			return err
-		}
 
-		ssql := string(line)
+			ssql = string(line)
+			tracker.read(ssql)
+			if o.filter.skip(ssql) {
+				tracker.skipped()
+				continue
+			}
 
-		// 删除末尾的换行符
-		ssql = trim(ssql)
-		if err != nil {
-			log.Printf("[error] [trim] %v\n", err)
-			return err
+			// 删除末尾的换行符
+			ssql = o.rename.rewrite(trim(ssql))
 		}
 
+		if loader != nil {
+			ok, err := loader.add(ssql)
+			if err != nil {
+				log.Printf("[error] %v\n", err)
+				return err
+			}
+			if ok {
+				limiter.wait(1)
+				tracker.executed(ssql)
+				continue
+			}
+			// 执行其他语句前先完成 LOAD DATA
+			err = loader.flush()
+			if err != nil {
+				log.Printf("[error] %v\n", err)
+				return err
+			}
+		}
+
+		// 合并 INSERT 时读到的下一条非 INSERT 语句, 在合并后的 INSERT 之后执行
+		var next string
+		executed := []string{ssql}
 		// 如果 INSERT 开始, 并且 mergeInsert 为 true, 则合并 INSERT
-		if o.mergeInsert > 1 && strings.HasPrefix(ssql, "INSERT INTO") {
+		// 只合并表和列相同的 INSERT, 没有注释或 CREATE 分隔的相邻表 (WithCompact, WithSchemaFirst 等) 不会合并到一起
+		var prefix string
+		mergeable := o.mergeInsert > 1 && strings.HasPrefix(ssql, "INSERT INTO")
+		if mergeable {
+			prefix, mergeable = insertValuesPrefix(ssql)
+		}
+		if mergeable {
 			var insertSQLs []string
 			insertSQLs = append(insertSQLs, ssql)
 			for i := 0; i < o.mergeInsert-1; i++ {
-				line, err := r.ReadString(';')
+				line, err := readStatement(r)
 				if err != nil {
 					if err == io.EOF {
+						tail += line
 						break
 					}
 					log.Printf("[error] %v\n", err)
@@ -153,18 +277,29 @@ func Source(dsn string, reader io.Reader, opts ...SourceOption) error {
 				}
 
 				ssql2 := string(line)
-				ssql2 = trim(ssql2)
+				tracker.read(ssql2)
+				if o.filter.skip(ssql2) {
+					tracker.skipped()
+					continue
+				}
+				ssql2 = o.rename.rewrite(trim(ssql2))
 				if err != nil {
 					log.Printf("[error] [trim] %v\n", err)
 					return err
 				}
 				if strings.HasPrefix(ssql2, "INSERT INTO") {
-					insertSQLs = append(insertSQLs, ssql2)
-					continue
+					if prefix2, ok := insertValuesPrefix(ssql2); ok && prefix2 == prefix {
+						insertSQLs = append(insertSQLs, ssql2)
+						continue
+					}
+					carried = ssql2
+					break
 				}
 
+				next = ssql2
 				break
 			}
+			executed = insertSQLs
 			// 合并 INSERT
 			ssql, err = mergeInsert(insertSQLs)
 			if err != nil {
@@ -173,11 +308,51 @@ func Source(dsn string, reader io.Reader, opts ...SourceOption) error {
 			}
 		}
 
+		limiter.wait(len(executed))
 		_, err = dbWrapper.Exec(ssql)
 		if err != nil {
 			log.Printf("[error] %s %v\n", ssql, err)
 			return err
 		}
+		for _, stmt := range executed {
+			tracker.executed(stmt)
+		}
+		if next != "" {
+			limiter.wait(1)
+			_, err = dbWrapper.Exec(next)
+			if err != nil {
+				log.Printf("[error] %s %v\n", next, err)
+				return err
+			}
+			tracker.executed(next)
+			executed = append(executed, next)
+		}
+		err = batch.executed(len(executed))
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+	}
+
+	if loader != nil {
+		err = loader.flush()
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+	}
+
+	// 不完整的导出文件回滚未提交的 DML, 已隐式提交的 DDL 和已提交的批次无法回滚
+	switch problem := tailProblem(tail); {
+	case problem == ErrMissingFooter && !o.requireFooter:
+		log.Printf("[warn] [source] %v, the dump may be incomplete\n", problem)
+	case problem != nil:
+		log.Printf("[error] %v\n", problem)
+		_, err = dbWrapper.Exec("ROLLBACK;")
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+		}
+		return problem
 	}
 
 	// 提交事务
@@ -218,12 +393,11 @@ func mergeInsert(insertSQLs []string) (string, error) {
 			builder.WriteString(",")
 		}
 
-		valuesIdx := strings.Index(insertSQL, "VALUES")
-		if valuesIdx == -1 {
+		prefix, ok := insertValuesPrefix(insertSQL)
+		if !ok {
 			return "", errors.New("invalid SQL: missing VALUES keyword")
 		}
-		sqln := insertSQL[valuesIdx:]
-		sqln = strings.TrimPrefix(sqln, "VALUES")
+		sqln := insertSQL[len(prefix):]
 		sqln = strings.TrimSuffix(sqln, ";")
 		builder.WriteString(sqln)
 
@@ -233,6 +407,70 @@ func mergeInsert(insertSQLs []string) (string, error) {
 	return builder.String(), nil
 }
 
+// sourceTailSize 执行前检查的文件尾大小, 文件尾注释和 incompleteMarker 都在最后几行
+const sourceTailSize = 64 << 10
+
+// checkSourceTail 读取文件最后 sourceTailSize 字节, 有 incompleteMarker 时返回 ErrIncomplete,
+// requireFooter 时没有文件尾返回 ErrMissingFooter, 检查后回到原来的位置
+func checkSourceTail(rs io.ReadSeeker, requireFooter bool) error {
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	_, err = rs.Seek(max(start, end-sourceTailSize), io.SeekStart)
+	if err != nil {
+		return err
+	}
+	tail, err := io.ReadAll(rs)
+	if err != nil {
+		return err
+	}
+	_, err = rs.Seek(start, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	switch {
+	case strings.Contains(string(tail), "\n"+incompleteMarker):
+		return ErrIncomplete
+	case requireFooter && !hasFooter(string(tail)):
+		return ErrMissingFooter
+	}
+	return nil
+}
+
+// insertValuesPrefix 返回 INSERT 语句到 VALUES 关键字为止的部分, 即表名和列名, 用于判断能否合并
+// 跳过反引号中的名称, VALUES 之前有字符串时返回 false
+func insertValuesPrefix(stmt string) (string, bool) {
+	for i := 0; i < len(stmt); i++ {
+		switch c := stmt[i]; {
+		case c == '`':
+			i++
+			for i < len(stmt) && (stmt[i] != '`' || i+1 < len(stmt) && stmt[i+1] == '`') {
+				if stmt[i] == '`' {
+					i++
+				}
+				i++
+			}
+		case c == '\'' || c == '"':
+			return "", false
+		case isWordByte(c):
+			j := i
+			for j < len(stmt) && isWordByte(stmt[j]) {
+				j++
+			}
+			if strings.EqualFold(stmt[i:j], "VALUES") {
+				return stmt[:j], true
+			}
+			i = j - 1
+		}
+	}
+	return "", false
+}
+
 // 删除空白符换行符和注释
 func trim(s string) string {
 	s = strings.TrimLeft(s, "\n")
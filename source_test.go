@@ -1,6 +1,15 @@
 package mysqldump
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
 
 func Test_mergeInsert(t *testing.T) {
 	type args struct {
@@ -36,3 +45,183 @@ func Test_mergeInsert(t *testing.T) {
 		})
 	}
 }
+
+func Test_insertValuesPrefix(t *testing.T) {
+	tests := []struct {
+		stmt   string
+		want   string
+		wantOK bool
+	}{
+		{stmt: "INSERT INTO `t` VALUES (1);", want: "INSERT INTO `t` VALUES", wantOK: true},
+		{stmt: "INSERT INTO `t` (`a`,`values`) VALUES (1,2);", want: "INSERT INTO `t` (`a`,`values`) VALUES", wantOK: true},
+		{stmt: "INSERT INTO `a``VALUES` VALUES ('x');", want: "INSERT INTO `a``VALUES` VALUES", wantOK: true},
+		{stmt: "INSERT INTO `t` SELECT 'VALUES';", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := insertValuesPrefix(tt.stmt)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("insertValuesPrefix(%q) = %q, %v, want %q, %v", tt.stmt, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSource_mergeInsertCompactSchemaFirst(t *testing.T) {
+	// 紧凑的先结构后数据的导出, 两个表的 INSERT 之间没有其他语句
+	o := newDumpOption([]DumpOption{WithCompact(), WithSchemaFirst()})
+	f := o.formatter
+	a := &TableMeta{Name: "a", CreateSQL: "CREATE TABLE `a` (`id` int)", Columns: []string{"id"}, DataTypes: []string{"INT"}}
+	b := &TableMeta{Name: "b", CreateSQL: "CREATE TABLE `b` (`id` int, `name` varchar(10))", Columns: []string{"id", "name"},
+		DataTypes: []string{"INT", "VARCHAR"}}
+	var sb strings.Builder
+	meta := &DumpMeta{Database: "test"}
+	_ = f.Header(&sb, meta)
+	_ = f.TableSchema(&sb, a)
+	_ = f.TableSchema(&sb, b)
+	for _, table := range []*TableMeta{a, b} {
+		_ = f.TableDataBegin(&sb, table)
+		for i := int64(1); i <= 2; i++ {
+			row := []interface{}{i}
+			if table == b {
+				row = append(row, []byte("x"))
+			}
+			_ = f.Row(&sb, table, row)
+		}
+		_ = f.TableDataEnd(&sb, table)
+	}
+	_ = f.Footer(&sb, meta)
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	err := Source("root:pass@tcp(127.0.0.1:1)/test", strings.NewReader(sb.String()), WithDryRun(), WithDebug(), WithMergeInsert(10))
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+	for _, want := range []string{
+		"INSERT INTO `a` VALUES (1), (2);",
+		"INSERT INTO `b` VALUES (1,'x'), (2,'x');",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("Source() did not execute %q, log:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestSource_checkTailBeforeExecute(t *testing.T) {
+	const body = "CREATE TABLE `a` (`id` int);\nINSERT INTO `a` VALUES (1);\n"
+	tests := []struct {
+		name  string
+		input string
+		opts  []SourceOption
+		want  error
+	}{
+		{name: "incomplete", input: body + incompleteMarker + ": canceled\n", want: ErrIncomplete},
+		{name: "missing footer", input: body, opts: []SourceOption{WithRequireFooter()}, want: ErrMissingFooter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+			err := Source("root:pass@tcp(127.0.0.1:1)/test", strings.NewReader(tt.input), append(tt.opts, WithDryRun(), WithDebug())...)
+			if err != tt.want {
+				t.Fatalf("Source() error = %v, want %v", err, tt.want)
+			}
+			// 可以 Seek 的输入执行前就返回错误, 不执行 CREATE TABLE
+			if strings.Contains(logs.String(), "CREATE TABLE") {
+				t.Errorf("Source() executed statements before checking the footer, log:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+func Test_checkSourceTail(t *testing.T) {
+	r := strings.NewReader("SELECT 1;\n-- Dump completed on 2024-01-02 03:04:06\n")
+	_, _ = r.Seek(3, io.SeekStart)
+	if err := checkSourceTail(r, true); err != nil {
+		t.Fatalf("checkSourceTail() error = %v", err)
+	}
+	// 检查后回到原来的位置
+	rest, _ := io.ReadAll(r)
+	if !strings.HasPrefix(string(rest), "ECT 1;") {
+		t.Errorf("checkSourceTail() left reader at %q", rest)
+	}
+}
+
+// sourceFake 在 fakeDB 上恢复 input, 返回执行的语句
+func sourceFake(t *testing.T, input string, opts ...SourceOption) ([]string, []int, error) {
+	db, f := newFakeDB(t)
+	var o sourceOption
+	for _, opt := range opts {
+		opt(&o)
+	}
+	dbName := "test"
+	if o.rename.database != "" {
+		dbName = o.rename.database
+	}
+	err := source(context.Background(), db, dbName, strings.NewReader(input), &o, newRestoreTracker(&o, time.Now()))
+	return f.executed(), f.executedConns(), err
+}
+
+func TestSource_targetDatabaseOneConnection(t *testing.T) {
+	input := "CREATE TABLE `a` (`id` int);\nINSERT INTO `a` VALUES (1);\nINSERT INTO `a` VALUES (2);\n" +
+		"-- Dump completed on 2024-01-02 03:04:06\n"
+	queries, conns, err := sourceFake(t, input, WithTargetDatabase("target"))
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+	if len(queries) == 0 || queries[0] != "USE `target`;" {
+		t.Fatalf("queries = %q, want USE `target` first", queries)
+	}
+	// 不带库名的语句与 USE 在同一个连接上执行
+	for i, conn := range conns {
+		if conn != conns[0] {
+			t.Errorf("%q ran on connection %d, USE ran on %d", queries[i], conn, conns[0])
+		}
+	}
+}
+
+func TestSource_batchCommitOneConnection(t *testing.T) {
+	input := "INSERT INTO `a` VALUES (1);\nINSERT INTO `a` VALUES (2);\nINSERT INTO `a` VALUES (3);\n" +
+		"-- Dump completed on 2024-01-02 03:04:06\n"
+	queries, conns, err := sourceFake(t, input, WithRestoreBatchSize(2))
+	if err != nil {
+		t.Fatalf("Source() error = %v", err)
+	}
+	want := []string{
+		"USE `test`;",
+		"SET autocommit=0;",
+		"INSERT INTO `a` VALUES (1);",
+		"INSERT INTO `a` VALUES (2);",
+		"COMMIT;",
+		"INSERT INTO `a` VALUES (3);",
+		"COMMIT;",
+		"SET autocommit=1;",
+	}
+	if strings.Join(queries, "\n") != strings.Join(want, "\n") {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+	// 关闭自动提交和每批的 COMMIT 与数据语句在同一个会话中
+	for i, conn := range conns {
+		if conn != conns[0] {
+			t.Errorf("%q ran on connection %d, want %d", queries[i], conn, conns[0])
+		}
+	}
+}
+
+func TestSource_incompleteStreamRollback(t *testing.T) {
+	// 不能 Seek 的输入执行后才发现没有文件尾, 回滚未提交的 DML
+	input := "CREATE TABLE `a` (`id` int);\nINSERT INTO `a` VALUES (1);\n"
+	queries, _, err := sourceFake(t, input, WithRequireFooter())
+	if err != ErrMissingFooter {
+		t.Fatalf("Source() error = %v, want %v", err, ErrMissingFooter)
+	}
+	if got := queries[len(queries)-1]; got != "ROLLBACK;" {
+		t.Errorf("last query = %q, want ROLLBACK;", got)
+	}
+	for _, q := range queries {
+		if q == "COMMIT;" {
+			t.Errorf("incomplete dump committed, queries = %q", queries)
+		}
+	}
+}
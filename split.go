@@ -0,0 +1,161 @@
+package mysqldump
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// splitGlobalFile 不属于某个表的语句, 如 CREATE DATABASE 和账号权限
+const splitGlobalFile = "global.sql"
+
+// SplitDump 将一个导出文件按表拆分到 dir, 每个表的结构写到 <表名>.schema.sql, 数据写到 <表名>.data.sql,
+// 不属于某个表的结构语句写到 global.sql. 第一个表之前的 SET, USE 等会话语句写到每个文件的开头,
+// 每个文件都可以单独用 Source 恢复. 表名中文件名不能使用的字符按 URL 编码
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func SplitDump(r io.Reader, dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	s := &dumpSplitter{dir: dir, created: make(map[string]bool)}
+	defer s.close()
+
+	br := bufio.NewReader(r)
+	// 是否已经遇到表的语句, 之前的会话语句作为每个文件的开头
+	started := false
+	for {
+		stmt, rerr := readStatement(br)
+		if rerr != nil && rerr != io.EOF {
+			return rerr
+		}
+		if strings.TrimSpace(stmt) != "" {
+			kind, table := classifyStatement(stmt)
+			switch {
+			case kind == stmtSession && !started:
+				s.prelude = append(s.prelude, stmt)
+			case kind == stmtSession && isUnlockTables(stmt):
+				// UNLOCK TABLES 与前面的 LOCK TABLES 在同一个文件
+				err = s.write(s.current, stmt)
+			case kind == stmtSession:
+				// 其他会话语句写到下一个有表语句的文件
+				s.pending = append(s.pending, stmt)
+			case table == "":
+				started = true
+				err = s.write(splitGlobalFile, stmt)
+			default:
+				started = true
+				suffix := ".schema.sql"
+				if kind == stmtData {
+					suffix = ".data.sql"
+				}
+				err = s.write(splitFileName(table)+suffix, stmt)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+	}
+	if len(s.pending) > 0 {
+		err = s.write(splitGlobalFile, "")
+		if err != nil {
+			return err
+		}
+	}
+	return s.close()
+}
+
+func isUnlockTables(stmt string) bool {
+	tokens := statementTokens(stmt)
+	return len(tokens) > 0 && tokens[0].isKeyword("UNLOCK")
+}
+
+// splitFileName 返回表名对应的文件名
+func splitFileName(table string) string {
+	return url.PathEscape(table)
+}
+
+// dumpSplitter 同时只打开一个文件, 表的语句不连续时以追加方式重新打开
+type dumpSplitter struct {
+	dir     string
+	prelude []string
+	pending []string
+	created map[string]bool
+	current string
+	file    *os.File
+	buf     *bufio.Writer
+}
+
+// write 将 pending 中的会话语句和 stmt 写到 name, name 为空时写到当前文件
+func (s *dumpSplitter) write(name string, stmt string) error {
+	if name == "" {
+		name = splitGlobalFile
+	}
+	if name != s.current || s.file == nil {
+		err := s.open(name)
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range s.pending {
+		_, err := s.buf.WriteString(p)
+		if err != nil {
+			return err
+		}
+	}
+	s.pending = s.pending[:0]
+	_, err := s.buf.WriteString(stmt)
+	return err
+}
+
+func (s *dumpSplitter) open(name string) error {
+	err := s.close()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, name)
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !s.created[name] {
+		flag |= os.O_TRUNC
+	}
+	s.file, err = os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return err
+	}
+	s.buf = bufio.NewWriter(s.file)
+	s.current = name
+	if !s.created[name] {
+		s.created[name] = true
+		for _, p := range s.prelude {
+			_, err = s.buf.WriteString(p)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// close 关闭当前文件
+func (s *dumpSplitter) close() error {
+	if s.file == nil {
+		return nil
+	}
+	_, err := s.buf.WriteString("\n")
+	if err == nil {
+		err = s.buf.Flush()
+	}
+	cerr := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+	return cerr
+}
@@ -0,0 +1,55 @@
+package mysqldump
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSplitDump(t *testing.T) {
+	dump := "-- header\nSET NAMES utf8mb4;\n" +
+		"/*!40000 DROP DATABASE IF EXISTS `db`*/;\n" +
+		"DROP TABLE IF EXISTS `t1`;\nCREATE TABLE `t1` (`id` int);\n" +
+		"LOCK TABLES `t1` WRITE;\nINSERT INTO `t1` VALUES (1);\nINSERT INTO `t1` VALUES (2);\nUNLOCK TABLES;\n" +
+		"/*!40101 SET @saved_cs_client = @@character_set_client */;\n" +
+		"CREATE TABLE `a/b` (`id` int);\nINSERT INTO `a/b` VALUES (3);\n" +
+		"SET FOREIGN_KEY_CHECKS=1;\n-- Dump completed\n"
+	dir := t.TempDir()
+	if err := SplitDump(strings.NewReader(dump), dir); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	want := []string{"a%2Fb.data.sql", "a%2Fb.schema.sql", "global.sql", "t1.data.sql", "t1.schema.sql"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	data := read("t1.data.sql")
+	if !strings.HasPrefix(data, "-- header\nSET NAMES utf8mb4;") || strings.Count(data, "INSERT") != 2 || !strings.Contains(data, "UNLOCK TABLES;") {
+		t.Errorf("t1.data.sql = %q", data)
+	}
+	if schema := read("a%2Fb.schema.sql"); !strings.Contains(schema, "@saved_cs_client") || strings.Contains(schema, "INSERT") {
+		t.Errorf("a%%2Fb.schema.sql = %q", schema)
+	}
+	if global := read("global.sql"); !strings.Contains(global, "DROP DATABASE") || !strings.Contains(global, "FOREIGN_KEY_CHECKS=1") {
+		t.Errorf("global.sql = %q", global)
+	}
+}
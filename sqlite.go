@@ -0,0 +1,218 @@
+package mysqldump
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sqliteFormatter 输出 SQLite 的 SQL, 全部语句在一个事务中执行
+type sqliteFormatter struct {
+	o *dumpOption
+}
+
+func (f *sqliteFormatter) Header(w io.Writer, meta *DumpMeta) error {
+	err := f.o.writeHeaderComment(w, meta, "MySQL Database Dump (SQLite dialect)")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "PRAGMA foreign_keys=OFF;\n"+
+		"BEGIN TRANSACTION;\n"+
+		f.o.spacing("\n\n"))
+	return err
+}
+
+// sqliteColumnType 返回 MySQL 列类型对应的 SQLite 类型亲和性
+func sqliteColumnType(col columnDef) string {
+	switch col.typ {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "year", "bool", "boolean":
+		return "INTEGER"
+	case "float", "double", "real":
+		return "REAL"
+	case "decimal", "numeric", "dec":
+		return "NUMERIC"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit":
+		return "BLOB"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteDefault 转换默认值, 无法转换时返回空
+func sqliteDefault(col columnDef) string {
+	v := col.defaultValue
+	upper := strings.ToUpper(v)
+	switch {
+	case v == "" || upper == "NULL":
+		return ""
+	case strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || strings.HasPrefix(upper, "NOW("):
+		return "CURRENT_TIMESTAMP"
+	case strings.HasPrefix(v, "'"):
+		s := unquoteString(v)
+		if sqliteColumnType(col) == "BLOB" {
+			return "X'" + hex.EncodeToString([]byte(s)) + "'"
+		}
+		return pgString(s)
+	case strings.HasPrefix(v, "b'") || strings.HasPrefix(v, "_") || strings.HasPrefix(v, "("):
+		return ""
+	default:
+		return v
+	}
+}
+
+// sqliteCreateTable 将 MySQL 的表定义转换为 SQLite 语句
+// 单列整数自增主键转换为 INTEGER PRIMARY KEY AUTOINCREMENT
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func sqliteCreateTable(def *tableDef) string {
+	var sb strings.Builder
+	table := pgQuote(def.name)
+
+	// 单列整数主键作为 rowid
+	rowid := ""
+	if len(def.primaryKey) == 1 {
+		for _, col := range def.columns {
+			if col.name == def.primaryKey[0] && sqliteColumnType(col) == "INTEGER" {
+				rowid = col.name
+			}
+		}
+	}
+
+	var lines []string
+	for _, col := range def.columns {
+		line := "  " + pgQuote(col.name) + " " + sqliteColumnType(col)
+		if col.name == rowid {
+			line += " PRIMARY KEY"
+			if col.autoInc {
+				line += " AUTOINCREMENT"
+			}
+		}
+		if col.notNull {
+			line += " NOT NULL"
+		}
+		if d := sqliteDefault(col); d != "" && !col.autoInc {
+			line += " DEFAULT " + d
+		}
+		lines = append(lines, line)
+	}
+	if len(def.primaryKey) > 0 && rowid == "" {
+		lines = append(lines, "  PRIMARY KEY ("+pgQuoteList(def.primaryKey)+")")
+	}
+	for _, fk := range def.foreignKeys {
+		line := fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s)", pgQuoteList(fk.columns), pgQuote(fk.refTable), pgQuoteList(fk.refColumns))
+		if fk.onDelete != "" {
+			line += " ON DELETE " + fk.onDelete
+		}
+		if fk.onUpdate != "" {
+			line += " ON UPDATE " + fk.onUpdate
+		}
+		lines = append(lines, line)
+	}
+	sb.WriteString("CREATE TABLE IF NOT EXISTS " + table + " (\n")
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n);\n")
+
+	for _, idx := range def.indexes {
+		if len(idx.columns) == 0 || (idx.kind != "" && idx.kind != "UNIQUE") {
+			continue
+		}
+		unique := ""
+		if idx.kind == "UNIQUE" {
+			unique = "UNIQUE "
+		}
+		// SQLite 的索引名在数据库内唯一, 加上表名前缀
+		name := pgQuote(def.name + "_" + idx.name)
+		sb.WriteString(fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s);\n", unique, name, table, pgQuoteList(idx.columns)))
+	}
+	return sb.String()
+}
+
+func (f *sqliteFormatter) TableSchema(w io.Writer, table *TableMeta) error {
+	if table.View || table.Sequence {
+		// 视图的查询语句是 MySQL 语法, 不转换
+		return writeUnconvertedView(w, table, f.o)
+	}
+	def, err := parseCreateTable(table.CreateSQL)
+	if err != nil {
+		return err
+	}
+	if f.o.isDropTable {
+		_, _ = fmt.Fprintf(w, "DROP TABLE IF EXISTS %s;\n", pgQuote(table.Name))
+	}
+	_, _ = io.WriteString(w, f.o.banner("Table structure for "+commentName(table.Name)))
+	_, err = io.WriteString(w, sqliteCreateTable(def)+"\n"+f.o.spacing("\n\n"))
+	return err
+}
+
+func (f *sqliteFormatter) TableDataBegin(w io.Writer, table *TableMeta) error {
+	_, err := io.WriteString(w, f.o.banner("Records of "+commentName(table.Name)))
+	if err == nil && f.o.isTruncateTable {
+		// SQLite 没有 TRUNCATE
+		_, err = fmt.Fprintf(w, "DELETE FROM %s;\n", pgQuote(table.Name))
+	}
+	return err
+}
+
+// sqliteValue 将列值转换为 SQLite 字面量
+func sqliteValue(col interface{}, dataType string) (string, error) {
+	value, null, err := textValue(col, dataType)
+	if err != nil || null {
+		return "NULL", err
+	}
+	if _, ok := col.(string); ok {
+		// ColumnTransform 返回的 string 不论列类型都作为字符串输出
+		return pgString(value), nil
+	}
+	switch strings.TrimSpace(strings.Replace(dataType, "UNSIGNED", "", -1)) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR", "FLOAT", "DOUBLE", "DECIMAL", "DEC", "BOOL", "BOOLEAN":
+		return value, nil
+	case "BIT", "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		// textValue 已转换为十六进制
+		return "X'" + value + "'", nil
+	}
+	return pgString(value), nil
+}
+
+func (f *sqliteFormatter) Row(w io.Writer, table *TableMeta, row []interface{}) error {
+	var sb strings.Builder
+	switch {
+	case f.o.incremental != nil:
+		sb.WriteString("INSERT OR REPLACE INTO ")
+	case f.o.ignoreInsert(table.Name):
+		sb.WriteString("INSERT OR IGNORE INTO ")
+	default:
+		sb.WriteString("INSERT INTO ")
+	}
+	sb.WriteString(pgQuote(table.Name))
+	if table.PartialColumns {
+		sb.WriteString(" (" + pgQuoteList(table.Columns) + ")")
+	}
+	sb.WriteString(" VALUES (")
+	for i, col := range row {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		value, err := sqliteValue(col, table.DataTypes[i])
+		if err != nil {
+			return err
+		}
+		sb.WriteString(value)
+	}
+	sb.WriteString(");\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (f *sqliteFormatter) TableDataEnd(w io.Writer, _ *TableMeta) error {
+	_, err := io.WriteString(w, f.o.spacing("\n\n"))
+	return err
+}
+
+func (f *sqliteFormatter) Footer(w io.Writer, meta *DumpMeta) error {
+	_, err := io.WriteString(w, "COMMIT;\n"+f.o.spacing("\n"))
+	if err != nil {
+		return err
+	}
+	return f.o.writeFooterComment(w, meta)
+}
@@ -0,0 +1,55 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_sqliteCreateTable(t *testing.T) {
+	def, err := parseCreateTable(testCreateTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE TABLE IF NOT EXISTS \"orders\" (\n" +
+		"  \"id\" INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,\n" +
+		"  \"user_id\" INTEGER NOT NULL,\n" +
+		"  \"status\" TEXT NOT NULL DEFAULT 'new',\n" +
+		"  \"amount\" NUMERIC DEFAULT '0.00',\n" +
+		"  \"balance\" INTEGER DEFAULT -1,\n" +
+		"  \"created_at\" TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  \"total\" NUMERIC,\n" +
+		"  FOREIGN KEY (\"user_id\") REFERENCES \"users\" (\"id\") ON DELETE CASCADE ON UPDATE SET NULL\n" +
+		");\n" +
+		"CREATE UNIQUE INDEX IF NOT EXISTS \"orders_uk_user\" ON \"orders\" (\"user_id\", \"status\");\n" +
+		"CREATE INDEX IF NOT EXISTS \"orders_idx_name\" ON \"orders\" (\"status\");\n"
+	if got := sqliteCreateTable(def); got != want {
+		t.Errorf("sqliteCreateTable() =\n%s\nwant\n%s", got, want)
+	}
+
+	// 复合主键
+	def, err = parseCreateTable("CREATE TABLE `t` (\n  `a` int NOT NULL,\n  `b` varchar(10) NOT NULL,\n  PRIMARY KEY (`a`,`b`)\n) ENGINE=InnoDB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "CREATE TABLE IF NOT EXISTS \"t\" (\n" +
+		"  \"a\" INTEGER NOT NULL,\n" +
+		"  \"b\" TEXT NOT NULL,\n" +
+		"  PRIMARY KEY (\"a\", \"b\")\n" +
+		");\n"
+	if got := sqliteCreateTable(def); got != want {
+		t.Errorf("sqliteCreateTable() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func Test_sqliteFormatter_Row(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"id", "name", "data"}, DataTypes: []string{"INT", "VARCHAR", "BLOB"}}
+	o := newDumpOption([]DumpOption{WithDialect(DialectSQLite), WithIgnoreInsertTable()})
+	var sb strings.Builder
+	if err := o.formatter.Row(&sb, table, []interface{}{int64(1), []byte("it's"), []byte{0x0a, 0xff}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "INSERT OR IGNORE INTO \"t\" VALUES (1,'it''s',X'0aff');\n"
+	if sb.String() != want {
+		t.Errorf("Row() = %q, want %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,194 @@
+package mysqldump
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// readStatement 从 r 中读取一条以 ; 结尾的语句, 忽略字符串, 标识符和注释中的 ;
+// 读到文件末尾时返回剩余内容和 io.EOF
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func readStatement(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	// 当前所在的引号, 0 表示不在引号中
+	var quote byte
+	// 当前所在的注释: '-' 单行注释, '*' 多行注释
+	var comment byte
+	var prev byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return b.String(), err
+		}
+		b.WriteByte(c)
+
+		switch {
+		case comment == '-':
+			if c == '\n' {
+				comment = 0
+			}
+		case comment == '*':
+			if prev == '*' && c == '/' {
+				comment = 0
+				c = 0
+			}
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				// 转义字符, 直接读取下一个字符
+				next, err := r.ReadByte()
+				if err != nil {
+					return b.String(), err
+				}
+				b.WriteByte(next)
+				c = 0
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '#':
+			comment = '-'
+		case prev == '-' && c == '-':
+			// -- 后需要空白符才是注释
+			next, err := r.Peek(1)
+			if err == nil && (next[0] == ' ' || next[0] == '\t' || next[0] == '\n' || next[0] == '\r') || err == io.EOF {
+				comment = '-'
+			}
+		case prev == '/' && c == '*':
+			comment = '*'
+			c = 0
+		case c == ';':
+			return b.String(), nil
+		}
+		prev = c
+	}
+}
+
+type sqlTokenKind int
+
+const (
+	tokenSpace sqlTokenKind = iota
+	tokenComment
+	// 关键字, 未加引号的标识符和数字
+	tokenWord
+	// `标识符`
+	tokenIdent
+	// '字符串' 或 "字符串"
+	tokenString
+	tokenPunct
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// tokenizeSQL 将语句切分为 token, 所有 token 的 text 拼接后等于原语句
+// 禁止 golangci-lint 检查
+// nolint: gocyclo
+func tokenizeSQL(s string) []sqlToken {
+	var tokens []sqlToken
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		start := i
+		kind := tokenPunct
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			kind = tokenSpace
+			for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+				i++
+			}
+		case c == '#' || (c == '-' && strings.HasPrefix(s[i:], "-- ")) || (c == '-' && strings.HasPrefix(s[i:], "--\n")):
+			kind = tokenComment
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			kind = tokenComment
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end + 4
+			}
+		case c == '\'' || c == '"' || c == '`':
+			kind = tokenString
+			if c == '`' {
+				kind = tokenIdent
+			}
+			i++
+			for i < len(s) {
+				if s[i] == '\\' && c != '`' {
+					i += 2
+					continue
+				}
+				if s[i] == c {
+					// 连续两个引号表示引号本身
+					if i+1 < len(s) && s[i+1] == c {
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			if i > len(s) {
+				i = len(s)
+			}
+		case isWordByte(c):
+			kind = tokenWord
+			for i < len(s) && isWordByte(s[i]) {
+				i++
+			}
+		default:
+			i++
+		}
+		tokens = append(tokens, sqlToken{kind: kind, text: s[start:i]})
+	}
+	return tokens
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// identName 返回标识符 token 的名称, 不是标识符时返回 false
+func (t sqlToken) identName() (string, bool) {
+	switch t.kind {
+	case tokenIdent:
+		return strings.ReplaceAll(t.text[1:len(t.text)-1], "``", "`"), true
+	case tokenWord:
+		return t.text, true
+	}
+	return "", false
+}
+
+func (t sqlToken) isKeyword(keywords ...string) bool {
+	if t.kind != tokenWord {
+		return false
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(t.text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// statementText 去掉语句前面的空白和注释以及结尾的 ;, /*!NNNNN */ 版本注释保留
+func statementText(stmt string) string {
+	tokens := tokenizeSQL(stmt)
+	i := 0
+	for i < len(tokens) && (tokens[i].kind == tokenSpace || tokens[i].kind == tokenComment && !strings.HasPrefix(tokens[i].text, "/*!")) {
+		i++
+	}
+	var sb strings.Builder
+	for _, t := range tokens[i:] {
+		sb.WriteString(t.text)
+	}
+	return strings.TrimSuffix(strings.TrimSpace(sb.String()), ";")
+}
@@ -0,0 +1,23 @@
+package mysqldump
+
+import (
+	"io"
+)
+
+// DumpReader 返回导出内容的流, 导出在后台进行, 读取的速度决定导出的速度, 可以直接作为 HTTP 响应或上传的 Body
+// 导出失败时 Read 返回导出的错误, 提前 Close 时导出因写入失败而停止
+// DSN 不能用于导出时 (ValidateDSN) 直接返回错误, 连接等其他错误在读取时返回
+func DumpReader(dsn string, opts ...DumpOption) (io.ReadCloser, error) {
+	err := ValidateDSN(dsn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	// 不修改调用者的 opts
+	opts = append(opts[:len(opts):len(opts)], WithWriter(pw))
+	go func() {
+		// err 为 nil 时读取返回 io.EOF
+		_ = pw.CloseWithError(Dump(dsn, opts...))
+	}()
+	return pr, nil
+}
@@ -0,0 +1,26 @@
+package mysqldump
+
+import (
+	"io"
+	"testing"
+)
+
+func TestDumpReader(t *testing.T) {
+	if _, err := DumpReader("bad dsn"); err == nil {
+		t.Error("DumpReader() want error for invalid dsn")
+	}
+
+	// 连接失败的错误在读取时返回
+	r, err := DumpReader("root:pass@tcp(127.0.0.1:1)/test?timeout=1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	buf := make([]byte, 1024)
+	for err == nil {
+		_, err = r.Read(buf)
+	}
+	if err == io.EOF {
+		t.Error("Read() want dump error, got EOF")
+	}
+}
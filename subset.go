@@ -0,0 +1,171 @@
+package mysqldump
+
+import (
+	"sort"
+	"strings"
+)
+
+// foreignKey 外键
+type foreignKey struct {
+	name       string
+	table      string
+	columns    []string
+	refTable   string
+	refColumns []string
+	// ON DELETE 和 ON UPDATE, 只有从 SHOW CREATE TABLE 解析时才有
+	onDelete string
+	onUpdate string
+	// 完整定义, 只有从 SHOW CREATE TABLE 解析时才有
+	definition string
+}
+
+// getForeignKeys 获取当前数据库中的所有外键
+func getForeignKeys(db queryer) ([]foreignKey, error) {
+	rows, err := db.Query("SELECT CONSTRAINT_NAME, TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME " +
+		"FROM information_schema.KEY_COLUMN_USAGE " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_SCHEMA = DATABASE() AND REFERENCED_TABLE_NAME IS NOT NULL " +
+		"ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fks []foreignKey
+	for rows.Next() {
+		var name, table, column, refTable, refColumn string
+		err = rows.Scan(&name, &table, &column, &refTable, &refColumn)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(fks); n > 0 && fks[n-1].name == name && fks[n-1].table == table {
+			fks[n-1].columns = append(fks[n-1].columns, column)
+			fks[n-1].refColumns = append(fks[n-1].refColumns, refColumn)
+			continue
+		}
+		fks = append(fks, foreignKey{
+			name:       name,
+			table:      table,
+			columns:    []string{column},
+			refTable:   refTable,
+			refColumns: []string{refColumn},
+		})
+	}
+	return fks, rows.Err()
+}
+
+// WithSubset 从 rootTable 中满足 where 的行开始, 沿外键导出关联的子表和父表数据
+// 与子集无关的表只导出表结构
+func WithSubset(rootTable, where string) DumpOption {
+	return func(option *dumpOption) {
+		option.subsetTable = rootTable
+		option.subsetWhere = where
+	}
+}
+
+// subsetBuilder 根据外键计算每个表的 WHERE 条件
+type subsetBuilder struct {
+	fks []foreignKey
+	// 子表集合, 即 root 及沿外键向下可达的表
+	down map[string]bool
+	// 已计算的条件
+	conds map[string]string
+	// 正在计算的表, 用于避免外键环
+	visiting map[string]bool
+}
+
+// buildSubsetWheres 返回子集中每个表的 WHERE 条件, 不在返回值中的表不导出数据
+func buildSubsetWheres(root, where string, fks []foreignKey) map[string]string {
+	b := &subsetBuilder{
+		fks:      fks,
+		down:     map[string]bool{root: true},
+		conds:    map[string]string{root: where},
+		visiting: make(map[string]bool),
+	}
+
+	// 向下: 引用了子集中的表的子表
+	queue := []string{root}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, fk := range fks {
+			if fk.refTable == t && !b.down[fk.table] {
+				b.down[fk.table] = true
+				queue = append(queue, fk.table)
+			}
+		}
+	}
+
+	// 向上: 子集中的表引用的父表
+	up := make(map[string]bool)
+	queue = queue[:0]
+	for t := range b.down {
+		queue = append(queue, t)
+	}
+	sort.Strings(queue)
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, fk := range fks {
+			if fk.table == t && !b.down[fk.refTable] && !up[fk.refTable] {
+				up[fk.refTable] = true
+				queue = append(queue, fk.refTable)
+			}
+		}
+	}
+
+	wheres := make(map[string]string)
+	for t := range b.down {
+		wheres[t] = b.cond(t)
+	}
+	for t := range up {
+		wheres[t] = b.cond(t)
+	}
+	return wheres
+}
+
+func (b *subsetBuilder) cond(table string) string {
+	if c, ok := b.conds[table]; ok {
+		return c
+	}
+	if b.visiting[table] {
+		return ""
+	}
+	b.visiting[table] = true
+	defer delete(b.visiting, table)
+
+	var parts []string
+	for _, fk := range b.fks {
+		if fk.table == fk.refTable {
+			continue
+		}
+		if b.down[table] {
+			// 子表: 外键指向子集中的父表行
+			if fk.table != table || !b.down[fk.refTable] {
+				continue
+			}
+			if c := b.cond(fk.refTable); c != "" {
+				parts = append(parts, subsetIn(fk.columns, fk.refColumns, fk.refTable, c))
+			}
+		} else {
+			// 父表: 被子集中的行引用
+			if fk.refTable != table {
+				continue
+			}
+			if c := b.cond(fk.table); c != "" {
+				parts = append(parts, subsetIn(fk.refColumns, fk.columns, fk.table, c))
+			}
+		}
+	}
+
+	c := strings.Join(parts, " OR ")
+	if c != "" {
+		b.conds[table] = c
+	}
+	return c
+}
+
+// subsetIn 生成 (`a`,`b`) IN (SELECT `x`,`y` FROM `t` WHERE cond)
+func subsetIn(columns, selectColumns []string, table, cond string) string {
+	return "(" + quoteColumns(columns) + ") IN (SELECT " + quoteColumns(selectColumns) +
+		" FROM " + QuoteIdentifier(table) + " WHERE " + cond + ")"
+}
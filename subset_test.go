@@ -0,0 +1,35 @@
+package mysqldump
+
+import "testing"
+
+func Test_buildSubsetWheres(t *testing.T) {
+	fks := []foreignKey{
+		{name: "fk1", table: "orders", columns: []string{"customer_id"}, refTable: "customers", refColumns: []string{"id"}},
+		{name: "fk2", table: "order_items", columns: []string{"order_id"}, refTable: "orders", refColumns: []string{"id"}},
+		{name: "fk3", table: "order_items", columns: []string{"product_id"}, refTable: "products", refColumns: []string{"id"}},
+		{name: "fk4", table: "customers", columns: []string{"manager_id"}, refTable: "customers", refColumns: []string{"id"}},
+	}
+	got := buildSubsetWheres("customers", "id IN (1,2)", fks)
+	want := map[string]string{
+		"customers":   "id IN (1,2)",
+		"orders":      "(`customer_id`) IN (SELECT `id` FROM `customers` WHERE id IN (1,2))",
+		"order_items": "(`order_id`) IN (SELECT `id` FROM `orders` WHERE (`customer_id`) IN (SELECT `id` FROM `customers` WHERE id IN (1,2)))",
+		"products":    "(`id`) IN (SELECT `product_id` FROM `order_items` WHERE (`order_id`) IN (SELECT `id` FROM `orders` WHERE (`customer_id`) IN (SELECT `id` FROM `customers` WHERE id IN (1,2))))",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("buildSubsetWheres() = %v, want %v", got, want)
+	}
+	for table, cond := range want {
+		if got[table] != cond {
+			t.Errorf("buildSubsetWheres()[%s] = %s, want %s", table, got[table], cond)
+		}
+	}
+}
+
+func Test_subsetIn(t *testing.T) {
+	got := subsetIn([]string{"user id"}, []string{"a`b"}, "or.ders", "1=1")
+	want := "(`user id`) IN (SELECT `a``b` FROM `or.ders` WHERE 1=1)"
+	if got != want {
+		t.Errorf("subsetIn() = %q, want %q", got, want)
+	}
+}
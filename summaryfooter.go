@@ -0,0 +1,89 @@
+package mysqldump
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// summaryMarker WithSummaryFooter 输出的摘要行前缀, 后面是一行 JSON
+const summaryMarker = "-- Summary: "
+
+// ErrMissingSummaryFooter 导出文件中没有 WithSummaryFooter 输出的摘要
+var ErrMissingSummaryFooter = errors.New("missing dump summary")
+
+// SummaryFooter 文件尾的导出摘要, 由 ReadSummaryFooter 读取
+type SummaryFooter struct {
+	Database string `json:"database"`
+	// 导出的总行数
+	Rows int64 `json:"rows"`
+	// 每个表的行数, 字节数和 sha256, 与 Manifest 相同
+	Tables       []ManifestTable `json:"tables"`
+	FailedTables []FailedTable   `json:"failed_tables,omitempty"`
+}
+
+// WithSummaryFooter 在 "-- Dump completed on" 行之前输出一行 "-- Summary: {...}" 注释, 内容为 JSON 格式的 SummaryFooter
+// 校验工具用 ReadSummaryFooter 读取每个表的行数和 sha256, 不需要解析 "Cost Time" 等注释, 会同时计算每个表的 sha256
+func WithSummaryFooter() DumpOption {
+	return func(option *dumpOption) {
+		option.summaryFooter = true
+	}
+}
+
+// tableChecksums 是否计算每个表输出的 sha256
+func (o *dumpOption) tableChecksums() bool {
+	return o.manifest != "" || o.summaryFooter
+}
+
+// newSummaryFooter 根据导出结果生成摘要
+func newSummaryFooter(result *DumpResult) *SummaryFooter {
+	return &SummaryFooter{
+		Database:     result.Database,
+		Rows:         result.Rows(),
+		Tables:       newManifest(result, nil).Tables,
+		FailedTables: result.FailedTables,
+	}
+}
+
+// writeSummary 输出摘要行, 没有 WithSummaryFooter 或没有导出结果时不输出
+func (o *dumpOption) writeSummary(w io.Writer, meta *DumpMeta) error {
+	if !o.summaryFooter || meta.Result == nil {
+		return nil
+	}
+	// json.Marshal 转义字符串中的换行, 摘要总是一行
+	data, err := json.Marshal(newSummaryFooter(meta.Result))
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, summaryMarker+string(data)+"\n")
+	return err
+}
+
+// ReadSummaryFooter 从导出文件中读取 WithSummaryFooter 输出的摘要, 有多个时返回最后一个, 没有时返回 ErrMissingSummaryFooter
+func ReadSummaryFooter(r io.Reader) (*SummaryFooter, error) {
+	br := bufio.NewReader(r)
+	var summary *SummaryFooter
+	for {
+		line, err := br.ReadString('\n')
+		if strings.HasPrefix(line, summaryMarker) {
+			var s SummaryFooter
+			if jerr := json.Unmarshal([]byte(strings.TrimPrefix(line, summaryMarker)), &s); jerr != nil {
+				return nil, fmt.Errorf("dump summary: %v", jerr)
+			}
+			summary = &s
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if summary == nil {
+		return nil, ErrMissingSummaryFooter
+	}
+	return summary, nil
+}
@@ -0,0 +1,50 @@
+package mysqldump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithSummaryFooter(t *testing.T) {
+	end := time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC)
+	result := &DumpResult{
+		Database: "shop",
+		Tables: []TableResult{
+			{Name: "orders", Rows: 3, Bytes: 120, Checksum: "abc"},
+			{Name: "users\n", Rows: 2, Bytes: 80, Checksum: "def"},
+		},
+	}
+	meta := &DumpMeta{Database: "shop", StartTime: end.Add(-time.Second), EndTime: end, Result: result}
+	want := "-- Summary: {\"database\":\"shop\",\"rows\":5,\"tables\":[" +
+		"{\"name\":\"orders\",\"rows\":3,\"bytes\":120,\"duration\":0,\"checksum\":\"abc\"}," +
+		"{\"name\":\"users\\n\",\"rows\":2,\"bytes\":80,\"duration\":0,\"checksum\":\"def\"}]}\n" +
+		"-- Dump completed on 2024-01-02 03:04:06\n"
+
+	for _, opts := range [][]DumpOption{
+		{WithSummaryFooter(), WithFooterTemplate(nil)},
+		{WithSummaryFooter(), WithMySQLDumpCompat()},
+	} {
+		o := newDumpOption(opts)
+		var sb strings.Builder
+		if err := o.formatter.Footer(&sb, meta); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(sb.String(), want) {
+			t.Errorf("Footer() = %q, want suffix %q", sb.String(), want)
+		}
+
+		summary, err := ReadSummaryFooter(strings.NewReader("INSERT INTO `t` VALUES ('-- Summary: x');\n" + sb.String()))
+		if err != nil {
+			t.Fatalf("ReadSummaryFooter() error = %v", err)
+		}
+		if summary.Database != "shop" || summary.Rows != 5 || len(summary.Tables) != 2 || summary.Tables[1].Name != "users\n" {
+			t.Errorf("ReadSummaryFooter() = %+v", summary)
+		}
+	}
+
+	if _, err := ReadSummaryFooter(strings.NewReader("-- Dump completed on 2024-01-02 03:04:06\n")); !errors.Is(err, ErrMissingSummaryFooter) {
+		t.Errorf("ReadSummaryFooter() error = %v, want ErrMissingSummaryFooter", err)
+	}
+}
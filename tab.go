@@ -0,0 +1,200 @@
+package mysqldump
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// tabFormatter 与 mysqldump --tab 相同, 每个表的结构写到 <表名>.sql,
+// 数据写到 <表名>.txt, 格式为 LOAD DATA INFILE 的默认格式
+type tabFormatter struct {
+	dir string
+	sql *sqlFormatter
+}
+
+// WithTab 每个表的结构导出为 dir 下的 <表名>.sql, 数据导出为 <表名>.txt,
+// 数据文件可以用 LOAD DATA INFILE 直接导入, 见 SourceTab
+func WithTab(dir string) DumpOption {
+	return func(option *dumpOption) {
+		option.formatter = &tabFormatter{dir: dir, sql: &sqlFormatter{o: option}}
+		option.tableWriter = dirTableWriter(dir, ".txt")
+	}
+}
+
+func (f *tabFormatter) Header(io.Writer, *DumpMeta) error { return nil }
+
+func (f *tabFormatter) TableSchema(_ io.Writer, table *TableMeta) error {
+	err := os.MkdirAll(f.dir, 0o755)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(f.dir, table.Name+".sql"))
+	if err != nil {
+		return err
+	}
+	err = f.sql.TableSchema(file, table)
+	cerr := file.Close()
+	if err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (f *tabFormatter) TableDataBegin(io.Writer, *TableMeta) error { return nil }
+
+func (f *tabFormatter) Row(w io.Writer, table *TableMeta, row []interface{}) error {
+	line := ""
+	for i, col := range row {
+		value, null, err := textValue(col, table.DataTypes[i])
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			line += "\t"
+		}
+		if null {
+			line += `\N`
+		} else {
+			line += escapeTabValue(value)
+		}
+	}
+	_, err := io.WriteString(w, line+"\n")
+	return err
+}
+
+func (f *tabFormatter) TableDataEnd(io.Writer, *TableMeta) error { return nil }
+
+func (f *tabFormatter) Footer(io.Writer, *DumpMeta) error { return nil }
+
+// tabEscaper LOAD DATA 默认 FIELDS ESCAPED BY '\\' 的转义
+var tabEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`, "\x00", `\0`)
+
+// escapeTabValue 转义 LOAD DATA 数据文件中的值
+func escapeTabValue(value string) string {
+	return tabEscaper.Replace(value)
+}
+
+// SourceTab 导入 WithTab 导出的目录, 先执行每个 <表名>.sql, 再用
+// LOAD DATA LOCAL INFILE 导入 <表名>.txt, 需要服务端开启 local_infile
+func SourceTab(dsn string, dir string, opts ...SourceOption) error {
+	var o sourceOption
+	for _, opt := range opts {
+		opt(&o)
+	}
+	tracker := newRestoreTracker(&o, time.Now())
+	defer tracker.finish()
+
+	schemas, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		log.Printf("[error] %v\n", err)
+		return err
+	}
+	sort.Strings(schemas)
+
+	if o.rename.database != "" {
+		// 每个连接都使用目标数据库
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+		cfg.DBName = o.rename.database
+		dsn = cfg.FormatDSN()
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		log.Printf("[error] %v\n", err)
+		return err
+	}
+	defer db.Close()
+	dbWrapper := newDBWrapper(db, o.dryRun, o.debug)
+
+	for _, schema := range schemas {
+		table := strings.TrimSuffix(filepath.Base(schema), ".sql")
+		if o.filter.skipTable(table) {
+			continue
+		}
+		err = sourceTabSchema(dbWrapper, schema, &o, tracker)
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+
+		data := filepath.Join(dir, table+".txt")
+		if _, err := os.Stat(data); os.IsNotExist(err) || o.filter.schemaOnly {
+			continue
+		}
+		rows, err := loadDataFile(dbWrapper, o.rename.table(table), data)
+		if err != nil {
+			log.Printf("[error] %v\n", err)
+			return err
+		}
+		tracker.add(o.rename.table(table), rows)
+	}
+	return nil
+}
+
+// sourceTabSchema 执行表结构文件中的语句
+func sourceTabSchema(db *dbWrapper, path string, o *sourceOption, tracker *restoreTracker) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	for {
+		stmt, err := readStatement(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		tracker.read(stmt)
+		if o.filter.skip(stmt) {
+			tracker.skipped()
+			continue
+		}
+		stmt = strings.TrimSpace(o.rename.rewrite(stmt))
+		if stmt == "" {
+			continue
+		}
+		_, err = db.Exec(stmt)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		tracker.executed(stmt)
+	}
+}
+
+// loadDataFile 通过 LOAD DATA LOCAL INFILE 导入数据文件, 返回导入的行数
+func loadDataFile(db *dbWrapper, table string, path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	name := "tab:" + path
+	mysql.RegisterReaderHandler(name, func() io.Reader { return file })
+	defer mysql.DeregisterReaderHandler(name)
+
+	result, err := db.Exec(fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s", strings.ReplaceAll(name, "'", "''"), QuoteIdentifier(table)))
+	if err != nil || result == nil {
+		// dry run 时没有结果
+		return 0, err
+	}
+	return result.RowsAffected()
+}
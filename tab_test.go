@@ -0,0 +1,31 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_tabFormatter_Row(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"id", "name", "note"}, DataTypes: []string{"INT", "VARCHAR", "TEXT"}}
+	tests := []struct {
+		name string
+		row  []interface{}
+		want string
+	}{
+		{name: "plain", row: []interface{}{int64(1), []byte("a"), []byte("b")}, want: "1\ta\tb\n"},
+		{name: "null", row: []interface{}{int64(2), nil, []byte("")}, want: "2\t\\N\t\n"},
+		{name: "escape", row: []interface{}{int64(3), []byte("a\tb"), []byte("c\\d\ne\x00")}, want: "3\ta\\tb\tc\\\\d\\ne\\0\n"},
+	}
+	f := &tabFormatter{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := f.Row(&sb, table, tt.row); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("Row() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
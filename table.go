@@ -0,0 +1,64 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log"
+	"time"
+)
+
+// DumpTable 导出一个表的结构和数据到 w, 不查询表列表, 不输出文件头尾
+// 默认只导出表结构, WithData 导出数据, WithNoSchema 只导出数据
+// 支持 WithDropTable, WithFormatter, WithOmitColumns, WithQualifiedNames 等选项, 表的筛选选项不生效
+func DumpTable(db *sql.DB, table string, w io.Writer, opts ...DumpOption) error {
+	o := newDumpOption(opts)
+	start := time.Now()
+	counter := &countWriter{w: w}
+	buf := o.newBuffer(counter)
+
+	views, err := getViews(db)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	o.views = map[string]bool{table: views[table]}
+	sequences, err := getSequences(db, getServerVersion(db))
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+	o.sequences = map[string]bool{table: sequences[table]}
+	if o.qualifiedNames {
+		var database sql.NullString
+		err = db.QueryRow("SELECT DATABASE()").Scan(&database)
+		if err != nil {
+			log.Printf("[error] %v \n", err)
+			return err
+		}
+		o.database = database.String
+	}
+
+	ctx, span := startSpan(context.Background(), o.tracer, "mysqldump.dump_table")
+	span.SetAttribute("db.sql.table", table)
+	result, err := dumpTable(ctx, db, table, buf, counter, false, o)
+	if err == nil {
+		err = buf.Flush()
+	}
+	endSpan(span, err)
+	if err != nil {
+		log.Printf("[error] %v \n", err)
+		return err
+	}
+
+	if o.result != nil {
+		result.Bytes = counter.n
+		*o.result = DumpResult{
+			StartTime: start,
+			EndTime:   time.Now(),
+			Bytes:     counter.n,
+			Tables:    []TableResult{result},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDumpTable(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	tests := []struct {
+		name    string
+		opts    []DumpOption
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "schema only",
+			want:    []string{"CREATE TABLE IF NOT EXISTS `a`"},
+			notWant: []string{"INSERT INTO", "Dump completed"},
+		},
+		{
+			name: "data",
+			opts: []DumpOption{WithData(), WithDropTable()},
+			want: []string{"DROP TABLE IF EXISTS `a`;", "CREATE TABLE IF NOT EXISTS `a`", "INSERT INTO `a` VALUES (1);"},
+		},
+		{
+			name:    "no schema",
+			opts:    []DumpOption{WithData(), WithNoSchema()},
+			want:    []string{"INSERT INTO `a` VALUES (2);"},
+			notWant: []string{"CREATE TABLE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			result := &DumpResult{}
+			if err := DumpTable(db, "a", &sb, append(tt.opts, WithResult(result))...); err != nil {
+				t.Fatalf("DumpTable() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(sb.String(), want) {
+					t.Errorf("DumpTable() output does not contain %q:\n%s", want, sb.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(sb.String(), notWant) {
+					t.Errorf("DumpTable() output contains %q:\n%s", notWant, sb.String())
+				}
+			}
+			if len(result.Tables) != 1 || result.Tables[0].Name != "a" || result.Bytes != int64(sb.Len()) {
+				t.Errorf("result = %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package mysqldump
+
+// TableOption 单个表的导出设置, 用于 WithTableOptions
+type TableOption func(*tableOption)
+
+type tableOption struct {
+	// 追加的查询条件, 与 WithSubset 和增量导出的条件以 AND 连接
+	where string
+	// 分块大小, chunkSet 为 false 时使用 WithChunkSize
+	chunkSize int
+	chunkSet  bool
+	// INSERT IGNORE, ignoreSet 为 false 时使用 WithIgnoreInsertTable
+	ignoreInsert bool
+	ignoreSet    bool
+	noData       bool
+}
+
+// WithTableOptions 单独设置一个表的查询条件, 分块大小, INSERT 方式和是否导出数据, 其他表使用全局设置
+//
+//	mysqldump.WithTableOptions("logs", mysqldump.TableWhere("created_at >= '2024-01-01'"), mysqldump.TableChunkSize(10000))
+func WithTableOptions(table string, opts ...TableOption) DumpOption {
+	return func(option *dumpOption) {
+		if option.tableOptions == nil {
+			option.tableOptions = make(map[string]*tableOption)
+		}
+		to := option.tableOptions[table]
+		if to == nil {
+			to = &tableOption{}
+			option.tableOptions[table] = to
+		}
+		for _, opt := range opts {
+			opt(to)
+		}
+	}
+}
+
+// TableWhere 只导出满足条件的行, 多次设置时以 AND 连接
+func TableWhere(where string) TableOption {
+	return func(option *tableOption) {
+		if option.where != "" {
+			where = "(" + option.where + ") AND (" + where + ")"
+		}
+		option.where = where
+	}
+}
+
+// TableChunkSize 表的分块大小, 为 0 时不分块
+func TableChunkSize(rows int) TableOption {
+	return func(option *tableOption) {
+		option.chunkSize = rows
+		option.chunkSet = true
+	}
+}
+
+// TableInsertIgnore 表是否使用 INSERT IGNORE
+func TableInsertIgnore(ignore bool) TableOption {
+	return func(option *tableOption) {
+		option.ignoreInsert = ignore
+		option.ignoreSet = true
+	}
+}
+
+// TableNoData 只导出表结构
+func TableNoData() TableOption {
+	return func(option *tableOption) {
+		option.noData = true
+	}
+}
+
+// tableWhere 返回表单独设置的查询条件
+func (o *dumpOption) tableWhere(table string) string {
+	if to := o.tableOptions[table]; to != nil {
+		return to.where
+	}
+	return ""
+}
+
+// tableChunkSize 返回表的分块大小
+func (o *dumpOption) tableChunkSize(table string) int {
+	if to := o.tableOptions[table]; to != nil && to.chunkSet {
+		return to.chunkSize
+	}
+	return o.chunkSize
+}
+
+// ignoreInsert 表是否使用 INSERT IGNORE
+func (o *dumpOption) ignoreInsert(table string) bool {
+	if to := o.tableOptions[table]; to != nil && to.ignoreSet {
+		return to.ignoreInsert
+	}
+	return o.isIgnoreInsert
+}
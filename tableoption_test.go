@@ -0,0 +1,44 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithTableOptions(t *testing.T) {
+	o := newDumpOption([]DumpOption{
+		WithChunkSize(1000),
+		WithIgnoreInsertTable(),
+		WithTableOptions("logs", TableWhere("id > 10"), TableChunkSize(0), TableInsertIgnore(false)),
+		WithTableOptions("logs", TableWhere("level = 'error'")),
+		WithTableOptions("big", TableChunkSize(50000), TableNoData()),
+	})
+
+	conds := tableConds("logs", o)
+	if want := "((id > 10) AND (level = 'error'))"; len(conds) != 1 || conds[0] != want {
+		t.Errorf("conds = %v, want [%s]", conds, want)
+	}
+	if conds = tableConds("users", o); len(conds) != 0 {
+		t.Errorf("users conds = %v", conds)
+	}
+
+	for table, want := range map[string]int{"logs": 0, "big": 50000, "users": 1000} {
+		if got := o.tableChunkSize(table); got != want {
+			t.Errorf("tableChunkSize(%s) = %d, want %d", table, got, want)
+		}
+	}
+	if !o.tableOptions["big"].noData || o.tableOptions["logs"].noData {
+		t.Errorf("noData big = %v, logs = %v", o.tableOptions["big"].noData, o.tableOptions["logs"].noData)
+	}
+
+	for table, want := range map[string]string{"logs": "INSERT INTO `logs` VALUES (1);\n", "users": "INSERT IGNORE INTO `users` VALUES (1);\n"} {
+		var sb strings.Builder
+		err := o.formatter.Row(&sb, &TableMeta{Name: table, Columns: []string{"id"}, DataTypes: []string{"INT"}}, []interface{}{int64(1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sb.String() != want {
+			t.Errorf("Row(%s) = %q, want %q", table, sb.String(), want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package mysqldump
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// WithHeaderTemplate 使用模板生成文件头注释, 模板数据为 *DumpMeta, tmpl 为 nil 时不输出文件头注释
+// 只替换注释部分, 方言需要的 SET 等语句仍会输出
+// RestoreIncrements 从文件头的 "-- Start Time: " 行读取导出时间, 需要时应在模板中保留
+//
+//	tmpl := template.Must(template.New("header").Parse("-- {{.Database}} on MySQL {{.ServerVersion}}\n"))
+func WithHeaderTemplate(tmpl *template.Template) DumpOption {
+	return func(option *dumpOption) {
+		option.headerTemplate = tmpl
+		option.customHeader = true
+	}
+}
+
+// WithFooterTemplate 使用模板生成文件尾注释, 模板数据为 *DumpMeta, tmpl 为 nil 时不输出文件尾注释
+// 最后的 "-- Dump completed on" 行总是输出, 用于恢复和 Validate 检查导出是否完整
+func WithFooterTemplate(tmpl *template.Template) DumpOption {
+	return func(option *dumpOption) {
+		option.footerTemplate = tmpl
+		option.customFooter = true
+	}
+}
+
+// writeHeaderComment 输出文件头注释, title 为默认注释的标题
+func (o *dumpOption) writeHeaderComment(w io.Writer, meta *DumpMeta, title string) error {
+	if o.customHeader {
+		if o.headerTemplate == nil {
+			return nil
+		}
+		return o.headerTemplate.Execute(w, meta)
+	}
+	if o.compact {
+		return nil
+	}
+	_, err := io.WriteString(w, "-- ----------------------------\n"+
+		"-- "+title+"\n"+
+		"-- Start Time: "+meta.StartTime.Format("2006-01-02 15:04:05")+"\n"+
+		o.provenanceComment(meta)+
+		serverVariablesComment(meta.ServerVariables)+
+		"-- ----------------------------\n")
+	return err
+}
+
+// writeFooterComment 输出文件尾注释, 摘要和 "-- Dump completed on" 行
+func (o *dumpOption) writeFooterComment(w io.Writer, meta *DumpMeta) error {
+	var err error
+	switch {
+	case o.customFooter:
+		if o.footerTemplate != nil {
+			err = o.footerTemplate.Execute(w, meta)
+		}
+	case !o.compact:
+		_, err = io.WriteString(w, "-- ----------------------------\n"+
+			"-- Dumped by mysqldump\n"+
+			"-- Cost Time: "+meta.EndTime.Sub(meta.StartTime).String()+"\n"+
+			"-- ----------------------------\n")
+	}
+	if err != nil {
+		return err
+	}
+	return o.writeDumpCompleted(w, meta)
+}
+
+// writeDumpCompleted 输出与官方 mysqldump 相同的完成标记, WithSummaryFooter 时先输出摘要
+func (o *dumpOption) writeDumpCompleted(w io.Writer, meta *DumpMeta) error {
+	err := o.writeSummary(w, meta)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, dumpCompletedMarker+" on "+meta.EndTime.Format("2006-01-02 15:04:05")+"\n")
+	return err
+}
+
+// optionNames 返回使用的选项, 用于文件头
+func (o *dumpOption) optionNames() []string {
+	var names []string
+	add := func(enabled bool, name string) {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	add(o.isData, "data")
+	add(o.noSchema, "no-schema")
+	add(o.schemaFirst, "schema-first")
+	add(o.mysqldumpCompat, "mysqldump-compat")
+	add(o.compact, "compact")
+	add(o.noHexBlob, "skip-hex-blob")
+	add(o.jsonCast, "json-cast")
+	add(o.compactJSON, "compact-json")
+	if o.timeZone != nil {
+		add(true, "time-zone="+timeZoneName(o.timeZone))
+	}
+	add(o.isDropDatabase, "drop-database")
+	add(o.isDropTable, "drop-table")
+	add(o.isTruncateTable, "truncate-table")
+	add(o.isDisableKeys, "disable-keys")
+	add(o.isAddLocks, "add-locks")
+	add(o.skipDefiner, "skip-definer")
+	add(o.noAutoIncrementValue, "skip-auto-increment")
+	add(o.noIfNotExists, "no-if-not-exists")
+	add(o.qualifiedNames, "qualified-names")
+	add(o.isIgnoreInsert, "insert-ignore")
+	add(len(o.tables) > 0 && !o.isAllTable, "tables="+strings.Join(o.tables, ","))
+	add(len(o.ignoreTables) > 0, "ignore-tables="+strings.Join(o.ignoreTables, ","))
+	add(len(o.noDataTables) > 0, "no-data-tables="+strings.Join(o.noDataTables, ","))
+	add(len(o.ignoreEngines) > 0, "ignore-engines="+strings.Join(o.ignoreEngines, ","))
+	add(o.maxTableSize > 0, fmt.Sprintf("max-table-size=%d", o.maxTableSize))
+	add(len(o.tableOptions) > 0, "table-options")
+	add(len(o.omitColumns) > 0, "omit-columns")
+	add(len(o.transforms) > 0 || len(o.maskRules) > 0, "mask")
+	add(len(o.sampleRates) > 0 || o.sampleEvery > 1, "sample")
+	add(o.subsetTable != "", "subset="+o.subsetTable)
+	add(o.incremental != nil, "incremental")
+	add(o.chunkSize > 0, fmt.Sprintf("chunk-size=%d", o.chunkSize))
+	add(o.concurrency > 1, fmt.Sprintf("concurrency=%d", o.concurrency))
+	add(o.noPartitions, "skip-partition")
+	add(o.partitionConcurrency > 1, fmt.Sprintf("partition-concurrency=%d", o.partitionConcurrency))
+	add(o.singleTransaction && !o.lockAllTables, "single-transaction")
+	add(o.lockAllTables, "lock-all-tables")
+	add(o.lockNonTransactional, "lock-non-transactional")
+	add(o.checkpoint != nil, "checkpoint")
+	add(o.summaryFooter, "summary-footer")
+	add(o.noDSNDefaults, "no-dsn-defaults")
+	add(o.compress, "compress")
+	add(o.noProvenance, "no-provenance")
+	add(o.readOnlyGuard, "read-only-guard")
+	add(o.bufferSize > 0, fmt.Sprintf("buffer-size=%d", o.bufferSize))
+	add(o.flushInterval > 0, "flush-interval="+o.flushInterval.String())
+	add(o.grants, "grants")
+	return names
+}
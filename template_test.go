@@ -0,0 +1,70 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestHeaderFooterTemplate(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	meta := &DumpMeta{Database: "db", ServerVersion: "8.0.36", StartTime: start, EndTime: start.Add(time.Second)}
+	// 文件尾注释不输出时仍有完成标记
+	completed := "-- Dump completed on 2024-01-02 03:04:06\n"
+	header := template.Must(template.New("header").Parse("-- {{.Database}} {{.ServerVersion}} {{range .Options}}{{.}} {{end}}{{.StartTime.Format \"2006-01-02\"}}\n"))
+	tests := []struct {
+		name       string
+		opts       []DumpOption
+		variables  []ServerVariable
+		wantHeader string
+		wantFooter string
+	}{
+		{
+			name:       "default",
+			wantHeader: "-- ----------------------------\n-- MySQL Database Dump\n-- Start Time: 2024-01-02 03:04:05\n-- Server Version: 8.0.36\n-- ----------------------------\n\n\n",
+			wantFooter: "-- ----------------------------\n-- Dumped by mysqldump\n-- Cost Time: 1s\n-- ----------------------------\n" + completed,
+		},
+		{
+			name:       "template",
+			opts:       []DumpOption{WithData(), WithDropTable(), WithHeaderTemplate(header), WithFooterTemplate(nil)},
+			wantHeader: "-- db 8.0.36 data drop-table 2024-01-02\n\n\n",
+			wantFooter: completed,
+		},
+		{
+			name:      "variables",
+			opts:      []DumpOption{WithFooterTemplate(nil), WithoutProvenance()},
+			variables: []ServerVariable{{Name: "sql_mode", Value: "STRICT_TRANS_TABLES"}, {Name: "time_zone", Value: "SYSTEM"}},
+			wantHeader: "-- ----------------------------\n-- MySQL Database Dump\n-- Start Time: 2024-01-02 03:04:05\n" +
+				"-- Server Variables:\n--   sql_mode = STRICT_TRANS_TABLES\n--   time_zone = SYSTEM\n-- ----------------------------\n\n\n",
+			wantFooter: completed,
+		},
+		{
+			name:       "suppressed",
+			opts:       []DumpOption{WithHeaderTemplate(nil), WithFooterTemplate(nil)},
+			wantHeader: "\n\n",
+			wantFooter: completed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			m := *meta
+			m.Options = o.optionNames()
+			m.ServerVariables = tt.variables
+			var h, f strings.Builder
+			if err := o.formatter.Header(&h, &m); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.formatter.Footer(&f, &m); err != nil {
+				t.Fatal(err)
+			}
+			if h.String() != tt.wantHeader {
+				t.Errorf("header = %q, want %q", h.String(), tt.wantHeader)
+			}
+			if f.String() != tt.wantFooter {
+				t.Errorf("footer = %q, want %q", f.String(), tt.wantFooter)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package mysqldump
+
+import (
+	"database/sql"
+	"strings"
+)
+
+const (
+	// tidbChunkSize TiDB 模式默认的分块行数, 避免单个查询超过 tidb_mem_quota_query
+	tidbChunkSize = 200000
+	// tidbBatchSize TiDB 模式 Clone 时每条 INSERT 的行数, TiDB 建议使用较小的事务
+	tidbBatchSize = 256
+	// tidbRowID 没有聚簇主键的表的隐藏行 ID, 用于分块
+	tidbRowID = "_tidb_rowid"
+)
+
+// WithTiDB 使用 TiDB 模式导出, 连接到 TiDB 时自动开启
+//   - 不输出 TiDB 不支持的 LOCK TABLES 和 DISABLE KEYS
+//   - 文件头允许显式写入 AUTO_RANDOM 列
+//   - 默认按 200000 行分块, 没有聚簇主键的表按 _tidb_rowid 分块
+//   - Clone 到 TiDB 时每条 INSERT 256 行
+func WithTiDB() DumpOption {
+	return func(option *dumpOption) {
+		option.tidb = true
+	}
+}
+
+// applyTiDB 根据服务端类型开启 TiDB 模式, 并设置 TiDB 模式的默认值
+func (o *dumpOption) applyTiDB(v ServerVersion) {
+	if v.Flavor == FlavorTiDB {
+		o.tidb = true
+	}
+	if !o.tidb {
+		return
+	}
+	if o.chunkSize <= 0 {
+		o.chunkSize = tidbChunkSize
+	}
+	if o.cloneBatchSize <= 0 {
+		o.cloneBatchSize = tidbBatchSize
+	}
+}
+
+// tidbAutoRandomSQL 允许 INSERT 指定 AUTO_RANDOM 列的值, 只在支持 AUTO_RANDOM 的 TiDB 上执行
+const tidbAutoRandomSQL = "/*T![auto_rand] SET @@SESSION.allow_auto_random_explicit_insert = 1 */;\n"
+
+// hasTiDBRowID 表没有聚簇主键时返回 true, 此时可以使用 _tidb_rowid 分块
+func hasTiDBRowID(db queryer, table string) (bool, error) {
+	var pkType sql.NullString
+	err := db.QueryRow("SELECT TIDB_PK_TYPE FROM information_schema.TABLES "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", table).Scan(&pkType)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(pkType.String, "NONCLUSTERED"), nil
+}
@@ -0,0 +1,52 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_dumpOption_applyTiDB(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []DumpOption
+		version   string
+		tidb      bool
+		chunkSize int
+	}{
+		{name: "mysql", version: "8.0.36"},
+		{name: "detected", version: "8.0.11-TiDB-v7.5.0", tidb: true, chunkSize: tidbChunkSize},
+		{name: "option", opts: []DumpOption{WithTiDB()}, version: "8.0.36", tidb: true, chunkSize: tidbChunkSize},
+		{name: "chunk size", opts: []DumpOption{WithChunkSize(1000)}, version: "8.0.11-TiDB-v7.5.0", tidb: true, chunkSize: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			o.applyTiDB(ParseServerVersion(tt.version))
+			if o.tidb != tt.tidb || o.chunkSize != tt.chunkSize {
+				t.Errorf("tidb = %v, chunkSize = %d, want %v, %d", o.tidb, o.chunkSize, tt.tidb, tt.chunkSize)
+			}
+		})
+	}
+}
+
+func Test_sqlFormatter_tidb(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithTiDB(), WithAddLocks(), WithDisableKeys(), WithHeaderTemplate(nil)})
+	table := &TableMeta{Name: "t"}
+	var sb strings.Builder
+	for _, step := range []func() error{
+		func() error { return o.formatter.Header(&sb, &DumpMeta{Database: "db"}) },
+		func() error { return o.formatter.TableDataBegin(&sb, table) },
+		func() error { return o.formatter.TableDataEnd(&sb, table) },
+	} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := sb.String()
+	if !strings.Contains(got, tidbAutoRandomSQL) {
+		t.Errorf("output should allow explicit AUTO_RANDOM insert: %q", got)
+	}
+	if strings.Contains(got, "LOCK TABLES") || strings.Contains(got, "DISABLE KEYS") {
+		t.Errorf("output should not lock tables or disable keys: %q", got)
+	}
+}
@@ -0,0 +1,53 @@
+package mysqldump
+
+import "context"
+
+// Tracer 链路追踪, 用于对接 OpenTelemetry 等, 例如:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string) (context.Context, mysqldump.Span) {
+//		ctx, span := o.t.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span 追踪的一个区间
+type Span interface {
+	// SetAttribute 设置属性, value 为 string, int64 或 bool
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// WithTracer 对整个导出, 每个表的结构和数据导出创建 span
+// span 名称为 mysqldump.dump, mysqldump.table_schema, mysqldump.table_data
+func WithTracer(tracer Tracer) DumpOption {
+	return func(option *dumpOption) {
+		option.tracer = tracer
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+// startSpan tracer 为 nil 时返回空 span
+func startSpan(ctx context.Context, tracer Tracer, name string) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name)
+}
+
+// endSpan 记录错误并结束 span
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
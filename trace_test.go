@@ -0,0 +1,55 @@
+package mysqldump
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordTracer 按结束顺序记录 span 的名称和属性
+type recordTracer struct {
+	mu    sync.Mutex
+	spans []*recordSpan
+}
+
+type recordSpan struct {
+	t     *recordTracer
+	name  string
+	attrs map[string]interface{}
+	err   error
+}
+
+func (t *recordTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, &recordSpan{t: t, name: name, attrs: make(map[string]interface{})}
+}
+
+func (s *recordSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *recordSpan) RecordError(err error)                      { s.err = err }
+func (s *recordSpan) End() {
+	s.t.mu.Lock()
+	s.t.spans = append(s.t.spans, s)
+	s.t.mu.Unlock()
+}
+
+func TestWithTracer(t *testing.T) {
+	db, _ := newFakeDumpDB(t)
+	tracer := &recordTracer{}
+	if _, err := fakeDump(db, WithTables("a"), WithData(), WithTracer(tracer)); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	var names []string
+	for _, span := range tracer.spans {
+		names = append(names, span.name)
+	}
+	if want := "mysqldump.table_schema,mysqldump.table_data,mysqldump.dump"; strings.Join(names, ",") != want {
+		t.Fatalf("spans = %v, want %s", names, want)
+	}
+	data, dump := tracer.spans[1], tracer.spans[2]
+	if data.attrs["db.sql.table"] != "a" || data.attrs["mysqldump.rows"] != int64(2) {
+		t.Errorf("table_data attributes = %v", data.attrs)
+	}
+	if dump.attrs["mysqldump.tables"] != int64(1) || dump.attrs["mysqldump.rows"] != int64(2) || dump.err != nil {
+		t.Errorf("dump attributes = %v, error = %v", dump.attrs, dump.err)
+	}
+}
@@ -1,21 +1,121 @@
 package mysqldump
 
 import (
-	"fmt"
+	"hash"
+	"io"
+	"net"
 	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 //从dsn中提取出数据库名称，并将其作为结果返回。
 //如果无法解析出数据库名称，将返回一个错误。
 
 func GetDBNameFromDSN(dsn string) (string, error) {
-	ss1 := strings.Split(dsn, "/")
-	if len(ss1) == 2 {
-		ss2 := strings.Split(ss1[1], "?")
-		if len(ss2) == 2 {
-			return ss2[0], nil
+	cfg, err := checkDSN(dsn, &dumpOption{})
+	if err != nil {
+		return "", err
+	}
+	return cfg.DBName, nil
+}
+
+// getHostFromDSN 返回 DSN 中的主机名, unix socket 返回 localhost
+func getHostFromDSN(dsn string) string {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return ""
+	}
+	if cfg.Net == "unix" {
+		return "localhost"
+	}
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return cfg.Addr
+	}
+	return host
+}
+
+// EscapeString 转义 MySQL 字符串字面量中的特殊字符, 结果可以放在单引号或双引号中
+// 与 mysql_real_escape_string 相同, 要求 sql_mode 没有 NO_BACKSLASH_ESCAPES
+func EscapeString(s string) string {
+	return string(appendEscaped(make([]byte, 0, len(s)), s))
+}
+
+// appendEscaped 将 EscapeString 的结果追加到 dst
+func appendEscaped[T string | []byte](dst []byte, s T) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case 0:
+			dst = append(dst, `\0`...)
+		case '\n':
+			dst = append(dst, `\n`...)
+		case '\r':
+			dst = append(dst, `\r`...)
+		case '\\':
+			dst = append(dst, `\\`...)
+		case '\'':
+			dst = append(dst, `\'`...)
+		case '"':
+			dst = append(dst, `\"`...)
+		case '\x1a':
+			dst = append(dst, `\Z`...)
+		default:
+			dst = append(dst, c)
 		}
 	}
+	return dst
+}
+
+// QuoteIdentifier 使用反引号包裹库名, 表名或列名, 名称中的反引号转义为两个反引号
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
 
-	return "", fmt.Errorf("dsn error: %s", dsn)
+// appendIdentifier 将 QuoteIdentifier 的结果追加到 dst
+func appendIdentifier(dst []byte, name string) []byte {
+	dst = append(dst, '`')
+	for i := 0; i < len(name); i++ {
+		if name[i] == '`' {
+			dst = append(dst, '`')
+		}
+		dst = append(dst, name[i])
+	}
+	return append(dst, '`')
+}
+
+// commentName 替换名称中的换行, 名称写入 -- 注释时不会截断注释
+func commentName(name string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(name)
+}
+
+// countWriter 统计写出的字节数, hash 不为 nil 时同时计算写出内容的哈希
+type countWriter struct {
+	w    io.Writer
+	n    int64
+	hash hash.Hash
+	// 每次写入后以已写出的字节数调用, 返回错误时停止写入
+	onWrite func(int64) error
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if c.hash != nil {
+		c.hash.Write(p[:n])
+	}
+	if err == nil && c.onWrite != nil {
+		err = c.onWrite(c.n)
+	}
+	return n, err
+}
+
+// quoteColumns 引用每个列名并以逗号连接, 如 `a`,`b`
+func quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = QuoteIdentifier(column)
+	}
+	return strings.Join(quoted, ",")
 }
@@ -0,0 +1,183 @@
+package mysqldump
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscapeString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: ""},
+		{in: "abc", want: "abc"},
+		{in: "中文", want: "中文"},
+		{in: "it's", want: `it\'s`},
+		{in: `say "hi"`, want: `say \"hi\"`},
+		{in: `C:\path`, want: `C:\\path`},
+		{in: "a\nb\rc", want: `a\nb\rc`},
+		{in: "nul\x00", want: `nul\0`},
+		{in: "ctrl-z\x1a", want: `ctrl-z\Z`},
+		{in: "tab\t%_", want: "tab\t%_"},
+		{in: `\'`, want: `\\\'`},
+	}
+	for _, tt := range tests {
+		if got := EscapeString(tt.in); got != tt.want {
+			t.Errorf("EscapeString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "users", want: "`users`"},
+		{in: "", want: "``"},
+		{in: "order items", want: "`order items`"},
+		{in: "a`b", want: "`a``b`"},
+		{in: "``", want: "``````"},
+		{in: "表", want: "`表`"},
+		{in: "a'b\"c", want: "`a'b\"c`"},
+	}
+	for _, tt := range tests {
+		if got := QuoteIdentifier(tt.in); got != tt.want {
+			t.Errorf("QuoteIdentifier(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	ts := time.Date(2024, 2, 29, 13, 14, 15, 0, time.UTC)
+	tests := []struct {
+		name    string
+		col     interface{}
+		typ     string
+		want    string
+		wantErr bool
+	}{
+		{name: "null", col: nil, typ: "VARCHAR", want: "NULL"},
+		{name: "null unknown type", col: nil, typ: "GEOMETRY", want: "NULL"},
+		{name: "tinyint", col: int64(-128), typ: "TINYINT", want: "-128"},
+		{name: "smallint", col: int64(32767), typ: "SMALLINT", want: "32767"},
+		{name: "mediumint", col: int64(1), typ: "MEDIUMINT", want: "1"},
+		{name: "int", col: int64(42), typ: "INT", want: "42"},
+		{name: "integer", col: int64(42), typ: "INTEGER", want: "42"},
+		{name: "int bytes", col: []byte("42"), typ: "INT", want: "42"},
+		{name: "unsigned int", col: int64(4294967295), typ: "UNSIGNED INT", want: "4294967295"},
+		{name: "bigint", col: int64(-9223372036854775808), typ: "BIGINT", want: "-9223372036854775808"},
+		{name: "unsigned bigint", col: uint64(18446744073709551615), typ: "UNSIGNED BIGINT", want: "18446744073709551615"},
+		{name: "unsigned bigint as int64", col: int64(-1), typ: "BIGINT UNSIGNED", want: "18446744073709551615"},
+		{name: "unsigned bigint bytes", col: []byte("18446744073709551615"), typ: "BIGINT UNSIGNED", want: "18446744073709551615"},
+		{name: "unsigned bigint bytes", col: []byte("18446744073709551614"), typ: "UNSIGNED BIGINT", want: "18446744073709551614"},
+		// string 来自 ColumnTransform, 不论列类型都作为字符串输出
+		{name: "int string", col: "18446744073709551614", typ: "UNSIGNED BIGINT", want: "'18446744073709551614'"},
+		{name: "int masked", col: "1**", typ: "INT", want: "'1**'"},
+		{name: "date masked", col: "it's", typ: "DATE", want: `'it\'s'`},
+		{name: "int invalid", col: float64(1.5), typ: "INT", wantErr: true},
+		{name: "double bytes", col: []byte("1.5"), typ: "DOUBLE", want: "1.5"},
+		{name: "double", col: float64(1.5), typ: "DOUBLE", want: "1.5"},
+		{name: "double precision", col: float64(0.1234567890123), typ: "DOUBLE", want: "0.1234567890123"},
+		{name: "double small", col: float64(1e-7), typ: "DOUBLE", want: "1e-07"},
+		{name: "double large", col: float64(1.7976931348623157e308), typ: "DOUBLE", want: "1.7976931348623157e+308"},
+		{name: "float", col: float32(0.1), typ: "FLOAT", want: "0.1"},
+		{name: "float invalid", col: int64(1), typ: "FLOAT", wantErr: true},
+		{name: "decimal", col: []byte("123.45"), typ: "DECIMAL", want: "123.45"},
+		{name: "decimal negative", col: []byte("-0.01"), typ: "DECIMAL", want: "-0.01"},
+		{name: "date", col: ts, typ: "DATE", want: "'2024-02-29'"},
+		{name: "date bytes", col: []byte("2024-02-29"), typ: "DATE", wantErr: true},
+		{name: "datetime", col: ts, typ: "DATETIME", want: "'2024-02-29 13:14:15'"},
+		{name: "timestamp", col: ts, typ: "TIMESTAMP", want: "'2024-02-29 13:14:15'"},
+		{name: "datetime bytes", col: []byte("2024-02-29 13:14:15"), typ: "DATETIME", wantErr: true},
+		{name: "time", col: []byte("-838:59:59"), typ: "TIME", want: "'-838:59:59'"},
+		{name: "time invalid", col: int64(1), typ: "TIME", wantErr: true},
+		{name: "year", col: int64(2024), typ: "YEAR", want: "2024"},
+		{name: "year bytes", col: []byte("2024"), typ: "YEAR", want: "2024"},
+		{name: "year invalid", col: float64(2024), typ: "YEAR", wantErr: true},
+		{name: "char", col: []byte("a"), typ: "CHAR", want: "'a'"},
+		{name: "varchar empty", col: []byte(""), typ: "VARCHAR", want: "''"},
+		{name: "varchar quote", col: []byte("it's"), typ: "VARCHAR", want: `'it\'s'`},
+		{name: "varchar backslash", col: []byte(`C:\dir`), typ: "VARCHAR", want: `'C:\\dir'`},
+		{name: "text newline", col: []byte("a\r\nb"), typ: "TEXT", want: `'a\r\nb'`},
+		{name: "tinytext", col: []byte("x"), typ: "TINYTEXT", want: "'x'"},
+		{name: "mediumtext nul", col: []byte("a\x00b"), typ: "MEDIUMTEXT", want: `'a\0b'`},
+		{name: "longtext unicode", col: []byte("中文😀"), typ: "LONGTEXT", want: "'中文😀'"},
+		{name: "varchar string", col: "s", typ: "VARCHAR", want: "'s'"},
+		{name: "blob", col: []byte{0x00, 0x27, 0xff}, typ: "BLOB", want: "0x0027FF"},
+		{name: "blob empty", col: []byte{}, typ: "BLOB", want: "''"},
+		{name: "binary", col: []byte("a"), typ: "BINARY", want: "0x61"},
+		{name: "varbinary", col: []byte("ab"), typ: "VARBINARY", want: "0x6162"},
+		{name: "tinyblob", col: []byte{1}, typ: "TINYBLOB", want: "0x01"},
+		{name: "mediumblob", col: []byte{2}, typ: "MEDIUMBLOB", want: "0x02"},
+		{name: "longblob", col: []byte{3}, typ: "LONGBLOB", want: "0x03"},
+		{name: "bit", col: []byte{0x01}, typ: "BIT", want: "0x01"},
+		{name: "enum", col: []byte("small"), typ: "ENUM", want: "'small'"},
+		{name: "set", col: []byte("a,b"), typ: "SET", want: "'a,b'"},
+		{name: "enum quote", col: []byte("it's"), typ: "ENUM", want: `'it\'s'`},
+		{name: "enum backslash", col: []byte(`a\b`), typ: "ENUM", want: `'a\\b'`},
+		{name: "set quote", col: []byte(`o'k,"x",y`), typ: "SET", want: `'o\'k,\"x\",y'`},
+		{name: "set empty", col: []byte(""), typ: "SET", want: "''"},
+		{name: "json", col: []byte(`{"a":1}`), typ: "JSON", want: `'{\"a\":1}'`},
+		{name: "json escape", col: []byte(`{"a": "it's \"q\" \\ b"}`), typ: "JSON", want: `'{\"a\": \"it\'s \\\"q\\\" \\\\ b\"}'`},
+		{name: "bool", col: true, typ: "BOOL", want: "true"},
+		{name: "boolean", col: false, typ: "BOOLEAN", want: "false"},
+		{name: "bool invalid", col: int64(1), typ: "BOOL", wantErr: true},
+		{name: "unsupported", col: []byte("x"), typ: "GEOMETRY", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatValue(tt.col, tt.typ)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// ENUM 和 SET 的值经过 FormatValue 和恢复时的解析后不变
+func TestFormatValue_enumSetRoundTrip(t *testing.T) {
+	values := []string{"small", "it's", `a\b`, "a,b,c", `o'k,"x",y`, "tab\there", "", "中文,值"}
+	for _, typ := range []string{"ENUM", "SET"} {
+		for _, value := range values {
+			literal, err := FormatValue([]byte(value), typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tokens := tokenizeSQL(literal)
+			if len(tokens) != 1 || tokens[0].kind != tokenString {
+				t.Fatalf("%s %q: literal %s is not a single string", typ, value, literal)
+			}
+			if got := unquoteString(tokens[0].text); got != value {
+				t.Errorf("%s %q: round trip = %q", typ, value, got)
+			}
+		}
+	}
+}
+
+func Test_scanRawBytes(t *testing.T) {
+	tests := []struct {
+		dataType string
+		want     bool
+	}{
+		{dataType: "VARCHAR", want: true},
+		{dataType: "LONGBLOB", want: true},
+		{dataType: "DECIMAL", want: true},
+		{dataType: "JSON", want: true},
+		// 驱动转换为 int64, float64 和 time.Time 的类型
+		{dataType: "BIGINT", want: false},
+		{dataType: "UNSIGNED INT", want: false},
+		{dataType: "DOUBLE", want: false},
+		{dataType: "DATETIME", want: false},
+		{dataType: "YEAR", want: false},
+	}
+	for _, tt := range tests {
+		if got := scanRawBytes(tt.dataType); got != tt.want {
+			t.Errorf("scanRawBytes(%q) = %v, want %v", tt.dataType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package mysqldump
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrUnterminated 字符串, 标识符或注释没有结束
+	ErrUnterminated = errors.New("unterminated string, identifier or comment")
+	// ErrTruncated 最后一条语句没有以 ; 结尾, 文件可能被截断
+	ErrTruncated = errors.New("last statement is not terminated")
+	// ErrUnbalancedParentheses 语句中的括号不匹配
+	ErrUnbalancedParentheses = errors.New("unbalanced parentheses")
+	// ErrMissingFooter 没有文件尾注释, 导出可能没有完成
+	ErrMissingFooter = errors.New("missing dump footer")
+	// ErrIncomplete 导出失败或取消, 文件末尾有 incompleteMarker
+	ErrIncomplete = errors.New("dump incomplete")
+)
+
+// dumpCompletedMarker 导出完成后总是输出的文件尾, 与官方 mysqldump 相同
+const dumpCompletedMarker = "-- Dump completed"
+
+// dumpFooterMarkers 文件尾注释, 包括本工具旧版本的格式
+var dumpFooterMarkers = []string{dumpCompletedMarker, "-- Dumped by mysqldump"}
+
+// Validate 按语句读取导出文件, 检查字符串或注释没有结束, 文件被截断, 括号不匹配和缺少文件尾等明显的损坏,
+// 不连接数据库. 返回的错误可以用 errors.Is 判断问题类型, 多个问题时通过 errors.Join 合并
+func Validate(r io.Reader) error {
+	br := bufio.NewReader(r)
+	var problems []error
+	var offset int64
+	var statements int
+	for {
+		stmt, err := readStatement(br)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF {
+			switch problem := tailProblem(stmt); problem {
+			case nil:
+			case ErrMissingFooter:
+				problems = append(problems, problem)
+			default:
+				problems = append(problems, fmt.Errorf("offset %d: %w", offset, problem))
+			}
+			break
+		}
+		statements++
+		if parenDepth(stmt) != 0 {
+			problems = append(problems, fmt.Errorf("statement %d at offset %d: %w", statements, offset, ErrUnbalancedParentheses))
+		}
+		offset += int64(len(stmt))
+	}
+	return errors.Join(problems...)
+}
+
+// tailProblem 检查最后一条语句之后的内容, 返回 ErrIncomplete, ErrUnterminated, ErrTruncated 或 ErrMissingFooter
+func tailProblem(tail string) error {
+	switch {
+	case strings.Contains(tail, "\n"+incompleteMarker):
+		return ErrIncomplete
+	case scanOpenQuote(tail) != 0:
+		return ErrUnterminated
+	case len(statementTokens(tail)) > 0:
+		return ErrTruncated
+	case !hasFooter(tail):
+		return ErrMissingFooter
+	}
+	return nil
+}
+
+func hasFooter(s string) bool {
+	for _, marker := range dumpFooterMarkers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// parenDepth 返回语句结束时未闭合的括号数, 字符串和注释中的括号不计算
+func parenDepth(stmt string) int {
+	depth := 0
+	for _, t := range tokenizeSQL(stmt) {
+		if t.kind != tokenPunct {
+			continue
+		}
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+	}
+	return depth
+}
+
+// scanOpenQuote 返回 s 结束时所在的引号, 在多行注释中时返回 '*', 都不在时返回 0
+// 规则与 readStatement 相同
+func scanOpenQuote(s string) byte {
+	var quote, comment byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case comment == '-':
+			if c == '\n' {
+				comment = 0
+			}
+		case comment == '*':
+			if c == '*' && i+1 < len(s) && s[i+1] == '/' {
+				comment = 0
+				i++
+			}
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '#' || c == '-' && strings.HasPrefix(s[i:], "-- ") || c == '-' && strings.HasPrefix(s[i:], "--\n"):
+			comment = '-'
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			comment = '*'
+			i++
+		}
+	}
+	if quote != 0 {
+		return quote
+	}
+	if comment == '*' {
+		return '*'
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package mysqldump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	body := "CREATE TABLE `t` (`id` int, `s` text);\nINSERT INTO `t` VALUES (1,'a;b'),(2,'it\\'s');\n"
+	footer := "\n-- Dumped by mysqldump at 2024-01-01 00:00:00\n"
+	tests := []struct {
+		name string
+		dump string
+		want []error
+	}{
+		{"valid", body + footer, nil},
+		{"compat footer", body + "-- Dump completed on 2024-01-01 00:00:00\n", nil},
+		{"missing footer", body, []error{ErrMissingFooter}},
+		{"truncated", body + "INSERT INTO `t` VALUES (3,'c')", []error{ErrTruncated}},
+		{"unterminated string", body + "INSERT INTO `t` VALUES (3,'c);\n" + footer, []error{ErrUnterminated}},
+		{"unterminated comment", body + "/* comment;\n" + footer, []error{ErrUnterminated}},
+		{"unbalanced", "INSERT INTO `t` VALUES (1,'a';\n" + footer, []error{ErrUnbalancedParentheses}},
+		{"parentheses in string", "INSERT INTO `t` VALUES (1,'(');\n" + footer, nil},
+		{"incomplete", body + "\n-- DUMP INCOMPLETE: connection lost\n", []error{ErrIncomplete}},
+		{"incomplete in statement", body + "INSERT INTO `t` VALUES (3,'c\n-- DUMP INCOMPLETE: canceled\n", []error{ErrIncomplete}},
+		{"several", "INSERT INTO `t` VALUES (1;\nINSERT", []error{ErrUnbalancedParentheses, ErrTruncated}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(strings.NewReader(tt.dump))
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v", err)
+				}
+				return
+			}
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("Validate() = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_tailProblem(t *testing.T) {
+	tests := []struct {
+		tail string
+		want error
+	}{
+		{tail: "\n-- Dump completed on 2024-01-01 00:00:00\n", want: nil},
+		{tail: "\n-- Dumped by mysqldump\n", want: nil},
+		{tail: "\n", want: ErrMissingFooter},
+		{tail: "INSERT INTO `t` VALUES (1)", want: ErrTruncated},
+		{tail: "INSERT INTO `t` VALUES ('a", want: ErrUnterminated},
+		{tail: "INSERT INTO `t` VALUES ('a\n-- DUMP INCOMPLETE: canceled\n", want: ErrIncomplete},
+	}
+	for _, tt := range tests {
+		if got := tailProblem(tt.tail); got != tt.want {
+			t.Errorf("tailProblem(%q) = %v, want %v", tt.tail, got, tt.want)
+		}
+	}
+}
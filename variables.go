@@ -0,0 +1,82 @@
+package mysqldump
+
+import "strings"
+
+// defaultServerVariables WithServerVariables 未指定变量名时记录的变量
+var defaultServerVariables = []string{
+	"version",
+	"sql_mode",
+	"character_set_server",
+	"collation_server",
+	"time_zone",
+	"system_time_zone",
+	"lower_case_table_names",
+	"explicit_defaults_for_timestamp",
+	"innodb_file_per_table",
+	"default_storage_engine",
+}
+
+// ServerVariable 服务端变量
+type ServerVariable struct {
+	Name  string
+	Value string
+}
+
+// WithServerVariables 在文件头以注释记录源库的全局变量, 恢复结果不同时可以对比源库配置
+// names 为空时记录 sql_mode, character_set_server, time_zone, innodb_file_per_table 等常用变量
+func WithServerVariables(names ...string) DumpOption {
+	return func(option *dumpOption) {
+		if len(names) == 0 {
+			names = defaultServerVariables
+		}
+		option.serverVariables = names
+	}
+}
+
+// getServerVariables 按 names 的顺序返回全局变量, 不存在的变量忽略
+func getServerVariables(db queryer, names []string) ([]ServerVariable, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(names)), ",")
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	rows, err := db.Query("SHOW GLOBAL VARIABLES WHERE Variable_name IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		err = rows.Scan(&name, &value)
+		if err != nil {
+			return nil, err
+		}
+		values[strings.ToLower(name)] = value
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var variables []ServerVariable
+	for _, name := range names {
+		if value, ok := values[strings.ToLower(name)]; ok {
+			variables = append(variables, ServerVariable{Name: name, Value: value})
+		}
+	}
+	return variables, nil
+}
+
+// serverVariablesComment 变量的注释, 每行一个变量
+func serverVariablesComment(variables []ServerVariable) string {
+	if len(variables) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("-- Server Variables:\n")
+	for _, v := range variables {
+		sb.WriteString("--   " + v.Name + " = " + strings.ReplaceAll(v.Value, "\n", " ") + "\n")
+	}
+	return sb.String()
+}
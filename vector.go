@@ -0,0 +1,40 @@
+package mysqldump
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// vectorText 将 VECTOR 列的值转换为 TO_VECTOR 使用的文本, 如 [1,2.5]
+// 驱动返回的值为小端序的 float32 数组, 长度不是 4 的倍数时返回 false
+func vectorText(b []byte) (string, bool) {
+	if len(b) == 0 || len(b)%4 != 0 {
+		return "", false
+	}
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i := 0; i < len(b); i += 4 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		f := math.Float32frombits(binary.LittleEndian.Uint32(b[i:]))
+		sb.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String(), true
+}
+
+// vectorValue 服务端支持时 VECTOR 输出为 TO_VECTOR('[...]'), 否则与二进制类型相同输出为十六进制
+func (o *dumpOption) vectorValue(col interface{}) (string, bool) {
+	bs, ok := col.([]byte)
+	if !ok || !o.serverVersion.supportsVector() {
+		return "", false
+	}
+	text, ok := vectorText(bs)
+	if !ok {
+		return "", false
+	}
+	return "TO_VECTOR('" + text + "')", true
+}
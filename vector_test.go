@@ -0,0 +1,55 @@
+package mysqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_vectorText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+		ok   bool
+	}{
+		{name: "values", in: []byte{0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x20, 0xc0}, want: "[1,-2.5]", ok: true},
+		{name: "fraction", in: []byte{0xcd, 0xcc, 0xcc, 0x3d}, want: "[0.1]", ok: true},
+		{name: "empty", in: []byte{}},
+		{name: "bad length", in: []byte{0x00, 0x00, 0x80}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := vectorText(tt.in)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("vectorText() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func Test_sqlFormatter_RowVector(t *testing.T) {
+	table := &TableMeta{Name: "t", Columns: []string{"id", "v"}, DataTypes: []string{"INT", "VECTOR"}}
+	row := []interface{}{int64(1), []byte{0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x00, 0x40}}
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{name: "mysql 9", version: "9.0.1", want: "INSERT INTO `t` VALUES (1,TO_VECTOR('[1,2]'));\n"},
+		{name: "older server", version: "8.0.36", want: "INSERT INTO `t` VALUES (1,0x0000803F00000040);\n"},
+		{name: "mariadb", version: "11.7.2-MariaDB", want: "INSERT INTO `t` VALUES (1,0x0000803F00000040);\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(nil)
+			o.serverVersion = ParseServerVersion(tt.version)
+			var sb strings.Builder
+			if err := o.formatter.Row(&sb, table, row); err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("Row() = %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
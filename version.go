@@ -0,0 +1,142 @@
+package mysqldump
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ServerFlavor 数据库服务端类型
+type ServerFlavor string
+
+const (
+	FlavorMySQL   ServerFlavor = "mysql"
+	FlavorMariaDB ServerFlavor = "mariadb"
+	FlavorTiDB    ServerFlavor = "tidb"
+)
+
+// ServerVersion SELECT VERSION() 解析后的服务端版本
+// TiDB 的版本号为 TiDB 自身的版本, 如 8.0.11-TiDB-v7.5.0 为 7.5.0
+type ServerVersion struct {
+	// SELECT VERSION() 的原始结果
+	Raw    string
+	Flavor ServerFlavor
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+// ParseServerVersion 解析 SELECT VERSION() 的结果
+// 如 8.0.36, 5.7.44-log, 10.11.6-MariaDB-1:10.11.6+maria~ubu2204, 5.5.5-10.6.12-MariaDB, 8.0.11-TiDB-v7.5.0
+func ParseServerVersion(raw string) ServerVersion {
+	v := ServerVersion{Raw: raw, Flavor: FlavorMySQL}
+	number := raw
+	lower := strings.ToLower(raw)
+	switch {
+	case strings.Contains(lower, "tidb"):
+		v.Flavor = FlavorTiDB
+		if i := strings.Index(lower, "-tidb-v"); i >= 0 {
+			number = raw[i+len("-tidb-v"):]
+		}
+	case strings.Contains(lower, "mariadb"):
+		v.Flavor = FlavorMariaDB
+		// 复制协议要求的 5.5.5- 前缀
+		number = strings.TrimPrefix(raw, "5.5.5-")
+	}
+	parts := strings.SplitN(number, ".", 3)
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		*nums[i], _ = strconv.Atoi(part[:end])
+	}
+	return v
+}
+
+func (v ServerVersion) String() string {
+	return fmt.Sprintf("%s %d.%d.%d", v.Flavor, v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast 版本号是否不小于 major.minor.patch
+func (v ServerVersion) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// supportsSequences 是否支持 CREATE SEQUENCE, MariaDB 10.3 开始支持
+func (v ServerVersion) supportsSequences() bool {
+	return v.Flavor == FlavorMariaDB && v.AtLeast(10, 3, 0)
+}
+
+// supportsRoles 是否支持 MySQL 8.0 的角色, MariaDB 的角色语法不同
+func (v ServerVersion) supportsRoles() bool {
+	return v.Flavor == FlavorMySQL && v.AtLeast(8, 0, 0)
+}
+
+// supportsBackupLock 是否支持 LOCK INSTANCE FOR BACKUP, MySQL 8.0 开始支持
+func (v ServerVersion) supportsBackupLock() bool {
+	return v.Flavor == FlavorMySQL && v.AtLeast(8, 0, 0)
+}
+
+// supportsVector 是否支持 VECTOR 类型和 TO_VECTOR, MySQL 9.0 开始支持
+func (v ServerVersion) supportsVector() bool {
+	return v.Flavor == FlavorMySQL && v.AtLeast(9, 0, 0)
+}
+
+// charset 导出文件使用的字符集, MySQL 5.5.3 之前没有 utf8mb4
+func (v ServerVersion) charset() string {
+	if v.Flavor == FlavorMySQL && v.Major > 0 && !v.AtLeast(5, 5, 3) {
+		return "utf8"
+	}
+	return "utf8mb4"
+}
+
+// binlogStatusQuery 查询 binlog 位置的语句, MySQL 8.2 开始使用 SHOW BINARY LOG STATUS
+func (v ServerVersion) binlogStatusQuery() string {
+	if v.Flavor == FlavorMySQL && v.AtLeast(8, 2, 0) {
+		return "SHOW BINARY LOG STATUS"
+	}
+	return "SHOW MASTER STATUS"
+}
+
+// gtidQuery 查询已执行的 GTID 集合的语句, MariaDB 的 GTID 格式与 MySQL 不同
+func (v ServerVersion) gtidQuery() string {
+	switch v.Flavor {
+	case FlavorMariaDB:
+		return "SELECT @@GLOBAL.gtid_binlog_pos"
+	case FlavorTiDB:
+		return ""
+	}
+	if !v.AtLeast(5, 6, 5) {
+		return ""
+	}
+	return "SELECT @@GLOBAL.gtid_executed"
+}
+
+// getServerVersion 查询服务端版本, 失败时返回空版本
+func getServerVersion(db queryer) ServerVersion {
+	var raw string
+	_ = db.QueryRow("SELECT VERSION()").Scan(&raw)
+	return ParseServerVersion(raw)
+}
+
+// getGTIDExecuted 获取已执行的 GTID 集合, 未开启 GTID 或没有权限时返回空
+func getGTIDExecuted(db queryer, v ServerVersion) string {
+	query := v.gtidQuery()
+	if query == "" {
+		return ""
+	}
+	var gtid sql.NullString
+	if db.QueryRow(query).Scan(&gtid) != nil {
+		return ""
+	}
+	return strings.ReplaceAll(gtid.String, "\n", "")
+}
@@ -0,0 +1,51 @@
+package mysqldump
+
+import "testing"
+
+func TestParseServerVersion(t *testing.T) {
+	tests := []struct {
+		raw    string
+		flavor ServerFlavor
+		want   [3]int
+	}{
+		{"8.0.36", FlavorMySQL, [3]int{8, 0, 36}},
+		{"5.7.44-log", FlavorMySQL, [3]int{5, 7, 44}},
+		{"10.11.6-MariaDB-1:10.11.6+maria~ubu2204", FlavorMariaDB, [3]int{10, 11, 6}},
+		{"5.5.5-10.6.12-MariaDB", FlavorMariaDB, [3]int{10, 6, 12}},
+		{"8.0.11-TiDB-v7.5.0", FlavorTiDB, [3]int{7, 5, 0}},
+		{"", FlavorMySQL, [3]int{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		v := ParseServerVersion(tt.raw)
+		if v.Flavor != tt.flavor || [3]int{v.Major, v.Minor, v.Patch} != tt.want {
+			t.Errorf("ParseServerVersion(%q) = %v, want %s %v", tt.raw, v, tt.flavor, tt.want)
+		}
+	}
+}
+
+func TestServerVersion_features(t *testing.T) {
+	tests := []struct {
+		raw       string
+		sequences bool
+		roles     bool
+		backup    bool
+		charset   string
+		binlog    string
+		gtid      string
+	}{
+		{"8.4.0", false, true, true, "utf8mb4", "SHOW BINARY LOG STATUS", "SELECT @@GLOBAL.gtid_executed"},
+		{"5.7.44-log", false, false, false, "utf8mb4", "SHOW MASTER STATUS", "SELECT @@GLOBAL.gtid_executed"},
+		{"5.5.1", false, false, false, "utf8", "SHOW MASTER STATUS", ""},
+		{"10.6.12-MariaDB", true, false, false, "utf8mb4", "SHOW MASTER STATUS", "SELECT @@GLOBAL.gtid_binlog_pos"},
+		{"10.2.44-MariaDB", false, false, false, "utf8mb4", "SHOW MASTER STATUS", "SELECT @@GLOBAL.gtid_binlog_pos"},
+		{"8.0.11-TiDB-v7.5.0", false, false, false, "utf8mb4", "SHOW MASTER STATUS", ""},
+	}
+	for _, tt := range tests {
+		v := ParseServerVersion(tt.raw)
+		if v.supportsSequences() != tt.sequences || v.supportsRoles() != tt.roles || v.supportsBackupLock() != tt.backup || v.charset() != tt.charset ||
+			v.binlogStatusQuery() != tt.binlog || v.gtidQuery() != tt.gtid {
+			t.Errorf("%s: sequences=%v roles=%v backup=%v charset=%s binlog=%q gtid=%q", tt.raw,
+				v.supportsSequences(), v.supportsRoles(), v.supportsBackupLock(), v.charset(), v.binlogStatusQuery(), v.gtidQuery())
+		}
+	}
+}
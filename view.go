@@ -0,0 +1,114 @@
+package mysqldump
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// WithCommentBrokenViews 引用的表或列已不存在的视图无法 SHOW CREATE VIEW,
+// 默认导出失败, 使用此选项时将其定义作为注释输出并继续, 与 mysqldump --force 相同
+func WithCommentBrokenViews() DumpOption {
+	return func(option *dumpOption) {
+		option.commentBrokenViews = true
+	}
+}
+
+// getViews 返回当前数据库中的视图
+func getViews(db queryer) (map[string]bool, error) {
+	rows, err := db.Query("SELECT TABLE_NAME FROM information_schema.TABLES " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'VIEW'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		views[name] = true
+	}
+	return views, rows.Err()
+}
+
+// sortViewsLast 将视图移到表之后, 恢复时视图依赖的表已经存在
+func sortViewsLast(tables []string, views map[string]bool) []string {
+	result := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !views[table] {
+			result = append(result, table)
+		}
+	}
+	for _, table := range tables {
+		if views[table] {
+			result = append(result, table)
+		}
+	}
+	return result
+}
+
+// getCreateViewSQL 返回 CREATE OR REPLACE VIEW 语句
+func getCreateViewSQL(db queryer, view string, o *dumpOption) (string, error) {
+	var name, createViewSQL, charset, collation string
+	err := db.QueryRow("SHOW CREATE VIEW "+QuoteIdentifier(view)).Scan(&name, &createViewSQL, &charset, &collation)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(createViewSQL, "CREATE ") && !strings.HasPrefix(createViewSQL, "CREATE OR REPLACE ") {
+		createViewSQL = "CREATE OR REPLACE " + strings.TrimPrefix(createViewSQL, "CREATE ")
+	}
+	if o.skipDefiner {
+		createViewSQL = stripDefiner(createViewSQL)
+	}
+	return createViewSQL, nil
+}
+
+// writeViewStruct 导出视图定义, 无效的视图根据选项输出为注释
+func writeViewStruct(db queryer, view string, w io.Writer, o *dumpOption) error {
+	createViewSQL, err := getCreateViewSQL(db, view, o)
+	if err == nil {
+		return o.formatter.TableSchema(w, &TableMeta{Name: view, CreateSQL: createViewSQL, View: true})
+	}
+	if !o.commentBrokenViews {
+		return err
+	}
+
+	var definition string
+	qerr := db.QueryRow("SELECT IFNULL(VIEW_DEFINITION, '') FROM information_schema.VIEWS "+
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", view).Scan(&definition)
+	if qerr != nil {
+		log.Printf("[warn] [dump] view %s: %v\n", view, qerr)
+	}
+	o.warnBrokenView(view, err)
+	if o.compact {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("-- ----------------------------\n")
+	sb.WriteString(fmt.Sprintf("-- View structure for %s (invalid: %s)\n", commentName(view), strings.ReplaceAll(err.Error(), "\n", " ")))
+	sb.WriteString("-- ----------------------------\n")
+	stmt := "CREATE OR REPLACE VIEW " + QuoteIdentifier(view) + " AS " + definition + ";"
+	for _, line := range strings.Split(stmt, "\n") {
+		sb.WriteString("-- " + line + "\n")
+	}
+	sb.WriteString("\n\n\n")
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// warnBrokenView 记录无效的视图
+func (o *dumpOption) warnBrokenView(view string, err error) {
+	o.warn(Warning{Kind: WarningBrokenView, Table: view, Message: err.Error()})
+	if o.result == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.result.FailedTables = append(o.result.FailedTables, FailedTable{Name: view, Error: err.Error()})
+}
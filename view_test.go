@@ -0,0 +1,32 @@
+package mysqldump
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_sortViewsLast(t *testing.T) {
+	got := sortViewsLast([]string{"v1", "a", "v2", "b"}, map[string]bool{"v1": true, "v2": true})
+	want := []string{"a", "b", "v1", "v2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortViewsLast() = %v, want %v", got, want)
+	}
+}
+
+func Test_sqlFormatter_View(t *testing.T) {
+	o := newDumpOption([]DumpOption{WithDropTable()})
+	var sb strings.Builder
+	err := o.formatter.TableSchema(&sb, &TableMeta{Name: "v", CreateSQL: "CREATE OR REPLACE VIEW `v` AS select 1 AS `a`", View: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DROP VIEW IF EXISTS `v`;\n" +
+		"-- ----------------------------\n" +
+		"-- View structure for v\n" +
+		"-- ----------------------------\n" +
+		"CREATE OR REPLACE VIEW `v` AS select 1 AS `a`;\n\n\n\n"
+	if sb.String() != want {
+		t.Errorf("TableSchema() = %q, want %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,83 @@
+package mysqldump
+
+import (
+	"fmt"
+	"log"
+)
+
+// WarningKind 警告类型
+type WarningKind string
+
+const (
+	// WarningNoPrimaryKey 表没有主键, 恢复时无法去重; 使用 WithChunkSize 时表没有单列主键, 无法分块导出
+	WarningNoPrimaryKey WarningKind = "no_primary_key"
+	// WarningGeneratedColumn 生成列的值由表达式计算, 不导出, 恢复时重新计算
+	WarningGeneratedColumn WarningKind = "generated_column"
+	// WarningBrokenView 无效的视图, WithCommentBrokenViews 时定义作为注释输出
+	WarningBrokenView WarningKind = "broken_view"
+	// WarningSkippedTable WithSkipFailedTables 时导出失败并跳过的表
+	WarningSkippedTable WarningKind = "skipped_table"
+	// WarningUnsupportedType 不支持的列类型, 值按原始内容输出为字符串或十六进制
+	WarningUnsupportedType WarningKind = "unsupported_type"
+	// WarningInconsistentSnapshot 没有全局读锁时, 并发导出的 worker 连接可能不在同一个快照时间点
+	WarningInconsistentSnapshot WarningKind = "inconsistent_snapshot"
+)
+
+// Warning 导出过程中不影响继续导出的问题
+type Warning struct {
+	Kind  WarningKind
+	Table string
+	// 与列相关的警告为列名, 否则为空
+	Column  string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Column != "" {
+		return fmt.Sprintf("%s: %s.%s: %s", w.Kind, w.Table, w.Column, w.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", w.Kind, w.Table, w.Message)
+}
+
+// WithWarningHandler 每个警告调用一次 handler, 调用是串行的
+// 警告同时记录到 DumpResult.Warnings 和日志
+func WithWarningHandler(handler func(Warning)) DumpOption {
+	return func(option *dumpOption) {
+		option.warningHandler = handler
+	}
+}
+
+// warn 记录警告, handler 在释放 o.mu 后调用, 耗时的 handler 不会阻塞其他 worker 记录结果
+func (o *dumpOption) warn(w Warning) {
+	log.Printf("[warn] %s\n", w)
+	o.mu.Lock()
+	if o.result != nil {
+		o.result.Warnings = append(o.result.Warnings, w)
+	}
+	handler := o.warningHandler
+	o.mu.Unlock()
+	if handler != nil {
+		o.warningMu.Lock()
+		defer o.warningMu.Unlock()
+		handler(w)
+	}
+}
+
+// warnPrimaryKey 表没有主键时警告, unchunked 为使用 WithChunkSize 但无法分块导出
+func (o *dumpOption) warnPrimaryKey(table string, pks []string, unchunked bool) {
+	switch {
+	case unchunked:
+		o.warn(Warning{Kind: WarningNoPrimaryKey, Table: table, Message: "no single-column primary key, dumped in one query"})
+	case len(pks) == 0:
+		o.warn(Warning{Kind: WarningNoPrimaryKey, Table: table, Message: "no primary key"})
+	}
+}
+
+// warnGeneratedColumns 每个不导出的生成列警告一次
+func (o *dumpOption) warnGeneratedColumns(table string, columns []tableColumn) {
+	for _, column := range columns {
+		if column.generated {
+			o.warn(Warning{Kind: WarningGeneratedColumn, Table: table, Column: column.name, Message: "generated column, value not dumped"})
+		}
+	}
+}
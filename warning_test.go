@@ -0,0 +1,82 @@
+package mysqldump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWarning(t *testing.T) {
+	var got []Warning
+	result := &DumpResult{}
+	o := newDumpOption([]DumpOption{
+		WithResult(result),
+		WithWarningHandler(func(w Warning) { got = append(got, w) }),
+	})
+	o.warn(Warning{Kind: WarningNoPrimaryKey, Table: "t1", Message: "no primary key"})
+	o.warnBrokenView("v1", errors.New("missing table"))
+
+	if len(got) != 2 || len(result.Warnings) != 2 {
+		t.Fatalf("handler got %d, result got %d warnings", len(got), len(result.Warnings))
+	}
+	if got[1].Kind != WarningBrokenView || got[1].Table != "v1" {
+		t.Errorf("unexpected warning %+v", got[1])
+	}
+	if len(result.FailedTables) != 1 {
+		t.Errorf("broken view should still be recorded as failed table")
+	}
+
+	tests := []struct {
+		w    Warning
+		want string
+	}{
+		{Warning{Kind: WarningNoPrimaryKey, Table: "t1", Message: "m"}, "no_primary_key: t1: m"},
+		{Warning{Kind: "generated_column", Table: "t1", Column: "c", Message: "m"}, "generated_column: t1.c: m"},
+	}
+	for _, tt := range tests {
+		if s := tt.w.String(); s != tt.want {
+			t.Errorf("String() = %q, want %q", s, tt.want)
+		}
+	}
+}
+
+func TestWarning_tableWarnings(t *testing.T) {
+	var got []string
+	o := newDumpOption([]DumpOption{WithWarningHandler(func(w Warning) { got = append(got, w.String()) })})
+	o.warnPrimaryKey("t1", nil, false)
+	o.warnPrimaryKey("t2", []string{"a", "b"}, true)
+	o.warnPrimaryKey("t3", []string{"id"}, false)
+	o.warnGeneratedColumns("t4", []tableColumn{{name: "id"}, parseColumnExtra("total", "STORED GENERATED")})
+	want := []string{
+		"no_primary_key: t1: no primary key",
+		"no_primary_key: t2: no single-column primary key, dumped in one query",
+		"generated_column: t4.total: generated column, value not dumped",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings = %q, want %q", got, want)
+	}
+}
+
+func TestWarning_handlerOutsideLock(t *testing.T) {
+	result := &DumpResult{}
+	var o *dumpOption
+	o = newDumpOption([]DumpOption{
+		WithResult(result),
+		// handler 中可以使用 o.mu 保护的状态, 不会死锁
+		WithWarningHandler(func(w Warning) {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+		}),
+	})
+	done := make(chan struct{})
+	go func() {
+		o.warn(Warning{Kind: WarningSkippedTable, Table: "t1", Message: "m"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("warn() deadlocked calling the handler under o.mu")
+	}
+}
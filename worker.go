@@ -0,0 +1,77 @@
+package mysqldump
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+)
+
+// workerSession 返回 worker 连接建立后重新设置的会话语句
+// 连接池中的连接可能被其他表的查询改过会话状态, 每个 worker 独占连接前按导出的设置重新设置
+func (o *dumpOption) workerSession() []string {
+	var stmts []string
+	if o.database != "" {
+		stmts = append(stmts, "USE "+QuoteIdentifier(o.database))
+	}
+	if o.timeZone != nil {
+		stmts = append(stmts, "SET SESSION time_zone = '"+timeZoneName(o.timeZone)+"'")
+	}
+	names := make([]string, 0, len(o.sessionVars))
+	for name := range o.sessionVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// 变量名在 openDB 中已检查
+		stmts = append(stmts, "SET SESSION "+name+" = "+sessionValue(o.sessionVars[name]))
+	}
+	if o.singleTransaction {
+		stmts = append(stmts, "SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ")
+	}
+	return stmts
+}
+
+// openWorkerConn 返回 worker 独占的连接, 关闭时放回
+// 一致性快照时取一个已开启共享快照的连接, 否则从连接池取一个连接并重新设置会话
+func openWorkerConn(ctx context.Context, db *sql.DB, o *dumpOption) (*dumpConn, error) {
+	if o.snapshotConns != nil {
+		return o.snapshotConns.get(ctx)
+	}
+	return openSessionConn(ctx, db, o)
+}
+
+// openSessionConn 从连接池取一个连接并重新设置会话, 关闭时放回连接池
+func openSessionConn(ctx context.Context, db *sql.DB, o *dumpOption) (*dumpConn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range o.workerSession() {
+		_, err = conn.ExecContext(ctx, stmt)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return &dumpConn{ctx: ctx, conn: conn}, nil
+}
+
+// openWorkerConns 打开 n 个 worker 连接, 失败时关闭已打开的连接
+func openWorkerConns(ctx context.Context, db *sql.DB, n int, o *dumpOption) ([]*dumpConn, error) {
+	conns := make([]*dumpConn, 0, n)
+	for i := 0; i < n; i++ {
+		conn, err := openWorkerConn(ctx, db, o)
+		if err != nil {
+			closeWorkerConns(conns)
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+func closeWorkerConns(conns []*dumpConn) {
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,41 @@
+package mysqldump
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_dumpOption_workerSession(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []DumpOption
+		want []string
+	}{
+		{name: "default", want: []string{"USE `db`"}},
+		{
+			name: "session",
+			opts: []DumpOption{
+				WithTimeZone(time.UTC),
+				WithSessionVars(map[string]string{"wait_timeout": "3600", "sql_mode": "ANSI"}),
+				WithSingleTransaction(),
+			},
+			want: []string{
+				"USE `db`",
+				"SET SESSION time_zone = '+00:00'",
+				"SET SESSION sql_mode = 'ANSI'",
+				"SET SESSION wait_timeout = 3600",
+				"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newDumpOption(tt.opts)
+			o.database = "db"
+			if got := o.workerSession(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workerSession() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}